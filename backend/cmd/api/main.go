@@ -5,8 +5,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/DanDo385/blackjack/backend/internal/contracts"
+	"github.com/DanDo385/blackjack/backend/internal/game"
 	"github.com/DanDo385/blackjack/backend/internal/handlers"
 	"github.com/go-chi/chi/v5"
 	"github.com/joho/godotenv"
@@ -44,6 +46,7 @@ func main() {
 	// Engine / Game
 	r.Get("/api/engine/state", handlers.GetEngineState)
 	r.Post("/api/engine/bet", handlers.PostBet)
+	r.Post("/api/engine/counting", handlers.PostCountingSystem)
 	r.Post("/api/game/resolve", handlers.PostResolve)
 
 	// Test route
@@ -65,10 +68,36 @@ func main() {
 	r.Post("/api/game/split", handlers.PostSplit)
 	r.Post("/api/game/double", handlers.PostDouble)
 	r.Post("/api/game/insurance", handlers.PostInsurance)
+	r.Post("/api/game/surrender", handlers.PostSurrender)
+	r.Post("/api/game/switch", handlers.PostSwitch)
 	r.Post("/api/game/cashout", handlers.PostCashOut)
+	r.Get("/api/game/advice", handlers.GetAdvice)
+	r.Get("/api/game/hint", handlers.GetHint)
+	r.Post("/api/game/verify", handlers.PostVerify)
+	r.Get("/api/game/events", handlers.GetHandEvents)
+	r.Get("/api/game/events/stream", handlers.GetHandEventStream)
 
 	log.Println("Registered game routes: /api/game/*")
 
+	// Table routes (multi-seat rounds)
+	r.Post("/api/table/join", handlers.PostJoinSeat)
+	r.Post("/api/table/leave", handlers.PostLeaveSeat)
+	r.Post("/api/table/betting", handlers.PostOpenBetting)
+	r.Post("/api/table/bet", handlers.PostSeatBet)
+	r.Post("/api/table/deal", handlers.PostTableDeal)
+
+	r.Get("/api/tables", handlers.GetTables)
+	r.Post("/api/tables", handlers.PostCreateTable)
+	r.Post("/api/tables/close", handlers.PostCloseTable)
+	r.Get("/api/tables/variants", handlers.GetVariants)
+
+	log.Println("Registered table routes: /api/table/*, /api/tables")
+
+	// Provably fair seeds
+	r.Get("/api/fair/seeds", handlers.GetFairSeeds)
+	r.Post("/api/fair/rotate", handlers.PostFairRotate)
+	r.Post("/api/fair/verify", handlers.PostFairVerify)
+
 	// Treasury
 	r.Get("/api/treasury/overview", handlers.GetTreasuryOverview)
 
@@ -92,6 +121,14 @@ func main() {
 		log.Println("No TABLE_ADDRESS found - event watcher disabled (set TABLE_ADDRESS env var or deploy contracts)")
 	}
 
+	// Evict tables nobody has used for a while
+	stopEviction := game.GetTableManager().StartEviction(time.Minute)
+	defer stopEviction()
+
+	// Stand (or decline insurance) for players who leave a decision hanging
+	stopTimers := game.GetTableManager().StartDecisionTimers(time.Second)
+	defer stopTimers()
+
 	log.Println("dev api on :8080")
 	log.Printf("Router has routes registered")
 
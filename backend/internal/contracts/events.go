@@ -225,17 +225,23 @@ func (ew *EventWatcher) handleHandSettled(ctx context.Context, logEntry types.Lo
 	// Event logged only (no database storage)
 }
 
-// resolveHand resolves a hand using the VRF seed
+// resolveHand resolves a hand using the VRF seed under the rules the table was deployed with
 func (ew *EventWatcher) resolveHand(ctx context.Context, handID int64, seed []byte) {
 	log.Printf("Resolving hand %d with seed %s", handID, hex.EncodeToString(seed))
 
+	rules, err := readRules(ctx, ew.client, ew.tableAddr)
+	if err != nil {
+		log.Printf("Failed to read table rules for hand %d: %v", handID, err)
+		return
+	}
+
 	// Mock hand details for demo
 	playerAddr := "0x0000000000000000000000000000000000000000"
 	tokenAddr := "0x0000000000000000000000000000000000000000"
 	amountStr := "1000000000000000000" // 1 token
 
 	// Resolve hand using game engine
-	result, err := game.ResolveHand(handID, playerAddr, tokenAddr, amountStr, seed)
+	result, err := game.ResolveHandWithRules(rules, handID, playerAddr, tokenAddr, amountStr, seed)
 	if err != nil {
 		log.Printf("Failed to resolve hand: %v", err)
 		return
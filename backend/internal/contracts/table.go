@@ -6,6 +6,8 @@ import (
 	"math/big"
 	"os"
 
+	"github.com/DanDo385/blackjack/backend/internal/game"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -81,6 +83,13 @@ func (tc *TableContract) Settle(ctx context.Context, handId *big.Int, cardsDealt
 	return fmt.Errorf("Settle requires contract ABI bindings - generate with abigen")
 }
 
+// CommitShoe calls commitShoe on the Table contract
+// root is the Merkle root of the shuffled shoe, committed before any of its cards is dealt
+func (tc *TableContract) CommitShoe(ctx context.Context, shoeId *big.Int, root [32]byte) error {
+	// Note: This requires contract ABI bindings
+	return fmt.Errorf("CommitShoe requires contract ABI bindings - generate with abigen")
+}
+
 // GetSpreadNum reads the spreadNum parameter from the contract
 func (tc *TableContract) GetSpreadNum(ctx context.Context) (*big.Int, error) {
 	// Note: This requires contract ABI bindings
@@ -105,6 +114,47 @@ func (tc *TableContract) GetTableMax(ctx context.Context) (*big.Int, error) {
 	return nil, fmt.Errorf("GetTableMax requires contract ABI bindings - generate with abigen")
 }
 
+// GetRules reads the rules struct from the contract
+// The engine should be configured with these so off-chain play matches the table
+func (tc *TableContract) GetRules(ctx context.Context) (game.Rules, error) {
+	return readRules(ctx, tc.client, tc.table)
+}
+
+// rulesSelector is the selector of the Table's public rules() getter
+var rulesSelector = crypto.Keccak256([]byte("rules()"))[:4]
+
+// readRules calls the Table's rules() getter and maps ITable.Rules onto the engine's rules
+// The getter returns the struct's eight static fields as 32-byte words, in declaration order:
+// decks, penetrationBps, hitSoft17, bjPayoutBps, allowDAS, allowSurrender, splitAcesOnce, midShoeEntry
+// Options the contract does not hold keep their defaults
+func readRules(ctx context.Context, client *ethclient.Client, table common.Address) (game.Rules, error) {
+	data, err := client.CallContract(ctx, ethereum.CallMsg{To: &table, Data: rulesSelector}, nil)
+	if err != nil {
+		return game.Rules{}, fmt.Errorf("failed to call rules(): %w", err)
+	}
+	if len(data) < 8*32 {
+		return game.Rules{}, fmt.Errorf("rules() returned %d bytes, want %d", len(data), 8*32)
+	}
+
+	word := func(i int) *big.Int { return new(big.Int).SetBytes(data[i*32 : (i+1)*32]) }
+	flag := func(i int) bool { return word(i).Sign() != 0 }
+
+	rules := game.DefaultRules()
+	rules.Decks = int(word(0).Int64())
+	rules.PenetrationBps = int(word(1).Int64())
+	rules.HitSoft17 = flag(2)
+	rules.BJPayoutBps = int(word(3).Int64())
+	rules.AllowDAS = flag(4)
+	rules.AllowSurrender = flag(5)
+	rules.SplitAcesOnce = flag(6)
+	rules.MidShoeEntry = flag(7)
+
+	if err := rules.Validate(); err != nil {
+		return game.Rules{}, fmt.Errorf("table %s has unplayable rules: %w", table.Hex(), err)
+	}
+	return rules, nil
+}
+
 // FulfillRandomness calls the fulfillRandomness function on the Table contract
 // Note: VRF automatically calls fulfillRandomWords, but this is kept for backward compatibility
 func (tc *TableContract) FulfillRandomness(ctx context.Context, handId *big.Int, seed [32]byte) error {
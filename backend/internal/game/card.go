@@ -0,0 +1,223 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Rank is a card rank, Ace (1) through King (13)
+type Rank uint8
+
+const (
+	Ace Rank = iota + 1
+	Two
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+)
+
+// Suit is a card suit
+type Suit uint8
+
+const (
+	Clubs Suit = iota + 1
+	Diamonds
+	Hearts
+	Spades
+)
+
+// Ranks and Suits list every rank and suit in deck order
+var (
+	Ranks = []Rank{Ace, Two, Three, Four, Five, Six, Seven, Eight, Nine, Ten, Jack, Queen, King}
+	Suits = []Suit{Clubs, Diamonds, Hearts, Spades}
+)
+
+var rankNames = [...]string{"?", "A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
+var suitNames = [...]string{"?", "C", "D", "H", "S"}
+
+// Card represents a playing card
+// Serialized as {"Suit":"H","Value":"10"}, the same shape as the original string card
+type Card struct {
+	Suit Suit
+	Rank Rank
+}
+
+// Points returns the blackjack value of the rank, counting an Ace as 1
+func (r Rank) Points() int {
+	if r >= Ten {
+		return 10
+	}
+	return int(r)
+}
+
+// IsTen reports whether the rank is worth ten (10, J, Q, K)
+func (r Rank) IsTen() bool {
+	return r >= Ten && r <= King
+}
+
+// String returns the rank as used in image paths: A, 2-10, J, Q, K
+func (r Rank) String() string {
+	if int(r) >= len(rankNames) {
+		return "?"
+	}
+	return rankNames[r]
+}
+
+// ParseRank parses A, 2-10, J, Q or K
+func ParseRank(s string) (Rank, error) {
+	for i := 1; i < len(rankNames); i++ {
+		if rankNames[i] == s {
+			return Rank(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid card rank %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (r Rank) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (r *Rank) UnmarshalText(text []byte) error {
+	rank, err := ParseRank(string(text))
+	if err != nil {
+		return err
+	}
+	*r = rank
+	return nil
+}
+
+// String returns the suit letter: C, D, H or S
+func (s Suit) String() string {
+	if int(s) >= len(suitNames) {
+		return "?"
+	}
+	return suitNames[s]
+}
+
+// red reports whether the suit is diamonds or hearts
+func (s Suit) red() bool {
+	return s == Diamonds || s == Hearts
+}
+
+// ParseSuit parses C, D, H or S
+func ParseSuit(s string) (Suit, error) {
+	for i := 1; i < len(suitNames); i++ {
+		if suitNames[i] == s {
+			return Suit(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid card suit %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Suit) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Suit) UnmarshalText(text []byte) error {
+	suit, err := ParseSuit(string(text))
+	if err != nil {
+		return err
+	}
+	*s = suit
+	return nil
+}
+
+// String returns the card as rank-suit, e.g. 10-H
+func (c Card) String() string {
+	return c.Rank.String() + "-" + c.Suit.String()
+}
+
+// ParseCard parses the rank-suit form returned by String
+func ParseCard(s string) (Card, error) {
+	for i := len(s) - 1; i > 0; i-- {
+		if s[i] != '-' {
+			continue
+		}
+		rank, err := ParseRank(s[:i])
+		if err != nil {
+			return Card{}, err
+		}
+		suit, err := ParseSuit(s[i+1:])
+		if err != nil {
+			return Card{}, err
+		}
+		return Card{Suit: suit, Rank: rank}, nil
+	}
+	return Card{}, fmt.Errorf("invalid card %q", s)
+}
+
+// Pack encodes the card into one byte: suit in the high nibble, rank in the low nibble
+func (c Card) Pack() byte {
+	return byte(c.Suit)<<4 | byte(c.Rank)
+}
+
+// UnpackCard decodes a byte produced by Card.Pack
+func UnpackCard(b byte) Card {
+	return Card{Suit: Suit(b >> 4), Rank: Rank(b & 0x0F)}
+}
+
+// cardJSON is the wire format of a card
+type cardJSON struct {
+	Suit  Suit `json:"Suit"`
+	Value Rank `json:"Value"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (c Card) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cardJSON{Suit: c.Suit, Value: c.Rank})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *Card) UnmarshalJSON(data []byte) error {
+	var wire cardJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*c = Card{Suit: wire.Suit, Rank: wire.Value}
+	return nil
+}
+
+// RankCounts is a shoe composition by blackjack value
+// Index 1 holds the aces, 2-9 the pips and 10 every ten-value card; index 0 is unused
+type RankCounts [11]int
+
+// NewRankCounts returns the composition of a full shoe
+func NewRankCounts(decks int) RankCounts {
+	var counts RankCounts
+	for points := 1; points <= 9; points++ {
+		counts[points] = 4 * decks
+	}
+	counts[10] = 16 * decks
+	return counts
+}
+
+// Add puts a card back into the composition
+func (c *RankCounts) Add(card Card) {
+	c[card.Rank.Points()]++
+}
+
+// Remove takes a card out of the composition
+func (c *RankCounts) Remove(card Card) {
+	c[card.Rank.Points()]--
+}
+
+// Total returns the number of cards in the composition
+func (c *RankCounts) Total() int {
+	total := 0
+	for _, n := range c[1:] {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,152 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestCardCodecs(t *testing.T) {
+	c := Card{Suit: Hearts, Rank: Ten}
+
+	if got := CardToImagePath(c); got != "/cards/10-H.png" {
+		t.Fatalf("CardToImagePath = %s, want /cards/10-H.png", got)
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `{"Suit":"H","Value":"10"}` {
+		t.Fatalf("JSON = %s, want the string card shape", data)
+	}
+
+	var decoded Card
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded != c {
+		t.Fatalf("decoded %+v, want %+v", decoded, c)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Suit":"X","Value":"10"}`), &decoded); err == nil {
+		t.Fatal("expected error for invalid suit")
+	}
+
+	for _, s := range []string{"", "10", "1-H", "A-", "Q-Z"} {
+		if _, err := ParseCard(s); err == nil {
+			t.Fatalf("ParseCard(%q): expected error", s)
+		}
+	}
+}
+
+func TestCardPack(t *testing.T) {
+	seen := make(map[byte]bool)
+	for _, c := range NewDeck(1).Cards {
+		b := c.Pack()
+		if seen[b] {
+			t.Fatalf("%s packs to duplicate byte %#x", c, b)
+		}
+		seen[b] = true
+
+		if got := UnpackCard(b); got != c {
+			t.Fatalf("UnpackCard(%#x) = %s, want %s", b, got, c)
+		}
+		if parsed, err := ParseCard(c.String()); err != nil || parsed != c {
+			t.Fatalf("ParseCard(%s) = %s, %v", c, parsed, err)
+		}
+	}
+}
+
+func TestRankCounts(t *testing.T) {
+	deck := NewDeck(2)
+	if got, want := deck.RankCounts(), NewRankCounts(2); got != want {
+		t.Fatalf("full shoe counts = %v, want %v", got, want)
+	}
+
+	deck.Cards = []Card{{Suit: Spades, Rank: King}, {Suit: Hearts, Rank: Ace}, {Suit: Clubs, Rank: Ten}}
+	drawn(t, deck)
+
+	counts := deck.RankCounts()
+	if counts[10] != 1 || counts[1] != 1 || counts.Total() != 2 {
+		t.Fatalf("counts = %v, want one ace and one ten left", counts)
+	}
+}
+
+func TestCalculateHandValueAllocations(t *testing.T) {
+	hand := []Card{{Suit: Hearts, Rank: Ace}, {Suit: Spades, Rank: Seven}, {Suit: Clubs, Rank: King}}
+	allocs := testing.AllocsPerRun(100, func() {
+		CalculateHandValue(hand)
+	})
+	if allocs != 0 {
+		t.Fatalf("CalculateHandValue allocates %v times per call, want 0", allocs)
+	}
+}
+
+// legacyCard and legacyHandValue reproduce the string-based card for benchmark comparison
+type legacyCard struct {
+	Suit  string
+	Value string
+}
+
+func legacyHandValue(cards []legacyCard) (int, bool) {
+	total := 0
+	aces := 0
+	for _, card := range cards {
+		switch card.Value {
+		case "A":
+			aces++
+			total += 11
+		case "J", "Q", "K":
+			total += 10
+		default:
+			val := 0
+			fmt.Sscanf(card.Value, "%d", &val)
+			total += val
+		}
+	}
+	isSoft := aces > 0 && total <= 21
+	for total > 21 && aces > 0 {
+		total -= 10
+		aces--
+	}
+	return total, isSoft
+}
+
+func BenchmarkHandValue(b *testing.B) {
+	b.Run("typed", func(b *testing.B) {
+		hand := []Card{{Suit: Hearts, Rank: Five}, {Suit: Spades, Rank: Seven}, {Suit: Clubs, Rank: Four}}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CalculateHandValue(hand)
+		}
+	})
+
+	b.Run("strings", func(b *testing.B) {
+		hand := []legacyCard{{Suit: "H", Value: "5"}, {Suit: "S", Value: "7"}, {Suit: "C", Value: "4"}}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			legacyHandValue(hand)
+		}
+	})
+}
+
+// BenchmarkPlayHand measures a full stateless hand the way cmd/gametest plays it
+func BenchmarkPlayHand(b *testing.B) {
+	rules := DefaultRules()
+	deck := NewDeck(rules.Decks)
+	deck.Shuffle([]byte("benchmark seed"))
+	reshuffleAt := rules.ReshuffleAt()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if deck.Dealt() >= reshuffleAt {
+			deck.Shuffle([]byte{byte(i), byte(i >> 8), byte(i >> 16)})
+		}
+		dealer := []Card{drawn(b, deck), drawn(b, deck)}
+		player := []Card{drawn(b, deck), drawn(b, deck)}
+		dealer = dealerPlayed(b, rules, deck, dealer)
+		CalculateHandValue(player)
+		CalculateHandValue(dealer)
+	}
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"fmt"
+	"math"
+)
+
+// CountingSystem identifies a card counting system tracked by the engine
+type CountingSystem string
+
+const (
+	CountHiLo       CountingSystem = "hi-lo"
+	CountKO         CountingSystem = "ko"
+	CountHiOptI     CountingSystem = "hi-opt-1"
+	CountHiOptII    CountingSystem = "hi-opt-2"
+	CountOmegaII    CountingSystem = "omega-2"
+	CountZen        CountingSystem = "zen"
+	CountWongHalves CountingSystem = "wong-halves"
+)
+
+// CountingSystems lists every system the engine tracks, in display order
+var CountingSystems = []CountingSystem{
+	CountHiLo, CountKO, CountHiOptI, CountHiOptII, CountOmegaII, CountZen, CountWongHalves,
+}
+
+// countTags holds a system's tag per rank, indexed A, 2, 3, ..., 9, 10 (all ten-value cards)
+// Wong Halves uses half-point tags
+var countTags = map[CountingSystem][10]float64{
+	CountHiLo:       {-1, 1, 1, 1, 1, 1, 0, 0, 0, -1},
+	CountKO:         {-1, 1, 1, 1, 1, 1, 1, 0, 0, -1},
+	CountHiOptI:     {0, 0, 1, 1, 1, 1, 0, 0, 0, -1},
+	CountHiOptII:    {0, 1, 1, 2, 2, 1, 1, 0, 0, -2},
+	CountOmegaII:    {0, 1, 1, 2, 2, 2, 1, 0, -1, -2},
+	CountZen:        {-1, 1, 1, 2, 2, 2, 1, 0, 0, -2},
+	CountWongHalves: {-1, 0.5, 1, 1, 1.5, 1, 0.5, 0, -0.5, -1},
+}
+
+// CountState is one system's count for the current shoe
+type CountState struct {
+	RunningCount float64 `json:"runningCount"`
+	TrueCount    float64 `json:"trueCount"`
+}
+
+// Validate checks that the system is one the engine tracks
+func (s CountingSystem) Validate() error {
+	if _, ok := countTags[s]; !ok {
+		return fmt.Errorf("unknown counting system %q", s)
+	}
+	return nil
+}
+
+// Balanced reports whether the system's tags sum to zero over a full deck
+// Unbalanced systems (KO) are played off the running count without a true count conversion
+func (s CountingSystem) Balanced() bool {
+	return s != CountKO
+}
+
+// Tag returns the system's count value for a card
+func (s CountingSystem) Tag(card Card) float64 {
+	points := card.Rank.Points()
+	if points < 1 {
+		return 0
+	}
+	return countTags[s][points-1]
+}
+
+// InitialRunningCount returns the count a fresh shoe starts at
+// KO starts at 4 - 4×decks so its key count lands near zero, balanced systems start at 0
+func (s CountingSystem) InitialRunningCount(decks int) float64 {
+	if s.Balanced() {
+		return 0
+	}
+	return float64(4 - 4*decks)
+}
+
+// TrueCount converts a running count using the decks left in the shoe
+// Unbalanced systems return the running count unchanged
+func (s CountingSystem) TrueCount(runningCount, decksRemaining float64) float64 {
+	if !s.Balanced() {
+		return runningCount
+	}
+	if decksRemaining <= 0 {
+		return 0
+	}
+	return math.Round(runningCount/decksRemaining*100) / 100
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestCountingSystemTags(t *testing.T) {
+	for _, system := range CountingSystems {
+		total := 0.0
+		for _, c := range NewDeck(1).Cards {
+			total += system.Tag(c)
+		}
+
+		want := 0.0
+		if !system.Balanced() {
+			want = 4 // KO counts 7s, leaving a full deck at +4
+		}
+		if total != want {
+			t.Errorf("%s: full deck sums to %v, want %v", system, total, want)
+		}
+	}
+
+	tests := []struct {
+		system CountingSystem
+		value  string
+		want   float64
+	}{
+		{CountHiLo, "A", -1},
+		{CountHiLo, "7", 0},
+		{CountKO, "7", 1},
+		{CountHiOptI, "2", 0},
+		{CountHiOptII, "4", 2},
+		{CountOmegaII, "9", -1},
+		{CountZen, "A", -1},
+		{CountWongHalves, "5", 1.5},
+		{CountWongHalves, "K", -1},
+	}
+	for _, tt := range tests {
+		if got := tt.system.Tag(card(tt.value, "S")); got != tt.want {
+			t.Errorf("%s tag for %s = %v, want %v", tt.system, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEngineCountsVisibleCards(t *testing.T) {
+	e := newTestEngine(t, DefaultRules(),
+		card("10", "C"), card("6", "D"), // dealer 16, hole card 6
+		card("5", "H"), card("5", "S"), // player 10
+		card("2", "C"), // dealer hits to 18
+	)
+
+	// Upcard and both player cards are visible, the hole card is not
+	state := e.GetState()
+	if state.Counts[CountHiLo].RunningCount != 1 {
+		t.Fatalf("hi-lo after deal = %v, want 1 (10, 5, 5)", state.Counts[CountHiLo].RunningCount)
+	}
+	if state.Counts[CountWongHalves].RunningCount != 2 {
+		t.Fatalf("wong halves after deal = %v, want 2", state.Counts[CountWongHalves].RunningCount)
+	}
+
+	playOut(t, e)
+
+	state = e.GetState()
+	if got := state.Counts[CountHiLo].RunningCount; got != 3 {
+		t.Fatalf("hi-lo after round = %v, want 3 (hole 6 and hit 2 counted)", got)
+	}
+	if state.RunningCount != 3 || state.CountingSystem != CountHiLo {
+		t.Fatalf("headline count = %v (%s), want hi-lo 3", state.RunningCount, state.CountingSystem)
+	}
+
+	wantDecks := float64(state.CardsRemaining) / 52
+	if state.DecksRemaining != wantDecks {
+		t.Fatalf("decksRemaining = %v, want %v", state.DecksRemaining, wantDecks)
+	}
+
+	if err := e.SetCountingSystem(CountZen); err != nil {
+		t.Fatalf("SetCountingSystem: %v", err)
+	}
+	if state = e.GetState(); state.RunningCount != state.Counts[CountZen].RunningCount {
+		t.Fatalf("headline count = %v, want zen count %v", state.RunningCount, state.Counts[CountZen].RunningCount)
+	}
+	if err := e.SetCountingSystem("red-seven"); err == nil {
+		t.Fatal("expected error for unknown counting system")
+	}
+}
+
+func TestCountsResetWithShoe(t *testing.T) {
+	rules := DefaultRules()
+	rules.Decks = 2
+
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	e.mu.Lock()
+	e.newShoe([]byte("seed"))
+	e.mu.Unlock()
+
+	state := e.GetState()
+	if got := state.Counts[CountKO].RunningCount; got != -4 {
+		t.Fatalf("KO initial running count = %v, want -4 for 2 decks", got)
+	}
+	if got := state.Counts[CountHiLo]; got.RunningCount != 0 || got.TrueCount != 0 {
+		t.Fatalf("hi-lo at shoe start = %+v, want zero", got)
+	}
+	if state.DecksRemaining != 2 {
+		t.Fatalf("decksRemaining = %v, want 2", state.DecksRemaining)
+	}
+}
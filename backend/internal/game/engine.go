@@ -3,45 +3,55 @@ package game
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/shopspring/decimal"
 )
 
-// Card represents a playing card
-type Card struct {
-	Suit  string // C, D, H, S
-	Value string // A, 2-10, J, Q, K
-}
-
 // HandResult represents the outcome of a resolved hand
 type HandResult struct {
-	HandID       int64
-	PlayerAddr   string
-	DealerCards  []string // Card image paths
-	PlayerCards  [][]string // Multiple hands for splits
-	Outcome      string   // win, lose, push
-	Payout       decimal.Decimal
-	FeeLink      decimal.Decimal
-	FeeNickelRef decimal.Decimal
+	HandID         int64
+	PlayerAddr     string
+	DealerCards    []string       // Card image paths
+	PlayerCards    [][]string     // Multiple hands for splits
+	ShuffleVersion ShuffleVersion // Shuffle algorithm the hand was dealt with
+	Outcome        string         // win, lose, push
+	Reason         OutcomeReason  // Rule that settled the hand
+	Payout         decimal.Decimal // Net for the player, as in Settlement
+	SideBets       []SideBetResult // Settled separately from the main hand
+	FeeLink        decimal.Decimal
+	FeeNickelRef   decimal.Decimal
 }
 
+// ErrDeckExhausted is returned when dealing from a deck with no cards left
+var ErrDeckExhausted = errors.New("deck exhausted")
+
 // Deck represents a shuffled deck of cards
 type Deck struct {
 	Cards []Card // Exported for testing and analysis
 	index int
+
+	seed    []byte         // Seed of the last shuffle; refill shuffles are derived from it
+	version ShuffleVersion // Algorithm of the last shuffle; refills use the same one
+	cutCard int            // Cards dealt when the cut card comes out (0 = no cut card)
+	refills int            // Discard reshuffles since the last shuffle
+
+	tree [][][32]byte // Merkle tree of the current arrangement, built on first use
 }
 
 // NewDeck creates a new deck with the specified number of decks
 func NewDeck(numDecks int) *Deck {
-	suits := []string{"C", "D", "H", "S"}
-	values := []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
+	return newDeck(numDecks, Ranks)
+}
 
-	cards := make([]Card, 0, numDecks*52)
+// newDeck creates a deck of numDecks decks holding the given ranks in every suit
+func newDeck(numDecks int, ranks []Rank) *Deck {
+	cards := make([]Card, 0, numDecks*len(Suits)*len(ranks))
 	for i := 0; i < numDecks; i++ {
-		for _, suit := range suits {
-			for _, value := range values {
-				cards = append(cards, Card{Suit: suit, Value: value})
+		for _, suit := range Suits {
+			for _, rank := range ranks {
+				cards = append(cards, Card{Suit: suit, Rank: rank})
 			}
 		}
 	}
@@ -50,59 +60,145 @@ func NewDeck(numDecks int) *Deck {
 }
 
 // Shuffle deterministically shuffles the deck using a seed
-// Uses Fisher-Yates shuffle with seed-derived randomness
+// Uses Fisher-Yates shuffle with seed-derived randomness (CurrentShuffleVersion)
 func (d *Deck) Shuffle(seed []byte) {
-	// Create a deterministic RNG from seed
-	rng := newSeedRNG(seed)
+	d.shuffle(seed, CurrentShuffleVersion)
+}
 
-	// Fisher-Yates shuffle
-	for i := len(d.Cards) - 1; i > 0; i-- {
-		j := rng.NextInt(i + 1)
-		d.Cards[i], d.Cards[j] = d.Cards[j], d.Cards[i]
+// ShuffleWithVersion shuffles the deck with a specific shuffle algorithm version
+// Used to replay hands that were dealt under an older algorithm
+func (d *Deck) ShuffleWithVersion(seed []byte, version ShuffleVersion) error {
+	if err := version.Validate(); err != nil {
+		return err
 	}
+	d.shuffle(seed, version)
+	return nil
+}
+
+func (d *Deck) shuffle(seed []byte, version ShuffleVersion) {
+	shuffleCards(d.Cards, seed, version)
 
+	d.seed = append([]byte(nil), seed...)
+	d.version = version
 	d.index = 0
+	d.refills = 0
+	d.tree = nil
 }
 
-// Deal deals the next card from the deck
-func (d *Deck) Deal() Card {
+// Version returns the shuffle algorithm version of the last shuffle
+func (d *Deck) Version() ShuffleVersion {
+	return d.version
+}
+
+// Draw deals the next card from the deck
+// Returns ErrDeckExhausted when no cards are left
+func (d *Deck) Draw() (Card, error) {
 	if d.index >= len(d.Cards) {
-		// Deck exhausted - in production, trigger reshuffle
-		panic("deck exhausted")
+		return Card{}, ErrDeckExhausted
 	}
 	card := d.Cards[d.index]
 	d.index++
-	return card
+	return card, nil
+}
+
+// DrawN deals the next n cards from the deck
+// Returns ErrDeckExhausted when fewer than n cards are left
+func (d *Deck) DrawN(n int) ([]Card, error) {
+	cards := make([]Card, 0, n)
+	for i := 0; i < n; i++ {
+		card, err := d.Draw()
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
 }
 
-// seedRNG provides deterministic randomness from a seed
-type seedRNG struct {
-	state []byte
-	pos   int
+// SetCutCard places the cut card so it comes out after n cards are dealt
+func (d *Deck) SetCutCard(n int) {
+	d.cutCard = n
 }
 
-func newSeedRNG(seed []byte) *seedRNG {
-	// Use SHA256 to extend seed if needed
-	hash := sha256.Sum256(seed)
-	return &seedRNG{state: hash[:], pos: 0}
+// CutCardReached reports whether the cut card has come out
+// The round in progress is finished before the shoe is reshuffled.
+// A shoe refilled from its discards is past the cut card too, so it is never carried into another round.
+func (d *Deck) CutCardReached() bool {
+	return d.refills > 0 || (d.cutCard > 0 && d.index >= d.cutCard)
 }
 
-func (r *seedRNG) NextInt(max int) int {
-	if r.pos+4 > len(r.state) {
-		// Extend state by hashing
-		hash := sha256.Sum256(r.state)
-		r.state = hash[:]
-		r.pos = 0
+// ReshuffleDiscards refills an exhausted deck mid-round
+// Every dealt card except those still in play is reshuffled and becomes dealable again.
+// The shuffle seed is derived from the shoe seed, so the refill is reproducible.
+func (d *Deck) ReshuffleDiscards(inPlay []Card) error {
+	remaining := make(map[Card]int, len(inPlay))
+	for _, card := range inPlay {
+		remaining[card]++
+	}
+
+	// Split dealt cards into those on the table and discards, keeping deal order
+	kept := make([]Card, 0, len(inPlay))
+	discards := make([]Card, 0, d.index)
+	for _, card := range d.Cards[:d.index] {
+		if remaining[card] > 0 {
+			remaining[card]--
+			kept = append(kept, card)
+		} else {
+			discards = append(discards, card)
+		}
 	}
 
-	val := binary.BigEndian.Uint32(r.state[r.pos : r.pos+4])
-	r.pos += 4
-	return int(val) % max
+	if len(discards) == 0 {
+		return ErrDeckExhausted
+	}
+
+	d.refills++
+	shuffleCards(discards, DeriveSeed(d.seed, "refill", d.refills), d.version)
+
+	cards := make([]Card, 0, len(kept)+len(discards)+len(d.Cards)-d.index)
+	cards = append(cards, kept...)
+	cards = append(cards, discards...)
+	cards = append(cards, d.Cards[d.index:]...)
+
+	d.Cards = cards
+	d.index = len(kept)
+	d.tree = nil
+	return nil
+}
+
+// DeriveSeed derives a child seed from a parent seed, a label and a counter: SHA-256(seed || label || n as uint64 big-endian)
+// Shoe refills and card salts are seeded this way, and so are the simulator's shoes
+func DeriveSeed(seed []byte, label string, n int) []byte {
+	data := make([]byte, 0, len(seed)+len(label)+8)
+	data = append(data, seed...)
+	data = append(data, label...)
+	data = binary.BigEndian.AppendUint64(data, uint64(n))
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// Dealt returns the number of cards dealt since the last shuffle
+func (d *Deck) Dealt() int {
+	return d.index
+}
+
+// Remaining returns the number of cards left to deal
+func (d *Deck) Remaining() int {
+	return len(d.Cards) - d.index
+}
+
+// RankCounts returns the composition of the cards left to deal
+func (d *Deck) RankCounts() RankCounts {
+	var counts RankCounts
+	for _, card := range d.Cards[d.index:] {
+		counts.Add(card)
+	}
+	return counts
 }
 
 // CardToImagePath converts a card to its image path
 func CardToImagePath(card Card) string {
-	return fmt.Sprintf("/cards/%s-%s.png", card.Value, card.Suit)
+	return "/cards/" + card.String() + ".png"
 }
 
 // CalculateHandValue calculates the total value of a hand
@@ -112,27 +208,21 @@ func CalculateHandValue(cards []Card) (int, bool) {
 	aces := 0
 
 	for _, card := range cards {
-		switch card.Value {
-		case "A":
+		if card.Rank == Ace {
 			aces++
 			total += 11
-		case "J", "Q", "K":
-			total += 10
-		default:
-			val := 0
-			fmt.Sscanf(card.Value, "%d", &val)
-			total += val
+		} else {
+			total += card.Rank.Points()
 		}
 	}
 
-	// Adjust for aces
-	isSoft := aces > 0 && total <= 21
+	// Adjust for aces; the hand is soft while an ace still counts as 11
 	for total > 21 && aces > 0 {
 		total -= 10
 		aces--
 	}
 
-	return total, isSoft
+	return total, aces > 0
 }
 
 // IsBlackjack checks if a hand is a natural blackjack (Ace + 10-value card)
@@ -144,10 +234,10 @@ func IsBlackjack(cards []Card) bool {
 	hasTen := false
 
 	for _, card := range cards {
-		if card.Value == "A" {
+		if card.Rank == Ace {
 			hasAce = true
 		}
-		if card.Value == "10" || card.Value == "J" || card.Value == "Q" || card.Value == "K" {
+		if isTenValue(card) {
 			hasTen = true
 		}
 	}
@@ -155,6 +245,11 @@ func IsBlackjack(cards []Card) bool {
 	return hasAce && hasTen
 }
 
+// isTenValue reports whether a card counts as 10 (10, J, Q, K)
+func isTenValue(card Card) bool {
+	return card.Rank.IsTen()
+}
+
 // IsBust checks if a hand value exceeds 21
 func IsBust(cards []Card) bool {
 	value, _ := CalculateHandValue(cards)
@@ -163,104 +258,111 @@ func IsBust(cards []Card) bool {
 
 // DealerPlay simulates dealer play according to rules
 // Dealer hits on soft 17, stands on hard 17+
-func DealerPlay(deck *Deck, dealerCards []Card, hitSoft17 bool) []Card {
-	for {
-		value, isSoft := CalculateHandValue(dealerCards)
-		
-		// Dealer must hit on 16 or less
-		if value < 17 {
-			dealerCards = append(dealerCards, deck.Deal())
-			continue
+// Returns ErrDeckExhausted if the deck runs out before the dealer stands
+func DealerPlay(deck *Deck, dealerCards []Card, hitSoft17 bool) ([]Card, error) {
+	for DealerShouldHit(dealerCards, hitSoft17) {
+		card, err := deck.Draw()
+		if err != nil {
+			return nil, err
 		}
+		dealerCards = append(dealerCards, card)
+	}
 
-		// Dealer hits on soft 17 if rule allows
-		if value == 17 && isSoft && hitSoft17 {
-			dealerCards = append(dealerCards, deck.Deal())
-			continue
-		}
+	return dealerCards, nil
+}
+
+// DealerShouldHit reports whether the dealer draws another card
+func DealerShouldHit(dealerCards []Card, hitSoft17 bool) bool {
+	value, isSoft := CalculateHandValue(dealerCards)
 
-		// Dealer stands on 17+ (or hard 17)
-		break
+	// Dealer must hit on 16 or less
+	if value < 17 {
+		return true
 	}
 
-	return dealerCards
+	// Dealer hits on soft 17 if rule allows, stands on 17+ (or hard 17)
+	return value == 17 && isSoft && hitSoft17
 }
 
 // EvaluateOutcome evaluates the outcome of a blackjack hand
-// Returns outcome (win/lose/push) and payout amount
+// Returns outcome (win/lose/push) and the player's net, settled as at a default table paying blackjackPayoutBps
+//
+// Deprecated: use Rules.EvaluateOutcome, which settles by the table's own rules
 func EvaluateOutcome(playerCards []Card, dealerCards []Card, betAmount decimal.Decimal, blackjackPayoutBps int) (string, decimal.Decimal) {
-	playerValue, _ := CalculateHandValue(playerCards)
-	dealerValue, _ := CalculateHandValue(dealerCards)
-
-	playerBJ := IsBlackjack(playerCards)
-	dealerBJ := IsBlackjack(dealerCards)
-
-	// Both blackjack = push
-	if playerBJ && dealerBJ {
-		return "push", decimal.Zero
-	}
+	rules := DefaultRules()
+	rules.BJPayoutBps = blackjackPayoutBps
+	settled := rules.EvaluateOutcome(playerCards, dealerCards, betAmount)
+	return settled.Outcome, settled.Payout
+}
 
-	// Player blackjack = win (pays 3:2 or configured)
-	if playerBJ {
-		payout := betAmount.Mul(decimal.NewFromInt(int64(blackjackPayoutBps)).Div(decimal.NewFromInt(10000)))
-		return "win", payout
-	}
+// ResolveHand resolves a hand using the VRF seed
+// This is the main entry point for resolving a hand
+func ResolveHand(handID int64, playerAddr, tokenAddr, amountStr string, seed []byte) (*HandResult, error) {
+	return ResolveHandWithRules(DefaultRules(), handID, playerAddr, tokenAddr, amountStr, seed)
+}
 
-	// Dealer blackjack = lose
-	if dealerBJ {
-		return "lose", decimal.Zero
+// ResolveHandWithRules resolves a hand using the VRF seed under a table's rules
+// Side bets are settled on the player's first two cards and the dealer's hand
+// It deals the player one hand, so variants that deal more per bet (Blackjack Switch) are refused
+func ResolveHandWithRules(rules Rules, handID int64, playerAddr, tokenAddr, amountStr string, seed []byte, sideBets ...SideBetWager) (*HandResult, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
 	}
-
-	// Player bust = lose
-	if IsBust(playerCards) {
-		return "lose", decimal.Zero
+	if hands := rules.variant().HandsPerBet(); hands != 1 {
+		return nil, fmt.Errorf("cannot resolve a %s hand: the variant deals %d hands per bet", rules.Variant, hands)
 	}
 
-	// Dealer bust = win
-	if IsBust(dealerCards) {
-		return "win", betAmount
+	if err := ValidateSideBets(sideBets); err != nil {
+		return nil, fmt.Errorf("invalid side bets: %w", err)
 	}
 
-	// Compare values
-	if playerValue > dealerValue {
-		return "win", betAmount
-	} else if playerValue < dealerValue {
-		return "lose", decimal.Zero
-	} else {
-		return "push", decimal.Zero
-	}
-}
-
-// ResolveHand resolves a hand using the VRF seed
-// This is the main entry point for resolving a hand
-func ResolveHand(handID int64, playerAddr, tokenAddr, amountStr string, seed []byte) (*HandResult, error) {
 	// Parse bet amount
 	betAmount, err := decimal.NewFromString(amountStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid bet amount: %w", err)
 	}
 
-	// Create and shuffle deck (7 decks standard)
-	deck := NewDeck(7)
+	// Create and shuffle the table's shoe
+	deck := rules.NewDeck()
 	deck.Shuffle(seed)
 
 	// Deal initial hands
-	dealerCards := []Card{deck.Deal(), deck.Deal()}
-	playerCards := []Card{deck.Deal(), deck.Deal()}
+	// Without a hole card the dealer's second card is drawn after the player's
+	dealerDealt := 1
+	if rules.Peeks() {
+		dealerDealt = 2
+	}
+	dealerCards, err := deck.DrawN(dealerDealt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deal dealer cards: %w", err)
+	}
+	playerCards, err := deck.DrawN(2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deal player cards: %w", err)
+	}
 
 	// Check for blackjack
 	if IsBlackjack(playerCards) || IsBlackjack(dealerCards) {
 		// No further play needed
+		if len(dealerCards) == 1 {
+			card, err := deck.Draw()
+			if err != nil {
+				return nil, fmt.Errorf("failed to deal dealer card: %w", err)
+			}
+			dealerCards = append(dealerCards, card)
+		}
 	} else {
 		// Player actions would be handled by frontend/API
 		// For now, dealer plays automatically
-		dealerCards = DealerPlay(deck, dealerCards, true) // hitSoft17 = true
+		if dealerCards, err = rules.DealerPlay(deck, dealerCards); err != nil {
+			return nil, fmt.Errorf("failed to play dealer hand: %w", err)
+		}
 	}
 
 	// Convert cards to image paths
 	dealerCardPaths := make([]string, len(dealerCards))
 	for i, card := range dealerCards {
-		if i == 1 && len(dealerCards) == 2 && !IsBlackjack(dealerCards) {
+		if i == 1 && len(dealerCards) == 2 && !IsBlackjack(dealerCards) && rules.Peeks() {
 			// Second dealer card is face-down initially
 			dealerCardPaths[i] = "/cards/back.png"
 		} else {
@@ -276,21 +378,29 @@ func ResolveHand(handID int64, playerAddr, tokenAddr, amountStr string, seed []b
 	}
 
 	// Evaluate outcome
-	outcome, payout := EvaluateOutcome(playerCards, dealerCards, betAmount, 14000) // 140% = 3:2 blackjack
+	settled := rules.EvaluateOutcome(playerCards, dealerCards, betAmount)
+
+	sideBetResults, err := SettleSideBets(sideBets, playerCards, dealerCards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle side bets: %w", err)
+	}
 
 	// Calculate fees (simplified)
 	feeLink := decimal.Zero // Chainlink VRF fee already paid
 	feeNickelRef := betAmount.Mul(decimal.NewFromInt(5)).Div(decimal.NewFromInt(10000)) // 0.05%
 
 	return &HandResult{
-		HandID:       handID,
-		PlayerAddr:   playerAddr,
-		DealerCards:  dealerCardPaths,
-		PlayerCards:  playerCardPaths,
-		Outcome:      outcome,
-		Payout:       payout,
-		FeeLink:      feeLink,
-		FeeNickelRef: feeNickelRef,
+		HandID:         handID,
+		PlayerAddr:     playerAddr,
+		DealerCards:    dealerCardPaths,
+		PlayerCards:    playerCardPaths,
+		ShuffleVersion: deck.Version(),
+		Outcome:        settled.Outcome,
+		Reason:         settled.Reason,
+		Payout:         settled.Payout,
+		SideBets:       sideBetResults,
+		FeeLink:        feeLink,
+		FeeNickelRef:   feeNickelRef,
 	}, nil
 }
 
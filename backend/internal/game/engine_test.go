@@ -2,6 +2,7 @@ package game
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -46,16 +47,38 @@ func TestDeckShuffleDeterministic(t *testing.T) {
 	}
 }
 
+// drawn deals the next card, failing the test if the deck is exhausted
+func drawn(tb testing.TB, deck *Deck) Card {
+	tb.Helper()
+
+	card, err := deck.Draw()
+	if err != nil {
+		tb.Fatalf("Draw: %v", err)
+	}
+	return card
+}
+
+// dealerPlayed plays out the dealer's hand, failing the test if the deck runs out
+func dealerPlayed(tb testing.TB, rules Rules, deck *Deck, dealerCards []Card) []Card {
+	tb.Helper()
+
+	cards, err := rules.DealerPlay(deck, dealerCards)
+	if err != nil {
+		tb.Fatalf("DealerPlay: %v", err)
+	}
+	return cards
+}
+
 func TestDeckDealOrder(t *testing.T) {
 	expected := []Card{
-		{Suit: "H", Value: "A"},
-		{Suit: "S", Value: "10"},
-		{Suit: "D", Value: "5"},
+		{Suit: Hearts, Rank: Ace},
+		{Suit: Spades, Rank: Ten},
+		{Suit: Diamonds, Rank: Five},
 	}
 	deck := &Deck{Cards: append([]Card(nil), expected...), index: 0}
 
 	for i, want := range expected {
-		got := deck.Deal()
+		got := drawn(t, deck)
 		if got != want {
 			t.Fatalf("deal %d: got %+v, want %+v", i, got, want)
 		}
@@ -64,13 +87,67 @@ func TestDeckDealOrder(t *testing.T) {
 	if deck.index != len(expected) {
 		t.Fatalf("deck index = %d, want %d after dealing all cards", deck.index, len(expected))
 	}
+}
+
+func TestDeckDrawExhausted(t *testing.T) {
+	deck := &Deck{Cards: []Card{{Suit: Hearts, Rank: Ace}}}
+
+	if _, err := deck.Draw(); err != nil {
+		t.Fatalf("first draw: %v", err)
+	}
+	if _, err := deck.Draw(); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("draw past end: err = %v, want ErrDeckExhausted", err)
+	}
+
+	deck = &Deck{Cards: []Card{{Suit: Hearts, Rank: Ace}}}
+	if _, err := deck.DrawN(2); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("DrawN past end: err = %v, want ErrDeckExhausted", err)
+	}
+}
 
-	defer func() {
-		if r := recover(); r == nil {
-			t.Fatal("expected panic when dealing past end of deck")
+func TestDeckReshuffleDiscards(t *testing.T) {
+	newDealtDeck := func() *Deck {
+		deck := NewDeck(1)
+		deck.Shuffle([]byte("refill seed"))
+		for deck.Remaining() > 0 {
+			drawn(t, deck)
 		}
-	}()
-	deck.Deal()
+		return deck
+	}
+
+	first := newDealtDeck()
+	inPlay := []Card{first.Cards[0], first.Cards[10], first.Cards[51]}
+	if err := first.ReshuffleDiscards(inPlay); err != nil {
+		t.Fatalf("ReshuffleDiscards: %v", err)
+	}
+
+	if first.Dealt() != len(inPlay) || first.Remaining() != 52-len(inPlay) {
+		t.Fatalf("dealt=%d remaining=%d, want in-play cards kept out of the refill", first.Dealt(), first.Remaining())
+	}
+	for _, c := range first.Cards[first.Dealt():] {
+		for _, held := range inPlay {
+			if c == held {
+				t.Fatalf("card %+v is in play but was reshuffled into the shoe", c)
+			}
+		}
+	}
+
+	// The refill shuffle is derived from the shoe seed, so replays deal the same cards
+	second := newDealtDeck()
+	if err := second.ReshuffleDiscards(inPlay); err != nil {
+		t.Fatalf("ReshuffleDiscards: %v", err)
+	}
+	for i := range first.Cards {
+		if first.Cards[i] != second.Cards[i] {
+			t.Fatalf("refill not deterministic at position %d: %+v vs %+v", i, first.Cards[i], second.Cards[i])
+		}
+	}
+
+	// Nothing to reshuffle when every dealt card is still on the table
+	deck := &Deck{Cards: []Card{{Suit: Hearts, Rank: Ace}}, index: 1}
+	if err := deck.ReshuffleDiscards(deck.Cards); !errors.Is(err, ErrDeckExhausted) {
+		t.Fatalf("err = %v, want ErrDeckExhausted", err)
+	}
 }
 
 func TestDealerPlay(t *testing.T) {
@@ -84,32 +161,40 @@ func TestDealerPlay(t *testing.T) {
 	}{
 		{
 			name:         "hard 16 hits once",
-			start:        []Card{{Suit: "H", Value: "9"}, {Suit: "S", Value: "7"}},
-			deckCards:    []Card{{Suit: "C", Value: "5"}},
+			start:        []Card{{Suit: Hearts, Rank: Nine}, {Suit: Spades, Rank: Seven}},
+			deckCards:    []Card{{Suit: Clubs, Rank: Five}},
 			hitSoft17:    true,
 			wantTotal:    21,
 			wantNumCards: 3,
 		},
 		{
 			name:         "soft 17 hits when allowed",
-			start:        []Card{{Suit: "H", Value: "A"}, {Suit: "D", Value: "6"}},
-			deckCards:    []Card{{Suit: "C", Value: "2"}},
+			start:        []Card{{Suit: Hearts, Rank: Ace}, {Suit: Diamonds, Rank: Six}},
+			deckCards:    []Card{{Suit: Clubs, Rank: Two}},
 			hitSoft17:    true,
 			wantTotal:    19,
 			wantNumCards: 3,
 		},
+		{
+			name:         "multi-card soft 17 hits when allowed",
+			start:        []Card{{Suit: Hearts, Rank: Ace}, {Suit: Diamonds, Rank: Ace}},
+			deckCards:    []Card{{Suit: Clubs, Rank: Five}, {Suit: Clubs, Rank: Two}},
+			hitSoft17:    true,
+			wantTotal:    19,
+			wantNumCards: 4,
+		},
 		{
 			name:         "soft 17 stands when not allowed to hit",
-			start:        []Card{{Suit: "H", Value: "A"}, {Suit: "D", Value: "6"}},
-			deckCards:    []Card{{Suit: "C", Value: "9"}},
+			start:        []Card{{Suit: Hearts, Rank: Ace}, {Suit: Diamonds, Rank: Six}},
+			deckCards:    []Card{{Suit: Clubs, Rank: Nine}},
 			hitSoft17:    false,
 			wantTotal:    17,
 			wantNumCards: 2,
 		},
 		{
 			name:         "already over threshold stands",
-			start:        []Card{{Suit: "H", Value: "10"}, {Suit: "D", Value: "8"}},
-			deckCards:    []Card{{Suit: "C", Value: "2"}},
+			start:        []Card{{Suit: Hearts, Rank: Ten}, {Suit: Diamonds, Rank: Eight}},
+			deckCards:    []Card{{Suit: Clubs, Rank: Two}},
 			hitSoft17:    true,
 			wantTotal:    18,
 			wantNumCards: 2,
@@ -121,7 +206,10 @@ func TestDealerPlay(t *testing.T) {
 			deck := &Deck{Cards: append([]Card(nil), tt.deckCards...), index: 0}
 			start := append([]Card(nil), tt.start...)
 
-			result := DealerPlay(deck, start, tt.hitSoft17)
+			result, err := DealerPlay(deck, start, tt.hitSoft17)
+			if err != nil {
+				t.Fatalf("DealerPlay: %v", err)
+			}
 
 			if len(result) != tt.wantNumCards {
 				t.Fatalf("got %d cards, want %d", len(result), tt.wantNumCards)
@@ -148,64 +236,64 @@ func TestEvaluateOutcome(t *testing.T) {
 	}{
 		{
 			name:            "player blackjack",
-			player:          []Card{{Suit: "H", Value: "A"}, {Suit: "D", Value: "K"}},
-			dealer:          []Card{{Suit: "C", Value: "9"}, {Suit: "S", Value: "7"}},
+			player:          []Card{{Suit: Hearts, Rank: Ace}, {Suit: Diamonds, Rank: King}},
+			dealer:          []Card{{Suit: Clubs, Rank: Nine}, {Suit: Spades, Rank: Seven}},
 			wantOutcome:     "win",
 			wantPayout:      decimal.NewFromInt(140),
 			blackjackPayout: 14000,
 		},
 		{
 			name:            "both blackjack push",
-			player:          []Card{{Suit: "H", Value: "A"}, {Suit: "D", Value: "K"}},
-			dealer:          []Card{{Suit: "C", Value: "A"}, {Suit: "S", Value: "Q"}},
+			player:          []Card{{Suit: Hearts, Rank: Ace}, {Suit: Diamonds, Rank: King}},
+			dealer:          []Card{{Suit: Clubs, Rank: Ace}, {Suit: Spades, Rank: Queen}},
 			wantOutcome:     "push",
 			wantPayout:      decimal.Zero,
 			blackjackPayout: 14000,
 		},
 		{
 			name:            "dealer blackjack",
-			player:          []Card{{Suit: "H", Value: "9"}, {Suit: "D", Value: "7"}},
-			dealer:          []Card{{Suit: "C", Value: "A"}, {Suit: "S", Value: "K"}},
+			player:          []Card{{Suit: Hearts, Rank: Nine}, {Suit: Diamonds, Rank: Seven}},
+			dealer:          []Card{{Suit: Clubs, Rank: Ace}, {Suit: Spades, Rank: King}},
 			wantOutcome:     "lose",
-			wantPayout:      decimal.Zero,
+			wantPayout:      bet.Neg(),
 			blackjackPayout: 14000,
 		},
 		{
 			name:            "player busts",
-			player:          []Card{{Suit: "H", Value: "10"}, {Suit: "D", Value: "9"}, {Suit: "S", Value: "5"}},
-			dealer:          []Card{{Suit: "C", Value: "9"}, {Suit: "S", Value: "7"}},
+			player:          []Card{{Suit: Hearts, Rank: Ten}, {Suit: Diamonds, Rank: Nine}, {Suit: Spades, Rank: Five}},
+			dealer:          []Card{{Suit: Clubs, Rank: Nine}, {Suit: Spades, Rank: Seven}},
 			wantOutcome:     "lose",
-			wantPayout:      decimal.Zero,
+			wantPayout:      bet.Neg(),
 			blackjackPayout: 14000,
 		},
 		{
 			name:            "dealer busts",
-			player:          []Card{{Suit: "H", Value: "10"}, {Suit: "D", Value: "7"}},
-			dealer:          []Card{{Suit: "C", Value: "9"}, {Suit: "S", Value: "7"}, {Suit: "H", Value: "8"}},
+			player:          []Card{{Suit: Hearts, Rank: Ten}, {Suit: Diamonds, Rank: Seven}},
+			dealer:          []Card{{Suit: Clubs, Rank: Nine}, {Suit: Spades, Rank: Seven}, {Suit: Hearts, Rank: Eight}},
 			wantOutcome:     "win",
 			wantPayout:      bet,
 			blackjackPayout: 14000,
 		},
 		{
 			name:            "player higher total wins",
-			player:          []Card{{Suit: "H", Value: "10"}, {Suit: "D", Value: "8"}},
-			dealer:          []Card{{Suit: "C", Value: "9"}, {Suit: "S", Value: "7"}},
+			player:          []Card{{Suit: Hearts, Rank: Ten}, {Suit: Diamonds, Rank: Eight}},
+			dealer:          []Card{{Suit: Clubs, Rank: Nine}, {Suit: Spades, Rank: Seven}},
 			wantOutcome:     "win",
 			wantPayout:      bet,
 			blackjackPayout: 14000,
 		},
 		{
 			name:            "dealer higher total wins",
-			player:          []Card{{Suit: "H", Value: "9"}, {Suit: "D", Value: "7"}},
-			dealer:          []Card{{Suit: "C", Value: "10"}, {Suit: "S", Value: "8"}},
+			player:          []Card{{Suit: Hearts, Rank: Nine}, {Suit: Diamonds, Rank: Seven}},
+			dealer:          []Card{{Suit: Clubs, Rank: Ten}, {Suit: Spades, Rank: Eight}},
 			wantOutcome:     "lose",
-			wantPayout:      decimal.Zero,
+			wantPayout:      bet.Neg(),
 			blackjackPayout: 14000,
 		},
 		{
 			name:            "equal totals push",
-			player:          []Card{{Suit: "H", Value: "10"}, {Suit: "D", Value: "8"}},
-			dealer:          []Card{{Suit: "C", Value: "Q"}, {Suit: "S", Value: "8"}},
+			player:          []Card{{Suit: Hearts, Rank: Ten}, {Suit: Diamonds, Rank: Eight}},
+			dealer:          []Card{{Suit: Clubs, Rank: Queen}, {Suit: Spades, Rank: Eight}},
 			wantOutcome:     "push",
 			wantPayout:      decimal.Zero,
 			blackjackPayout: 14000,
@@ -374,7 +462,7 @@ func TestDealingSequenceAfterShuffle(t *testing.T) {
 
 	// Deal all cards and verify order matches shuffled deck
 	for i := 0; i < len(deck.Cards); i++ {
-		dealt := deck.Deal()
+		dealt := drawn(t, deck)
 		if dealt != deck.Cards[i] {
 			// This shouldn't happen - cards should be dealt in order
 			t.Fatalf("deal %d: got %+v, expected %+v", i, dealt, deck.Cards[i])
@@ -389,12 +477,11 @@ func TestDealingSequenceAfterShuffle(t *testing.T) {
 // TestStatisticalDistribution verifies rank positions are spread throughout deck
 func TestStatisticalDistribution(t *testing.T) {
 	numShuffles := 500
-	values := []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
 
 	// For each rank, track how many appear in different thirds of the deck
-	rankDistribution := make(map[string][3]int) // [first third, middle third, last third]
-	for _, v := range values {
-		rankDistribution[v] = [3]int{}
+	rankDistribution := make(map[Rank][3]int) // [first third, middle third, last third]
+	for _, r := range Ranks {
+		rankDistribution[r] = [3]int{}
 	}
 
 	for shuffleNum := 0; shuffleNum < numShuffles; shuffleNum++ {
@@ -409,9 +496,9 @@ func TestStatisticalDistribution(t *testing.T) {
 			if third > 2 {
 				third = 2 // Last card goes to third section
 			}
-			dist := rankDistribution[card.Value]
+			dist := rankDistribution[card.Rank]
 			dist[third]++
-			rankDistribution[card.Value] = dist
+			rankDistribution[card.Rank] = dist
 		}
 	}
 
@@ -421,7 +508,7 @@ func TestStatisticalDistribution(t *testing.T) {
 	expectedPerThird := (numShuffles * 4) / 3
 	tolerance := expectedPerThird / 2 // Allow ±50% variance
 
-	for _, value := range values {
+	for _, value := range Ranks {
 		dist := rankDistribution[value]
 		for i, count := range dist {
 			if count < expectedPerThird-tolerance || count > expectedPerThird+tolerance {
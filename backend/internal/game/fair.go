@@ -0,0 +1,112 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/DanDo385/blackjack/backend/internal/random"
+)
+
+// NextCommitment returns the commitment the next shoe will be shuffled under
+// The server seed hash is published before any bet is placed on that shoe
+func (e *GlobalEngine) NextCommitment() (random.Commitment, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	commit, err := e.pendingCommitment()
+	if err != nil {
+		return random.Commitment{}, err
+	}
+	return commit.Public(), nil
+}
+
+// RotateSeeds sets a seated player's share of the next shoe's client seed, and commits to a new server seed if asked
+// The client seed combines the shares of every seated player, so no one player picks it alone
+// The shoe in play keeps the seeds it was shuffled with
+func (e *GlobalEngine) RotateSeeds(seat int, playerAddr, clientSeed string, newServerSeed bool) (random.Commitment, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, err := e.heldSeat(seat, playerAddr)
+	if err != nil {
+		return random.Commitment{}, err
+	}
+	if strings.Contains(clientSeed, clientSeedSep) {
+		return random.Commitment{}, fmt.Errorf("client seed may not contain %q", clientSeedSep)
+	}
+	if clientSeed != "" {
+		s.ClientSeed = clientSeed
+	}
+
+	commit, err := e.pendingCommitment()
+	if err != nil {
+		return random.Commitment{}, err
+	}
+	if newServerSeed {
+		if commit, err = random.NewCommitment(commit.ClientSeed, commit.Nonce); err != nil {
+			return random.Commitment{}, fmt.Errorf("failed to commit to a server seed: %w", err)
+		}
+		e.nextCommit = commit
+	}
+
+	log.Printf("Seeds rotated by seat %d: serverSeedHash=%s, clientSeed=%s, nonce=%d", seat, commit.ServerSeedHash, commit.ClientSeed, commit.Nonce)
+	return commit.Public(), nil
+}
+
+// Reveals returns the revealed server seeds of retired shoes, oldest first
+func (e *GlobalEngine) Reveals() []random.Reveal {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return append([]random.Reveal(nil), e.reveals...)
+}
+
+// pendingCommitment returns the next shoe's commitment, committing to a server seed if there is none
+// Caller must hold e.mu
+func (e *GlobalEngine) pendingCommitment() (*random.Commitment, error) {
+	if e.nextCommit == nil {
+		commit, err := random.NewCommitment("", 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit to a server seed: %w", err)
+		}
+		e.nextCommit = commit
+	}
+	if shares := e.clientSeedShares(); len(shares) > 0 {
+		e.nextCommit.ClientSeed = strings.Join(shares, clientSeedSep)
+	}
+	return e.nextCommit, nil
+}
+
+// clientSeedSep separates the seated players' shares of a client seed
+const clientSeedSep = "|"
+
+// clientSeedShares returns the client seeds set by seated players, in seat order
+// With none set, the last client seed is kept
+// Caller must hold e.mu
+func (e *GlobalEngine) clientSeedShares() []string {
+	var shares []string
+	for _, s := range e.state.Seats {
+		if s.PlayerAddr != "" && s.ClientSeed != "" {
+			shares = append(shares, s.ClientSeed)
+		}
+	}
+	return shares
+}
+
+// useCommitment takes the pending commitment for a new shoe and publishes the next one
+// Every shoe gets its own server seed, so revealing it never exposes a later shoe
+// Caller must hold e.mu
+func (e *GlobalEngine) useCommitment() (*random.Commitment, error) {
+	commit, err := e.pendingCommitment()
+	if err != nil {
+		return nil, err
+	}
+	next, err := random.NewCommitment(commit.ClientSeed, commit.Nonce+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit to a server seed: %w", err)
+	}
+
+	e.nextCommit = next
+	return commit, nil
+}
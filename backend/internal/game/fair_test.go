@@ -0,0 +1,177 @@
+package game
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/DanDo385/blackjack/backend/internal/random"
+)
+
+// playFairHand deals one single-seat hand under the engine's commitment and stands it out
+func playFairHand(t *testing.T, e *GlobalEngine, handID int64) {
+	t.Helper()
+
+	if err := e.OpenBetting(handID); err != nil {
+		t.Fatalf("OpenBetting: %v", err)
+	}
+	if err := e.PlaceBet(0, seatPlayer(0), "0xtoken", "100"); err != nil {
+		t.Fatalf("PlaceBet: %v", err)
+	}
+	if err := e.CloseBetting(); err != nil {
+		t.Fatalf("CloseBetting: %v", err)
+	}
+	if err := e.ShuffleAndDealFair(); err != nil {
+		t.Fatalf("ShuffleAndDealFair: %v", err)
+	}
+	for i := 0; i < 10 && e.GetState().Phase != PhaseComplete; i++ {
+		switch e.GetState().Phase {
+		case PhaseInsuranceOffer:
+			if err := e.SeatInsurance(0, seatPlayer(0), false, ""); err != nil {
+				t.Fatalf("SeatInsurance: %v", err)
+			}
+		case PhasePlayerTurn:
+			if err := e.SeatStand(0, seatPlayer(0)); err != nil {
+				t.Fatalf("SeatStand: %v", err)
+			}
+		}
+		if err := e.FinishRound(); err != nil {
+			t.Fatalf("FinishRound: %v", err)
+		}
+	}
+}
+
+func TestProvablyFairShoes(t *testing.T) {
+	rules := DefaultRules()
+	rules.Decks = 1
+	rules.PenetrationBps = 770 // Cut card after 4 cards: every hand retires its shoe
+
+	e := NewEngine(rules)
+	if err := e.JoinSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+
+	// The player picks the client seed before the shoe is shuffled
+	committed, err := e.RotateSeeds(0, seatPlayer(0), "lucky", false)
+	if err != nil {
+		t.Fatalf("RotateSeeds: %v", err)
+	}
+	if committed.ClientSeed != "lucky" || committed.ServerSeedHash == "" {
+		t.Fatalf("commitment = %+v, want client seed lucky and a server seed hash", committed)
+	}
+
+	playFairHand(t, e, 1)
+	if current := e.GetState().Commitment; current == nil || current.ServerSeedHash != committed.ServerSeedHash || current.ClientSeed != "lucky" {
+		t.Fatalf("shoe dealt under %+v, want the published %+v", current, committed)
+	}
+	next, err := e.NextCommitment()
+	if err != nil {
+		t.Fatalf("NextCommitment: %v", err)
+	}
+	if next.ServerSeedHash == committed.ServerSeedHash || next.Nonce != committed.Nonce+1 || next.ClientSeed != "lucky" {
+		t.Errorf("next commitment = %+v, want a new server seed for nonce %d", next, committed.Nonce+1)
+	}
+
+	// Passing the cut card retires the shoe and reveals its server seed
+	reveals := e.Reveals()
+	if len(reveals) != 1 || reveals[0].ServerSeedHash != committed.ServerSeedHash {
+		t.Fatalf("reveals = %+v, want the first shoe's server seed", reveals)
+	}
+	seed, err := reveals[0].Verify()
+	if err != nil {
+		t.Fatalf("Reveal.Verify: %v", err)
+	}
+
+	log, err := e.HandLog(1)
+	if err != nil {
+		t.Fatalf("HandLog: %v", err)
+	}
+	if log.Seed.Fair == nil || log.Seed.Shoe != hex.EncodeToString(seed) {
+		t.Fatalf("hand 1 seed %+v, want the shoe shuffled from the revealed seeds", log.Seed)
+	}
+	if _, err := log.Verify(); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	// Seeds that don't match the commitment, or a shoe they didn't produce, are caught
+	tampered := log
+	forged := *log.Seed.Fair
+	forged.ClientSeed = "unlucky"
+	tampered.Seed.Fair = &forged
+	if _, err := tampered.Verify(); err == nil {
+		t.Error("expected an error for a different client seed")
+	}
+
+	other, err := random.NewCommitment("lucky", 0)
+	if err != nil {
+		t.Fatalf("NewCommitment: %v", err)
+	}
+	forged = other.Reveal()
+	forged.ServerSeedHash = log.Seed.Fair.ServerSeedHash
+	tampered.Seed.Fair = &forged
+	if _, err := tampered.Verify(); err == nil {
+		t.Error("expected an error for a server seed that doesn't match its hash")
+	}
+}
+
+func TestRotateServerSeed(t *testing.T) {
+	e := NewEngine(DefaultRules())
+	if err := e.JoinSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+
+	before, err := e.NextCommitment()
+	if err != nil {
+		t.Fatalf("NextCommitment: %v", err)
+	}
+	after, err := e.RotateSeeds(0, seatPlayer(0), "", true)
+	if err != nil {
+		t.Fatalf("RotateSeeds: %v", err)
+	}
+	if after.ServerSeedHash == before.ServerSeedHash {
+		t.Error("server seed hash unchanged after rotating")
+	}
+	if after.ClientSeed != before.ClientSeed || after.Nonce != before.Nonce {
+		t.Errorf("rotated commitment = %+v, want client seed and nonce of %+v", after, before)
+	}
+}
+
+func TestClientSeedSharedBySeats(t *testing.T) {
+	e := NewEngine(DefaultRules())
+	for _, n := range []int{0, 2} {
+		if err := e.JoinSeat(n, seatPlayer(n)); err != nil {
+			t.Fatalf("JoinSeat: %v", err)
+		}
+	}
+
+	if _, err := e.RotateSeeds(1, seatPlayer(1), "mine", false); err == nil {
+		t.Error("expected an error rotating seeds from an open seat")
+	}
+	if _, err := e.RotateSeeds(0, seatPlayer(2), "mine", false); err == nil {
+		t.Error("expected an error rotating seeds from another player's seat")
+	}
+	if _, err := e.RotateSeeds(0, seatPlayer(0), "a|b", false); err == nil {
+		t.Error("expected an error for a client seed containing the separator")
+	}
+
+	if _, err := e.RotateSeeds(2, seatPlayer(2), "second", false); err != nil {
+		t.Fatalf("RotateSeeds: %v", err)
+	}
+	next, err := e.RotateSeeds(0, seatPlayer(0), "first", false)
+	if err != nil {
+		t.Fatalf("RotateSeeds: %v", err)
+	}
+	if next.ClientSeed != "first|second" {
+		t.Errorf("client seed = %q, want both seats' seeds in seat order", next.ClientSeed)
+	}
+
+	// A player who leaves takes their share with them
+	if err := e.LeaveSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("LeaveSeat: %v", err)
+	}
+	if next, err = e.NextCommitment(); err != nil {
+		t.Fatalf("NextCommitment: %v", err)
+	}
+	if next.ClientSeed != "second" {
+		t.Errorf("client seed = %q after seat 0 left, want second", next.ClientSeed)
+	}
+}
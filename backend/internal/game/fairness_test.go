@@ -27,20 +27,23 @@ func TestOperatorProfitability(t *testing.T) {
 		deck.Shuffle(seed)
 
 		// Deal initial hands
-		dealerCards := []Card{deck.Deal(), deck.Deal()}
-		playerCards := []Card{deck.Deal(), deck.Deal()}
+		dealerCards := []Card{drawn(t, deck), drawn(t, deck)}
+		playerCards := []Card{drawn(t, deck), drawn(t, deck)}
 
 		// Check for blackjack
 		if !IsBlackjack(playerCards) && !IsBlackjack(dealerCards) {
 			// Player plays basic strategy (simplified)
 			playerValue, isSoft := CalculateHandValue(playerCards)
 			for playerValue < 17 || (playerValue == 17 && isSoft) {
-				playerCards = append(playerCards, deck.Deal())
+				playerCards = append(playerCards, drawn(t, deck))
 				playerValue, isSoft = CalculateHandValue(playerCards)
 			}
 
 			// Dealer plays
-			dealerCards = DealerPlay(deck, dealerCards, true)
+			var err error
+			if dealerCards, err = DealerPlay(deck, dealerCards, true); err != nil {
+				t.Fatalf("DealerPlay: %v", err)
+			}
 		}
 
 		// Evaluate outcome
@@ -89,8 +92,8 @@ func TestOperatorProfitability(t *testing.T) {
 		seed := bytes.Repeat([]byte{byte(i % 256), byte(i / 256)}, 16)
 		deck := NewDeck(1)
 		deck.Shuffle(seed)
-		_ = []Card{deck.Deal(), deck.Deal()} // dealer cards (not used in this test)
-		playerCards := []Card{deck.Deal(), deck.Deal()}
+		_ = []Card{drawn(t, deck), drawn(t, deck)} // dealer cards (not used in this test)
+		playerCards := []Card{drawn(t, deck), drawn(t, deck)}
 
 		if IsBlackjack(playerCards) {
 			blackjackCount++
@@ -112,8 +115,8 @@ func TestCardDistributionFairness(t *testing.T) {
 		seed := bytes.Repeat([]byte{byte(i % 256), byte(i / 256)}, 16)
 		deck := NewDeck(1)
 		deck.Shuffle(seed)
-		firstCard := deck.Deal()
-		key := fmt.Sprintf("%s-%s", firstCard.Value, firstCard.Suit)
+		firstCard := drawn(t, deck)
+		key := fmt.Sprintf("%s-%s", firstCard.Rank, firstCard.Suit)
 		firstCards[key]++
 	}
 
@@ -206,7 +209,7 @@ func TestNoPatternDetection(t *testing.T) {
 			cardIndex := 0
 			for i, suit := range suits {
 				for j, value := range values {
-					if card.Suit == suit && card.Value == value {
+					if card.Suit.String() == suit && card.Rank.String() == value {
 						cardIndex = i*len(values) + j
 					}
 				}
@@ -271,11 +274,11 @@ func TestVRFResistance(t *testing.T) {
 		// Verify the seed produces deterministic outcome
 		deck1 := NewDeck(1)
 		deck1.Shuffle(attackSeed)
-		firstCard1 := deck1.Deal()
+		firstCard1 := drawn(t, deck1)
 
 		deck2 := NewDeck(1)
 		deck2.Shuffle(attackSeed)
-		firstCard2 := deck2.Deal()
+		firstCard2 := drawn(t, deck2)
 
 		if firstCard1 == firstCard2 {
 			successfulPredictions++
@@ -296,8 +299,8 @@ func TestVRFResistance(t *testing.T) {
 // TestBlackjackPayoutCorrectness ensures blackjack pays out at 3:2
 func TestBlackjackPayoutCorrectness(t *testing.T) {
 	bet := decimal.NewFromInt(100)
-	playerCards := []Card{{Suit: "H", Value: "A"}, {Suit: "D", Value: "K"}}
-	dealerCards := []Card{{Suit: "C", Value: "9"}, {Suit: "S", Value: "7"}}
+	playerCards := []Card{{Suit: Hearts, Rank: Ace}, {Suit: Diamonds, Rank: King}}
+	dealerCards := []Card{{Suit: Clubs, Rank: Nine}, {Suit: Spades, Rank: Seven}}
 
 	// Test at 3:2 (15000 bps = 150% = 3:2)
 	outcome, payout := EvaluateOutcome(playerCards, dealerCards, bet, 15000)
@@ -323,18 +326,18 @@ func TestPushCorrectness(t *testing.T) {
 	}{
 		{
 			name:   "both blackjack",
-			player: []Card{{Suit: "H", Value: "A"}, {Suit: "D", Value: "K"}},
-			dealer: []Card{{Suit: "C", Value: "A"}, {Suit: "S", Value: "Q"}},
+			player: []Card{{Suit: Hearts, Rank: Ace}, {Suit: Diamonds, Rank: King}},
+			dealer: []Card{{Suit: Clubs, Rank: Ace}, {Suit: Spades, Rank: Queen}},
 		},
 		{
 			name:   "both 20",
-			player: []Card{{Suit: "H", Value: "10"}, {Suit: "D", Value: "K"}},
-			dealer: []Card{{Suit: "C", Value: "Q"}, {Suit: "S", Value: "10"}},
+			player: []Card{{Suit: Hearts, Rank: Ten}, {Suit: Diamonds, Rank: King}},
+			dealer: []Card{{Suit: Clubs, Rank: Queen}, {Suit: Spades, Rank: Ten}},
 		},
 		{
 			name:   "both 17 after hitting",
-			player: []Card{{Suit: "H", Value: "9"}, {Suit: "D", Value: "8"}},
-			dealer: []Card{{Suit: "C", Value: "9"}, {Suit: "S", Value: "8"}},
+			player: []Card{{Suit: Hearts, Rank: Nine}, {Suit: Diamonds, Rank: Eight}},
+			dealer: []Card{{Suit: Clubs, Rank: Nine}, {Suit: Spades, Rank: Eight}},
 		},
 	}
 
@@ -352,3 +355,65 @@ func TestPushCorrectness(t *testing.T) {
 		})
 	}
 }
+
+// TestBonusHandsHouseEdge plays the same shuffles with and without the bonus hand rules
+// The house pays every bonus, so they must lower the edge, but at these payouts it stays with the house
+func TestBonusHandsHouseEdge(t *testing.T) {
+	numHands := 10000
+	bet := decimal.NewFromInt(100)
+
+	bonus := DefaultRules()
+	bonus.CharlieCards = 5
+	bonus.Suited678Bps = 20000
+	bonus.Triple7Bps = 30000
+	bonus.SuitedBJPayoutBps = 20000
+
+	houseEdge := func(rules Rules) (float64, map[OutcomeReason]int) {
+		reasons := make(map[OutcomeReason]int)
+		operatorGain := decimal.Zero
+
+		for i := 0; i < numHands; i++ {
+			seed := bytes.Repeat([]byte{byte(i % 256), byte(i / 256)}, 16)
+			deck := rules.NewDeck()
+			deck.Shuffle(seed)
+
+			dealerCards := []Card{drawn(t, deck), drawn(t, deck)}
+			playerCards := []Card{drawn(t, deck), drawn(t, deck)}
+
+			// Same simplified strategy as above; a Charlie finishes the hand like it does in the engine
+			if !IsBlackjack(playerCards) && !IsBlackjack(dealerCards) {
+				playerValue, isSoft := CalculateHandValue(playerCards)
+				for (playerValue < 17 || (playerValue == 17 && isSoft)) && !rules.charlie(playerCards) {
+					playerCards = append(playerCards, drawn(t, deck))
+					playerValue, isSoft = CalculateHandValue(playerCards)
+				}
+				dealerCards = dealerPlayed(t, rules, deck, dealerCards)
+			}
+
+			settled := rules.EvaluateOutcome(playerCards, dealerCards, bet)
+			reasons[settled.Reason]++
+			operatorGain = operatorGain.Sub(settled.Payout)
+		}
+
+		return operatorGain.Div(bet.Mul(decimal.NewFromInt(int64(numHands)))).Mul(decimal.NewFromInt(100)).InexactFloat64(), reasons
+	}
+
+	baseEdge, _ := houseEdge(DefaultRules())
+	bonusEdge, reasons := houseEdge(bonus)
+
+	t.Logf("House edge without bonus hands: %.2f%%", baseEdge)
+	t.Logf("House edge with 5-card Charlie, 2:1 suited 6-7-8, 3:1 7-7-7 and 2:1 suited blackjack: %.2f%%", bonusEdge)
+	for _, reason := range []OutcomeReason{ReasonCharlie, Reason678, Reason777, ReasonSuitedBlackjack, ReasonBlackjack} {
+		t.Logf("%s: %d hands", reason, reasons[reason])
+	}
+
+	if reasons[ReasonCharlie] == 0 || reasons[ReasonSuitedBlackjack] == 0 {
+		t.Errorf("expected Charlies and suited blackjacks in %d hands, got %v", numHands, reasons)
+	}
+	if bonusEdge >= baseEdge {
+		t.Errorf("bonus hands should cost the house edge: %.2f%% with them, %.2f%% without", bonusEdge, baseEdge)
+	}
+	if bonusEdge <= 0 {
+		t.Errorf("house edge with bonus hands should stay positive, got %.2f%%", bonusEdge)
+	}
+}
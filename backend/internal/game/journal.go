@@ -0,0 +1,211 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxJournalEvents is the number of events an engine keeps in its journal
+const maxJournalEvents = 20000
+
+// EventType names a transition recorded in the engine's journal
+type EventType string
+
+const (
+	EventBettingOpened    EventType = "betting_opened"
+	EventBetPlaced        EventType = "bet_placed"
+	EventSideBetsPlaced   EventType = "side_bets_placed"
+	EventBettingClosed    EventType = "betting_closed"
+	EventHandStarted      EventType = "hand_started" // Heads-up round started with StartHand
+	EventCardsDealt       EventType = "cards_dealt"
+	EventInsuranceDecided EventType = "insurance_decided"
+	EventPlayerHit        EventType = "player_hit"
+	EventPlayerStand      EventType = "player_stand"
+	EventPlayerDouble     EventType = "player_double"
+	EventPlayerSplit      EventType = "player_split"
+	EventPlayerSurrender  EventType = "player_surrender"
+	EventPlayerSwitch     EventType = "player_switch"
+	EventDealerPlayed     EventType = "dealer_played"
+	EventHandResolved     EventType = "hand_resolved"
+	EventRoundVoided      EventType = "round_voided" // The deal failed; bets are returned
+)
+
+// Event is one transition of the engine, as it stood once the transition finished
+// Events are never changed once journaled; a hand's state is the fold of its events.
+type Event struct {
+	Seq    int64     `json:"seq"` // Position in the table's journal, from 1
+	HandID int64     `json:"handId"`
+	Type   EventType `json:"type"`
+	Time   time.Time `json:"time"`
+
+	// Phases the transition went through, starting from From; Phase is the last of them
+	From   GamePhase   `json:"from"`
+	Phases []GamePhase `json:"phases,omitempty"`
+	Phase  GamePhase   `json:"phase"`
+	Detail string      `json:"detail"`
+
+	Action      *HandAction   `json:"action,omitempty"` // Player command behind the transition
+	ActiveSeat  int           `json:"activeSeat"`
+	ShoeID      int64         `json:"shoeId"`
+	DealerCards []Card        `json:"dealerCards"` // Face-up only; the hole card appears once revealed
+	Seats       []SeatOutcome `json:"seats"`       // Every seat in the round
+}
+
+// HandState is a hand's state folded from its events
+type HandState struct {
+	HandID      int64         `json:"handId"`
+	Seq         int64         `json:"seq"` // Last event applied
+	Phase       GamePhase     `json:"phase"`
+	Detail      string        `json:"detail"`
+	ActiveSeat  int           `json:"activeSeat"`
+	ShoeID      int64         `json:"shoeId"`
+	DealerCards []Card        `json:"dealerCards"`
+	Seats       []SeatOutcome `json:"seats"`
+	Actions     []HandAction  `json:"actions"`
+}
+
+// Apply folds one event into the state
+// Events must follow on from the state, and every phase change must be a valid transition.
+func (s *HandState) Apply(ev Event) error {
+	if s.Seq != 0 {
+		if ev.Seq != s.Seq+1 {
+			return fmt.Errorf("event %d does not follow event %d", ev.Seq, s.Seq)
+		}
+		if ev.HandID != s.HandID {
+			return fmt.Errorf("event %d is for hand %d, not %d", ev.Seq, ev.HandID, s.HandID)
+		}
+		if ev.From != s.Phase {
+			return fmt.Errorf("event %d starts in phase %s, hand is in %s", ev.Seq, ev.From, s.Phase)
+		}
+	}
+
+	phase := ev.From
+	for _, next := range ev.Phases {
+		if next == phase {
+			continue
+		}
+		if err := ValidateTransition(phase, next); err != nil {
+			return fmt.Errorf("event %d (%s): %w", ev.Seq, ev.Type, err)
+		}
+		phase = next
+	}
+	if phase != ev.Phase {
+		return fmt.Errorf("event %d (%s) ends in phase %s, its transitions reach %s", ev.Seq, ev.Type, ev.Phase, phase)
+	}
+
+	s.HandID = ev.HandID
+	s.Seq = ev.Seq
+	s.Phase = ev.Phase
+	s.Detail = ev.Detail
+	s.ActiveSeat = ev.ActiveSeat
+	s.ShoeID = ev.ShoeID
+	s.DealerCards = append([]Card{}, ev.DealerCards...)
+	s.Seats = append([]SeatOutcome{}, ev.Seats...)
+	if ev.Action != nil {
+		s.Actions = append(s.Actions, *ev.Action)
+	}
+	return nil
+}
+
+// FoldEvents derives a hand's state from its events, oldest first
+func FoldEvents(events []Event) (HandState, error) {
+	var state HandState
+	if len(events) == 0 {
+		return state, fmt.Errorf("no events to fold")
+	}
+	for _, ev := range events {
+		if err := state.Apply(ev); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+// Events returns the journaled events of a hand after the given sequence number, oldest first
+// The returned channel is closed when the next event is journaled, so callers can stream a
+// hand by waiting on it and asking again.
+func (e *GlobalEngine) Events(handID int64, after int64) ([]Event, <-chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.journalled == nil {
+		e.journalled = make(chan struct{})
+	}
+
+	var events []Event
+	for _, ev := range e.journal {
+		if ev.HandID == handID && ev.Seq > after {
+			events = append(events, ev)
+		}
+	}
+	return events, e.journalled
+}
+
+// setPhase moves the round to another phase, refusing transitions ValidateTransition doesn't allow
+// Staying in the same phase only updates the detail. Every phase change goes through here,
+// and is journaled with the next event.
+// Caller must hold e.mu
+func (e *GlobalEngine) setPhase(to GamePhase, detail string) error {
+	from := e.state.Phase
+	if to != from {
+		if err := ValidateTransition(from, to); err != nil {
+			return fmt.Errorf("hand %d: %w", e.state.HandID, err)
+		}
+		if len(e.phases) == 0 {
+			e.phasesFrom = from
+		}
+		e.phases = append(e.phases, to)
+	}
+
+	e.state.Phase = to
+	e.state.PhaseDetail = detail
+	return nil
+}
+
+// emit journals a transition with the state it left the round in and wakes any streams
+// Every transition may leave the round waiting on a new decision, so the decision timer is rearmed too.
+// Caller must hold e.mu
+func (e *GlobalEngine) emit(typ EventType, action *HandAction) {
+	e.armDecision(false)
+
+	from := e.state.Phase
+	if len(e.phases) > 0 {
+		from = e.phasesFrom
+	}
+
+	dealer := e.state.DealerCards
+	if !e.state.HoleCardRevealed && len(dealer) > 1 {
+		dealer = dealer[:1]
+	}
+
+	e.seq++
+	ev := Event{
+		Seq:         e.seq,
+		HandID:      e.state.HandID,
+		Type:        typ,
+		Time:        e.now(),
+		From:        from,
+		Phases:      e.phases,
+		Phase:       e.state.Phase,
+		Detail:      e.state.PhaseDetail,
+		ActiveSeat:  e.state.ActiveSeat,
+		ShoeID:      e.state.ShoeID,
+		DealerCards: append([]Card{}, dealer...),
+		Seats:       e.state.HandOutcome().Seats,
+	}
+	if action != nil {
+		a := *action
+		ev.Action = &a
+	}
+	e.phases = nil
+
+	e.journal = append(e.journal, ev)
+	if over := len(e.journal) - maxJournalEvents; over > 0 {
+		e.journal = append([]Event(nil), e.journal[over:]...)
+	}
+
+	if e.journalled != nil {
+		close(e.journalled)
+	}
+	e.journalled = make(chan struct{})
+}
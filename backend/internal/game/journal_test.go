@@ -0,0 +1,154 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJournalFoldsToEngineState(t *testing.T) {
+	rules := DefaultRules()
+	rules.AllowSurrender = true
+	seats := []int{1, 3, 4}
+
+	e := NewEngine(rules)
+	for _, n := range seats {
+		if err := e.JoinSeat(n, seatPlayer(n)); err != nil {
+			t.Fatalf("JoinSeat: %v", err)
+		}
+	}
+
+	seen := make(map[EventType]bool)
+	for handID := int64(1); handID <= 40; handID++ {
+		playTableHand(t, e, handID, seats)
+
+		events, _ := e.Events(handID, 0)
+		state, err := FoldEvents(events)
+		if err != nil {
+			t.Fatalf("hand %d: %v", handID, err)
+		}
+
+		engine := e.GetState()
+		if state.Phase != PhaseComplete || state.HandID != handID {
+			t.Fatalf("hand %d folded to hand %d in %s", handID, state.HandID, state.Phase)
+		}
+		want := engine.HandOutcome()
+		if !reflect.DeepEqual(state.DealerCards, want.DealerCards) || !reflect.DeepEqual(state.Seats, want.Seats) {
+			t.Fatalf("hand %d folded to %+v, engine has %+v", handID, state, want)
+		}
+		if !reflect.DeepEqual(state.Actions, e.hand.Actions) {
+			t.Fatalf("hand %d folded actions %+v, engine logged %+v", handID, state.Actions, e.hand.Actions)
+		}
+		for _, ev := range events {
+			seen[ev.Type] = true
+		}
+	}
+
+	for _, typ := range []EventType{EventBettingOpened, EventBetPlaced, EventSideBetsPlaced, EventBettingClosed, EventCardsDealt,
+		EventInsuranceDecided, EventPlayerHit, EventPlayerStand, EventPlayerDouble, EventPlayerSplit, EventDealerPlayed, EventHandResolved} {
+		if !seen[typ] {
+			t.Errorf("no %s event journaled", typ)
+		}
+	}
+}
+
+func TestJournalHidesHoleCard(t *testing.T) {
+	e := NewEngine(DefaultRules())
+	for seed := 0; seed < 20; seed++ {
+		handID := int64(seed + 1)
+		if err := e.StartHand(handID, seatPlayer(0), "0xtoken", "100", 100); err != nil {
+			t.Fatalf("StartHand: %v", err)
+		}
+		if err := e.ShuffleAndDeal([]byte{byte(seed)}); err != nil {
+			t.Fatalf("ShuffleAndDeal: %v", err)
+		}
+
+		events, _ := e.Events(handID, 0)
+		dealt := events[len(events)-1]
+		if dealt.Type != EventCardsDealt {
+			t.Fatalf("last event is %s, want %s", dealt.Type, EventCardsDealt)
+		}
+		if dealt.Phase == PhasePlayerTurn {
+			if len(dealt.DealerCards) != 1 {
+				t.Fatalf("cards_dealt shows %d dealer cards with the hole card down", len(dealt.DealerCards))
+			}
+			if !reflect.DeepEqual(dealt.Phases, []GamePhase{PhaseDealing, PhasePlayerTurn}) || dealt.From != PhaseShuffling {
+				t.Errorf("cards_dealt went %s → %v, want SHUFFLING → [DEALING PLAYER_TURN]", dealt.From, dealt.Phases)
+			}
+			return
+		}
+		if err := e.FinishRound(); err != nil {
+			t.Fatalf("FinishRound: %v", err)
+		}
+	}
+	t.Fatal("no hand reached the player's turn")
+}
+
+func TestJournalRejectsInvalidTransitions(t *testing.T) {
+	e := NewEngine(DefaultRules())
+	if err := e.StartHand(1, seatPlayer(0), "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+
+	// The engine refuses a phase change the state machine doesn't allow
+	if err := e.setPhase(PhaseDealerTurn, ""); err == nil {
+		t.Error("expected SHUFFLING → DEALER_TURN to be refused")
+	}
+	if e.GetState().Phase != PhaseShuffling {
+		t.Errorf("phase = %s after a refused transition", e.GetState().Phase)
+	}
+
+	if err := e.ShuffleAndDeal([]byte("journal")); err != nil {
+		t.Fatalf("ShuffleAndDeal: %v", err)
+	}
+	events, _ := e.Events(1, 0)
+	if _, err := FoldEvents(events); err != nil {
+		t.Fatalf("FoldEvents: %v", err)
+	}
+
+	// Folding checks every event against the state machine and the events before it
+	if _, err := FoldEvents([]Event{events[1], events[0]}); err == nil {
+		t.Error("expected an error folding events out of order")
+	}
+	moved := append([]Event(nil), events...)
+	moved[1].From = PhaseBetting
+	if _, err := FoldEvents(moved); err == nil {
+		t.Error("expected an error folding an event that starts from another phase")
+	}
+
+	forged := append([]Event(nil), events...)
+	last := forged[len(forged)-1]
+	last.Phases = []GamePhase{PhaseDealing, PhaseComplete}
+	last.Phase = PhaseComplete
+	forged[len(forged)-1] = last
+	if _, err := FoldEvents(forged); err == nil {
+		t.Error("expected DEALING → COMPLETE to be refused when folding")
+	}
+}
+
+func TestEventsSignalNewEvents(t *testing.T) {
+	e := NewEngine(DefaultRules())
+	if err := e.JoinSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+
+	events, next := e.Events(1, 0)
+	if len(events) != 0 {
+		t.Fatalf("%d events before the hand started", len(events))
+	}
+	if err := e.OpenBetting(1); err != nil {
+		t.Fatalf("OpenBetting: %v", err)
+	}
+	select {
+	case <-next:
+	default:
+		t.Fatal("stream not woken by a new event")
+	}
+
+	events, _ = e.Events(1, 0)
+	if len(events) != 1 || events[0].Type != EventBettingOpened || events[0].Phase != PhaseBetting {
+		t.Fatalf("events = %+v, want betting_opened", events)
+	}
+	if later, _ := e.Events(1, events[0].Seq); len(later) != 0 {
+		t.Errorf("%d events after the last one", len(later))
+	}
+}
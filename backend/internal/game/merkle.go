@@ -0,0 +1,169 @@
+package game
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Domain prefixes keep a leaf from ever hashing the same as an inner node
+const (
+	merkleLeafPrefix byte = 0x00
+	merkleNodePrefix byte = 0x01
+)
+
+// ShoeCommitment is the Merkle root over a shoe's salted card positions
+// Each leaf hashes a position, its card and a salt derived from the shoe seed, so proving one card
+// shows only sibling hashes and gives away nothing about the rest of the shoe
+type ShoeCommitment struct {
+	Root   string `json:"root"`   // Hex
+	Cards  int    `json:"cards"`  // Positions in the tree
+	Refill int    `json:"refill"` // Discard reshuffles before this arrangement (0 = as shuffled)
+}
+
+// ProofNode is one sibling hash on the path from a leaf to the root
+type ProofNode struct {
+	Hash string `json:"hash"` // Hex
+	Left bool   `json:"left"` // The sibling is the left child
+}
+
+// CardProof proves that a card was at a position of a committed shoe
+type CardProof struct {
+	Root     string      `json:"root"`     // Hex root the proof is against
+	Position int         `json:"position"` // 0-based position in the shoe
+	Card     Card        `json:"card"`
+	Salt     string      `json:"salt"` // Hex
+	Path     []ProofNode `json:"path"` // Leaf to root
+}
+
+// Commitment returns the Merkle commitment of the shoe as currently arranged
+// A refill rearranges the shoe, so it gets a new commitment
+func (d *Deck) Commitment() ShoeCommitment {
+	levels := d.merkleTree()
+	root := levels[len(levels)-1][0]
+	return ShoeCommitment{
+		Root:   hex.EncodeToString(root[:]),
+		Cards:  len(d.Cards),
+		Refill: d.refills,
+	}
+}
+
+// Prove returns the inclusion proof of a dealt card
+// Cards still in the shoe can't be proven, so a proof never reveals a card before it is dealt
+func (d *Deck) Prove(position int) (CardProof, error) {
+	if position < 0 || position >= d.index {
+		return CardProof{}, fmt.Errorf("position %d has not been dealt (%d cards dealt)", position, d.index)
+	}
+
+	levels := d.merkleTree()
+	path := make([]ProofNode, 0, len(levels)-1)
+	i := position
+	for _, level := range levels[:len(levels)-1] {
+		sibling := i ^ 1
+		if sibling < len(level) {
+			path = append(path, ProofNode{Hash: hex.EncodeToString(level[sibling][:]), Left: sibling < i})
+		}
+		i /= 2
+	}
+
+	root := levels[len(levels)-1][0]
+	return CardProof{
+		Root:     hex.EncodeToString(root[:]),
+		Position: position,
+		Card:     d.Cards[position],
+		Salt:     hex.EncodeToString(d.salt(position)),
+		Path:     path,
+	}, nil
+}
+
+// VerifyCardProof checks that the proof's card was at its position in the shoe committed to by root
+func VerifyCardProof(root string, proof CardProof) error {
+	want, err := hex.DecodeString(strings.TrimPrefix(root, "0x"))
+	if err != nil || len(want) != sha256.Size {
+		return fmt.Errorf("invalid root %q: want 32 hex bytes", root)
+	}
+	salt, err := hex.DecodeString(strings.TrimPrefix(proof.Salt, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid salt %q: want hex bytes", proof.Salt)
+	}
+	if proof.Position < 0 {
+		return fmt.Errorf("invalid position %d", proof.Position)
+	}
+
+	hash := merkleLeaf(proof.Position, proof.Card, salt)
+	for i, node := range proof.Path {
+		sibling, err := hex.DecodeString(strings.TrimPrefix(node.Hash, "0x"))
+		if err != nil || len(sibling) != sha256.Size {
+			return fmt.Errorf("invalid hash %q at proof step %d", node.Hash, i)
+		}
+		if node.Left {
+			hash = merkleNode([32]byte(sibling), hash)
+		} else {
+			hash = merkleNode(hash, [32]byte(sibling))
+		}
+	}
+
+	if !bytes.Equal(hash[:], want) {
+		return fmt.Errorf("%s at position %d does not hash to root %s", proof.Card, proof.Position, root)
+	}
+	return nil
+}
+
+// salt returns the secret salt of a position in the shoe's current arrangement
+func (d *Deck) salt(position int) []byte {
+	return DeriveSeed(DeriveSeed(d.seed, "merkle", d.refills), "salt", position)
+}
+
+// merkleTree returns every level of the tree over the shoe, leaves first, building it on first use
+func (d *Deck) merkleTree() [][][32]byte {
+	if d.tree != nil {
+		return d.tree
+	}
+
+	level := make([][32]byte, len(d.Cards))
+	for i, card := range d.Cards {
+		level[i] = merkleLeaf(i, card, d.salt(i))
+	}
+	if len(level) == 0 {
+		level = append(level, sha256.Sum256(nil))
+	}
+
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i]) // A node without a sibling is carried up unchanged
+			} else {
+				next = append(next, merkleNode(level[i], level[i+1]))
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	d.tree = levels
+	return levels
+}
+
+// merkleLeaf hashes a shoe position with its card and salt
+func merkleLeaf(position int, card Card, salt []byte) [32]byte {
+	data := make([]byte, 0, 10+len(salt))
+	data = append(data, merkleLeafPrefix)
+	data = binary.BigEndian.AppendUint64(data, uint64(position))
+	data = append(data, card.Pack())
+	data = append(data, salt...)
+	return sha256.Sum256(data)
+}
+
+// merkleNode hashes two children into their parent
+func merkleNode(left, right [32]byte) [32]byte {
+	data := make([]byte, 0, 1+2*sha256.Size)
+	data = append(data, merkleNodePrefix)
+	data = append(data, left[:]...)
+	data = append(data, right[:]...)
+	return sha256.Sum256(data)
+}
@@ -0,0 +1,191 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCardProofs(t *testing.T) {
+	for _, size := range []int{1, 2, 7, 52, 416} {
+		t.Run(fmt.Sprintf("%d cards", size), func(t *testing.T) {
+			deck := NewDeck(8)
+			deck.Shuffle([]byte("merkle shoe"))
+			deck.Cards = deck.Cards[:size]
+			root := deck.Commitment().Root
+
+			if _, err := deck.Prove(0); err == nil {
+				t.Error("expected an error proving a card that has not been dealt")
+			}
+			for i := 0; i < size; i++ {
+				card := drawn(t, deck)
+				proof, err := deck.Prove(i)
+				if err != nil {
+					t.Fatalf("Prove(%d): %v", i, err)
+				}
+				if proof.Card != card || proof.Root != root {
+					t.Fatalf("proof %d = %s under %s, want %s under %s", i, proof.Card, proof.Root, card, root)
+				}
+				if err := VerifyCardProof(root, proof); err != nil {
+					t.Fatalf("VerifyCardProof(%d): %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCardProofTampering(t *testing.T) {
+	deck := NewDeck(1)
+	deck.Shuffle([]byte("merkle shoe"))
+	drawn(t, deck)
+	drawn(t, deck)
+	root := deck.Commitment().Root
+
+	proof, err := deck.Prove(1)
+	if err != nil {
+		t.Fatalf("Prove: %v", err)
+	}
+
+	other := deck.Cards[0]
+	for name, tamper := range map[string]func(p *CardProof){
+		"card":     func(p *CardProof) { p.Card = other },
+		"position": func(p *CardProof) { p.Position = 0 },
+		"salt":     func(p *CardProof) { p.Salt = "00" + p.Salt[2:] },
+		"path":     func(p *CardProof) { p.Path = p.Path[1:] },
+		"side":     func(p *CardProof) { p.Path[0].Left = !p.Path[0].Left },
+	} {
+		t.Run(name, func(t *testing.T) {
+			tampered := proof
+			tampered.Path = append([]ProofNode(nil), proof.Path...)
+			tamper(&tampered)
+			if err := VerifyCardProof(root, tampered); err == nil {
+				t.Errorf("expected a tampered %s to fail", name)
+			}
+		})
+	}
+
+	// Another shoe's root doesn't vouch for the card
+	reshuffled := NewDeck(1)
+	reshuffled.Shuffle([]byte("another shoe"))
+	if err := VerifyCardProof(reshuffled.Commitment().Root, proof); err == nil {
+		t.Error("expected a proof against another shoe's root to fail")
+	}
+}
+
+func TestEngineCardProofs(t *testing.T) {
+	rules := DefaultRules()
+	rules.Decks = 1
+	rules.PenetrationBps = 10000 // Seven seats run the shoe dry, so refilled shoes are proven too
+
+	seats := []int{0, 1, 2, 3, 4, 5, 6}
+	e := NewEngine(rules)
+	for _, n := range seats {
+		if err := e.JoinSeat(n, seatPlayer(n)); err != nil {
+			t.Fatalf("JoinSeat: %v", err)
+		}
+	}
+
+	roots := make(map[string]bool)
+	refilled := false
+	for handID := int64(1); handID <= 30; handID++ {
+		playTableHand(t, e, handID, seats)
+		state := e.GetState()
+		roots[state.ShoeRoot] = true
+		if len(state.ShoeRoots) == 0 || state.ShoeRoots[0] != state.ShoeRoot {
+			t.Fatalf("hand %d: shoe roots %v, want the published root %s first", handID, state.ShoeRoots, state.ShoeRoot)
+		}
+		refilled = refilled || len(state.ShoeRoots) > 1
+
+		// Every card on the table is proven against one of the shoe's roots, the hole card once it is turned over
+		shoeRoots := make(map[string]bool)
+		for _, root := range state.ShoeRoots {
+			shoeRoots[root] = true
+		}
+		proven := make(map[Card]int)
+		for _, proof := range state.CardProofs {
+			if err := VerifyCardProof(proof.Root, proof); err != nil {
+				t.Fatalf("hand %d: %v", handID, err)
+			}
+			if !shoeRoots[proof.Root] {
+				t.Fatalf("hand %d: proof of %s under %s, not a root of the shoe %v", handID, proof.Card, proof.Root, state.ShoeRoots)
+			}
+			proven[proof.Card]++
+		}
+		cards := state.DealerCards
+		if !state.HoleCardRevealed {
+			cards = cards[:1]
+		}
+		for _, n := range seats {
+			for _, hand := range state.Seats[n].Hands {
+				cards = append(cards, hand.Cards...)
+			}
+		}
+		for _, card := range cards {
+			if proven[card] == 0 {
+				t.Fatalf("hand %d: no proof for %s", handID, card)
+			}
+			proven[card]--
+		}
+	}
+	if len(roots) < 2 {
+		t.Errorf("saw %d shoe roots, want a new root for each shoe", len(roots))
+	}
+	if !refilled {
+		t.Error("no shoe was refilled mid-round")
+	}
+}
+
+func TestHoleCardProofWithheld(t *testing.T) {
+	e := NewEngine(DefaultRules())
+	if err := e.JoinSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+
+	for seed := 0; seed < 20; seed++ {
+		handID := int64(seed + 1)
+		if err := e.OpenBetting(handID); err != nil {
+			t.Fatalf("OpenBetting: %v", err)
+		}
+		if err := e.PlaceBet(0, seatPlayer(0), "0xtoken", "100"); err != nil {
+			t.Fatalf("PlaceBet: %v", err)
+		}
+		if err := e.CloseBetting(); err != nil {
+			t.Fatalf("CloseBetting: %v", err)
+		}
+		if err := e.ShuffleAndDeal([]byte(fmt.Sprintf("hole card %d", seed))); err != nil {
+			t.Fatalf("ShuffleAndDeal: %v", err)
+		}
+
+		state := e.GetState()
+		if state.Phase != PhasePlayerTurn {
+			e.Reset()
+			continue
+		}
+		holePosition := state.CardsDealt - 1 // Dealt last
+		if state.HoleCardRevealed || len(state.CardProofs) != 3 {
+			t.Fatalf("%d proofs with the hole card down, want the 3 face-up cards", len(state.CardProofs))
+		}
+		for _, proof := range state.CardProofs {
+			if proof.Position == holePosition {
+				t.Fatalf("hole card proof published before it was revealed")
+			}
+		}
+
+		if err := e.SeatStand(0, seatPlayer(0)); err != nil {
+			t.Fatalf("SeatStand: %v", err)
+		}
+		if err := e.FinishRound(); err != nil {
+			t.Fatalf("FinishRound: %v", err)
+		}
+		state = e.GetState()
+		for _, proof := range state.CardProofs {
+			if proof.Position == holePosition {
+				if !state.HoleCardRevealed || proof.Card != state.DealerCards[1] {
+					t.Fatalf("hole card proof is for %s, want the revealed %s", proof.Card, state.DealerCards[1])
+				}
+				return
+			}
+		}
+		t.Fatal("no proof for the revealed hole card")
+	}
+	t.Fatal("no hand reached the player's turn")
+}
@@ -0,0 +1,423 @@
+package game
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/DanDo385/blackjack/backend/internal/random"
+)
+
+// maxHandHistory is the number of verifiable hand logs an engine keeps
+const maxHandHistory = 500
+
+// ErrShoeInPlay is returned for a hand whose shoe is still being dealt
+// Its seed would give away every card left in the shoe, so the log is held back until the shuffle
+var ErrShoeInPlay = errors.New("shoe still in play")
+
+// ErrHandNotFound is returned for a hand the engine has no log of
+var ErrHandNotFound = errors.New("hand not found")
+
+// ActionType is a player action recorded in a hand's log
+type ActionType string
+
+const (
+	ActionBet       ActionType = "bet"
+	ActionSideBets  ActionType = "side_bets"
+	ActionInsurance ActionType = "insurance"
+	ActionHit       ActionType = "hit"
+	ActionStand     ActionType = "stand"
+	ActionDouble    ActionType = "double"
+	ActionSplit     ActionType = "split"
+	ActionSurrender ActionType = "surrender"
+	ActionSwitch    ActionType = "switch"
+)
+
+// HandAction is one player action, in the order the engine accepted it
+type HandAction struct {
+	Seat     int            `json:"seat"`
+	Action   ActionType     `json:"action"`
+	Player   string         `json:"player,omitempty"`   // bet
+	Token    string         `json:"token,omitempty"`    // bet
+	Amount   string         `json:"amount,omitempty"`   // bet, or insurance (empty for the maximum); in wei
+	Buy      bool           `json:"buy,omitempty"`      // insurance
+	SideBets []SideBetWager `json:"sideBets,omitempty"` // side_bets
+	TimedOut bool           `json:"timedOut,omitempty"` // stand or insurance applied when the decision timer ran out
+}
+
+// HandSeed locates a hand in its shoe
+type HandSeed struct {
+	Shoe    string         `json:"shoe"`    // Hex shuffle seed of the shoe
+	Version ShuffleVersion `json:"version"` // Shuffle algorithm of the shoe
+	Offset  int            `json:"offset"`  // Cards dealt from the shoe before the hand
+
+	// Revealed seeds of a provably fair shoe; the shoe seed must derive from them
+	Fair *random.Reveal `json:"fair,omitempty"`
+}
+
+// verifyFair checks that a provably fair shoe was shuffled from its revealed seeds
+func (s HandSeed) verifyFair() error {
+	if s.Fair == nil {
+		return nil
+	}
+
+	seed, err := s.Fair.Verify()
+	if err != nil {
+		return err
+	}
+	if shoe := hex.EncodeToString(seed); shoe != strings.ToLower(strings.TrimPrefix(s.Shoe, "0x")) {
+		return fmt.Errorf("shoe seed %s is not derived from the revealed seeds (want %s)", s.Shoe, shoe)
+	}
+	return nil
+}
+
+// deck rebuilds the shoe as it stood when the hand was dealt
+func (s HandSeed) deck(rules Rules) (*Deck, error) {
+	seed, err := hex.DecodeString(strings.TrimPrefix(s.Shoe, "0x"))
+	if err != nil || len(seed) == 0 {
+		return nil, fmt.Errorf("invalid shoe seed %q: want hex bytes", s.Shoe)
+	}
+
+	deck := rules.NewDeck()
+	if err := deck.ShuffleWithVersion(seed, s.Version); err != nil {
+		return nil, err
+	}
+	deck.SetCutCard(rules.ReshuffleAt())
+
+	if s.Offset < 0 || s.Offset >= len(deck.Cards) {
+		return nil, fmt.Errorf("offset %d outside a %d-card shoe", s.Offset, len(deck.Cards))
+	}
+	deck.index = s.Offset
+	return deck, nil
+}
+
+// HandLog is everything needed to replay a hand, and the result the table recorded for it
+type HandLog struct {
+	HandID  int64        `json:"handId"`
+	Rules   Rules        `json:"rules"`
+	Seed    HandSeed     `json:"seed"`
+	Actions []HandAction `json:"actions"`
+	Result  *HandOutcome `json:"result,omitempty"` // Set once the hand is resolved
+}
+
+// Replay replays the logged hand
+func (l HandLog) Replay() (*EngineState, error) {
+	state, err := Replay(l.Rules, l.Seed, l.Actions)
+	if err != nil {
+		return nil, err
+	}
+	state.HandID = l.HandID
+	return state, nil
+}
+
+// Verify replays the logged hand and checks it against the recorded result
+// A provably fair hand's shoe seed is first checked against the revealed seeds.
+// The replayed outcome is returned even when it does not match.
+func (l HandLog) Verify() (HandOutcome, error) {
+	if err := l.Seed.verifyFair(); err != nil {
+		return HandOutcome{}, fmt.Errorf("hand %d: %w", l.HandID, err)
+	}
+
+	state, err := l.Replay()
+	if err != nil {
+		return HandOutcome{}, fmt.Errorf("replay hand %d: %w", l.HandID, err)
+	}
+
+	got := state.HandOutcome()
+	if l.Result == nil {
+		return got, fmt.Errorf("hand %d has no recorded result", l.HandID)
+	}
+	return got, l.Result.Compare(got)
+}
+
+// HandOutcome is what a hand dealt and paid: the part of the state a replay must reproduce
+type HandOutcome struct {
+	DealerCards []Card        `json:"dealerCards"`
+	Seats       []SeatOutcome `json:"seats"`
+}
+
+// SeatOutcome is one seat's cards and settlement
+type SeatOutcome struct {
+	Seat             int             `json:"seat"`
+	Hands            []PlayerHand    `json:"hands"`
+	SideBets         []SideBetResult `json:"sideBets"`
+	InsuranceAmount  string          `json:"insuranceAmount"`
+	InsuranceOutcome string          `json:"insuranceOutcome"`
+	InsurancePayout  string          `json:"insurancePayout"`
+	Outcome          string          `json:"outcome"`
+	Payout           string          `json:"payout"`
+}
+
+// HandOutcome returns the cards and settlement of every seat in the round
+func (s *EngineState) HandOutcome() HandOutcome {
+	out := HandOutcome{
+		DealerCards: append([]Card{}, s.DealerCards...),
+		Seats:       []SeatOutcome{},
+	}
+	for _, seat := range s.Seats {
+		if !seat.InRound {
+			continue
+		}
+		out.Seats = append(out.Seats, SeatOutcome{
+			Seat:             seat.Number,
+			Hands:            copyHands(seat.Hands),
+			SideBets:         append([]SideBetResult{}, seat.SideBets...),
+			InsuranceAmount:  seat.InsuranceAmount,
+			InsuranceOutcome: seat.InsuranceOutcome,
+			InsurancePayout:  seat.InsurancePayout,
+			Outcome:          seat.Outcome,
+			Payout:           seat.Payout,
+		})
+	}
+	return out
+}
+
+// Compare returns an error describing the first difference from another outcome, nil if they match
+func (o HandOutcome) Compare(other HandOutcome) error {
+	if !reflect.DeepEqual(o.DealerCards, other.DealerCards) {
+		return fmt.Errorf("dealer cards %v, replay dealt %v", o.DealerCards, other.DealerCards)
+	}
+	if len(o.Seats) != len(other.Seats) {
+		return fmt.Errorf("%d seats in the round, replay has %d", len(o.Seats), len(other.Seats))
+	}
+
+	for i, want := range o.Seats {
+		got := other.Seats[i]
+		if want.Seat != got.Seat {
+			return fmt.Errorf("seat %d in the round, replay has seat %d", want.Seat, got.Seat)
+		}
+		if len(want.Hands) != len(got.Hands) {
+			return fmt.Errorf("seat %d: %d hands, replay has %d", want.Seat, len(want.Hands), len(got.Hands))
+		}
+		for h := range want.Hands {
+			w, g := want.Hands[h], got.Hands[h]
+			if !reflect.DeepEqual(w.Cards, g.Cards) {
+				return fmt.Errorf("seat %d hand %d: cards %v, replay dealt %v", want.Seat, h+1, w.Cards, g.Cards)
+			}
+			if !reflect.DeepEqual(w, g) {
+				return fmt.Errorf("seat %d hand %d: bet %s %s for %s, replay bet %s %s for %s",
+					want.Seat, h+1, w.Bet, w.Outcome, w.Payout, g.Bet, g.Outcome, g.Payout)
+			}
+		}
+		if !reflect.DeepEqual(want.SideBets, got.SideBets) {
+			return fmt.Errorf("seat %d side bets %+v, replay has %+v", want.Seat, want.SideBets, got.SideBets)
+		}
+		if !reflect.DeepEqual(want, got) {
+			return fmt.Errorf("seat %d settled %s for %s (insurance %s), replay settled %s for %s (insurance %s)",
+				want.Seat, want.Outcome, want.Payout, want.InsuranceOutcome, got.Outcome, got.Payout, got.InsuranceOutcome)
+		}
+	}
+	return nil
+}
+
+// Replay deals a hand again from its seed and plays the logged actions on a fresh engine
+// Bets and side bets come first; the cards are dealt before the first decision. After every
+// action the dealer plays and the hand is resolved as soon as the engine allows, just as the
+// handlers do, so the returned state holds the cards, dealer play and payouts the table produced.
+func Replay(rules Rules, seed HandSeed, actions []HandAction) (*EngineState, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+	deck, err := seed.deck(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every betting seat is taken before the betting window opens
+	e := NewEngine(rules)
+	seated := make(map[int]bool)
+	for i, a := range actions {
+		if a.Action != ActionBet || seated[a.Seat] {
+			continue
+		}
+		seated[a.Seat] = true
+		if err := e.JoinSeat(a.Seat, a.Player); err != nil {
+			return nil, fmt.Errorf("action %d (%s, seat %d): %w", i, a.Action, a.Seat, err)
+		}
+	}
+	if err := e.OpenBetting(0); err != nil {
+		return nil, err
+	}
+
+	dealt := false
+	for i, a := range actions {
+		if !dealt && a.Action != ActionBet && a.Action != ActionSideBets {
+			if err := e.dealFrom(deck); err != nil {
+				return nil, err
+			}
+			dealt = true
+		}
+		if err := e.apply(a); err != nil {
+			return nil, fmt.Errorf("action %d (%s, seat %d): %w", i, a.Action, a.Seat, err)
+		}
+		if dealt {
+			if err := e.FinishRound(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !dealt {
+		if err := e.dealFrom(deck); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.FinishRound(); err != nil {
+		return nil, err
+	}
+	return e.GetState(), nil
+}
+
+// dealFrom closes betting and deals the round from a shoe already positioned for it
+func (e *GlobalEngine) dealFrom(deck *Deck) error {
+	if err := e.CloseBetting(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.useShoe(deck)
+	err := e.dealInitialCards()
+	e.syncActiveSeat()
+	if err != nil {
+		return fmt.Errorf("deal: %w", err)
+	}
+	return nil
+}
+
+// apply plays one logged action as the player holding its seat
+func (e *GlobalEngine) apply(a HandAction) error {
+	e.mu.RLock()
+	player := e.holder(a.Seat)
+	e.mu.RUnlock()
+
+	switch a.Action {
+	case ActionBet:
+		return e.PlaceBet(a.Seat, a.Player, a.Token, a.Amount)
+	case ActionSideBets:
+		return e.SeatSideBets(a.Seat, player, a.SideBets)
+	case ActionInsurance:
+		return e.SeatInsurance(a.Seat, player, a.Buy, a.Amount)
+	case ActionHit:
+		return e.SeatHit(a.Seat, player)
+	case ActionStand:
+		return e.SeatStand(a.Seat, player)
+	case ActionDouble:
+		return e.SeatDouble(a.Seat, player)
+	case ActionSplit:
+		return e.SeatSplit(a.Seat, player)
+	case ActionSurrender:
+		return e.SeatSurrender(a.Seat, player)
+	case ActionSwitch:
+		return e.SeatSwitch(a.Seat, player)
+	default:
+		return fmt.Errorf("unknown action %q", a.Action)
+	}
+}
+
+// HandLog returns the log of a hand dealt from a retired shoe
+// Hands from the shoe still in play return ErrShoeInPlay
+func (e *GlobalEngine) HandLog(handID int64) (HandLog, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for i := len(e.history) - 1; i >= 0; i-- {
+		if l := e.history[i]; l.HandID == handID {
+			l.Actions = append([]HandAction(nil), l.Actions...)
+			return l, nil
+		}
+	}
+	for _, l := range e.shoeHands {
+		if l.HandID == handID {
+			return HandLog{}, fmt.Errorf("hand %d: %w", handID, ErrShoeInPlay)
+		}
+	}
+	if e.hand != nil && e.hand.HandID == handID {
+		return HandLog{}, fmt.Errorf("hand %d: %w", handID, ErrShoeInPlay)
+	}
+	return HandLog{}, fmt.Errorf("hand %d: %w", handID, ErrHandNotFound)
+}
+
+// record appends an accepted player action to the current hand's log
+// Caller must hold e.mu
+func (e *GlobalEngine) record(a HandAction) {
+	if e.hand != nil {
+		e.hand.Actions = append(e.hand.Actions, a)
+	}
+}
+
+// logDeal records where in the shoe the current hand is dealt from
+// Caller must hold e.mu
+func (e *GlobalEngine) logDeal() {
+	deck := e.state.Deck
+	if e.hand == nil || deck == nil {
+		return
+	}
+
+	e.hand.Seed = HandSeed{Shoe: hex.EncodeToString(deck.seed), Version: deck.Version(), Offset: deck.Dealt()}
+	e.shoeHands = append(e.shoeHands, e.hand)
+}
+
+// logResult records the settled hand, publishing its shoe's logs if the cut card has come out
+// Caller must hold e.mu
+func (e *GlobalEngine) logResult() {
+	if e.hand != nil {
+		result := e.state.HandOutcome()
+		e.hand.Result = &result
+	}
+	if e.state.Deck != nil && e.state.Deck.CutCardReached() {
+		e.retireShoe()
+	}
+}
+
+// Retire retires the shoe in play, as when the table closes, and returns everything the table has published:
+// the revealed server seeds and the logs of the hands dealt from retired shoes, oldest first
+// A round cut short is logged without its result
+func (e *GlobalEngine) Retire() ([]random.Reveal, []HandLog) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.retireShoe()
+	return append([]random.Reveal(nil), e.reveals...), append([]HandLog(nil), e.history...)
+}
+
+// RetireIdle is Retire for a table between rounds
+// A table in any other phase than WAITING_FOR_DEAL or COMPLETE has a round in play: it is left untouched and false returned
+func (e *GlobalEngine) RetireIdle() ([]random.Reveal, []HandLog, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.Phase != PhaseWaitingForDeal && e.state.Phase != PhaseComplete {
+		return nil, nil, false
+	}
+	e.retireShoe()
+	return append([]random.Reveal(nil), e.reveals...), append([]HandLog(nil), e.history...), true
+}
+
+// retireShoe publishes the logs of the hands dealt from the current shoe, and its server seed
+// No card of a retired shoe is dealt again, so its seed gives nothing away
+// Caller must hold e.mu
+func (e *GlobalEngine) retireShoe() {
+	var reveal *random.Reveal
+	if e.shoeCommit != nil {
+		r := e.shoeCommit.Reveal()
+		reveal = &r
+		e.shoeCommit = nil
+		e.reveals = append(e.reveals, r)
+		if over := len(e.reveals) - maxHandHistory; over > 0 {
+			e.reveals = append([]random.Reveal(nil), e.reveals[over:]...)
+		}
+	}
+
+	for _, l := range e.shoeHands {
+		l.Seed.Fair = reveal
+		e.history = append(e.history, *l)
+	}
+	e.shoeHands = nil
+
+	if over := len(e.history) - maxHandHistory; over > 0 {
+		e.history = append([]HandLog(nil), e.history[over:]...)
+	}
+}
@@ -0,0 +1,206 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// playTableHand plays one multi-seat round with a fixed policy: insure on even hand IDs, switch when offered,
+// split any pair but tens and fives, double 11, surrender 16 against a ten, hit below 17
+func playTableHand(t *testing.T, e *GlobalEngine, handID int64, seats []int) {
+	t.Helper()
+
+	if err := e.OpenBetting(handID); err != nil {
+		t.Fatalf("OpenBetting: %v", err)
+	}
+	for _, n := range seats {
+		if err := e.PlaceBet(n, seatPlayer(n), "0xtoken", fmt.Sprintf("%d", 100*(n+1))); err != nil {
+			t.Fatalf("PlaceBet: %v", err)
+		}
+	}
+	if err := e.SeatSideBets(seats[0], seatPlayer(seats[0]), []SideBetWager{{Kind: SideBetPerfectPairs, Amount: "10"}}); err != nil {
+		t.Fatalf("SeatSideBets: %v", err)
+	}
+	if err := e.CloseBetting(); err != nil {
+		t.Fatalf("CloseBetting: %v", err)
+	}
+	if err := e.ShuffleAndDeal([]byte(fmt.Sprintf("replay shoe %d", handID))); err != nil {
+		t.Fatalf("ShuffleAndDeal: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		state := e.GetState()
+		seat := state.ActiveSeat
+		switch state.Phase {
+		case PhaseInsuranceOffer:
+			if err := e.SeatInsurance(seat, seatPlayer(seat), handID%2 == 0, ""); err != nil {
+				t.Fatalf("SeatInsurance: %v", err)
+			}
+		case PhasePlayerTurn:
+			hand := state.Seats[seat].Hands[state.Seats[seat].ActiveHand]
+			total, _ := CalculateHandValue(hand.Cards)
+			pair := len(hand.Cards) == 2 && hand.Cards[0].Rank == hand.Cards[1].Rank
+			var err error
+			switch {
+			case e.Rules().Variant == VariantSwitch && !hand.Switched && e.SeatSwitch(seat, seatPlayer(seat)) == nil:
+			case pair && !isTenValue(hand.Cards[0]) && hand.Cards[0].Rank != Five && e.SeatSplit(seat, seatPlayer(seat)) == nil:
+			case total == 11 && len(hand.Cards) == 2 && e.SeatDouble(seat, seatPlayer(seat)) == nil:
+			case total == 16 && isTenValue(state.DealerCards[0]) && e.SeatSurrender(seat, seatPlayer(seat)) == nil:
+			case total < 17:
+				err = e.SeatHit(seat, seatPlayer(seat))
+			default:
+				err = e.SeatStand(seat, seatPlayer(seat))
+			}
+			if err != nil {
+				t.Fatalf("hand %d seat %d: %v", handID, seat, err)
+			}
+		default:
+			if err := e.FinishRound(); err != nil {
+				t.Fatalf("FinishRound: %v", err)
+			}
+			if e.GetState().Phase != PhaseComplete {
+				t.Fatalf("phase = %s after finishing the round", e.GetState().Phase)
+			}
+			return
+		}
+		if err := e.FinishRound(); err != nil {
+			t.Fatalf("FinishRound: %v", err)
+		}
+	}
+	t.Fatalf("hand %d did not finish", handID)
+}
+
+func TestReplayReproducesEngine(t *testing.T) {
+	surrender := DefaultRules()
+	surrender.Decks = 1
+	surrender.AllowSurrender = true
+
+	// Seven seats and no cut card run the shoe dry mid-round, so the discards are reshuffled
+	refill := DefaultRules()
+	refill.Decks = 1
+	refill.PenetrationBps = 10000
+
+	obo := DefaultRules()
+	obo.Decks = 2
+	obo.DealingMode = DealOBO
+
+	variants := make(map[VariantName]Rules)
+	for _, v := range Variants() {
+		rules := v.Rules(DefaultRules())
+		rules.Decks = 2
+		rules.Variant = v.Name()
+		variants[v.Name()] = rules
+	}
+
+	for _, tc := range []struct {
+		name  string
+		rules Rules
+		seats []int
+	}{
+		{"three seats", surrender, []int{0, 2, 5}},
+		{"refilled shoes", refill, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"no hole card", obo, []int{1, 3, 4}},
+		{"spanish 21", variants[VariantSpanish21], []int{0, 6}},
+		{"free bet", variants[VariantFreeBet], []int{2, 3}},
+		{"switch", variants[VariantSwitch], []int{0, 4}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := NewEngine(tc.rules)
+			for _, n := range tc.seats {
+				if err := e.JoinSeat(n, seatPlayer(n)); err != nil {
+					t.Fatalf("JoinSeat: %v", err)
+				}
+			}
+			const hands = 60
+			for handID := int64(1); handID <= hands; handID++ {
+				playTableHand(t, e, handID, tc.seats)
+			}
+
+			// Hands from the shoe being dealt are held back
+			if _, err := e.HandLog(hands); !e.GetState().CutCardReached && !errors.Is(err, ErrShoeInPlay) {
+				t.Errorf("HandLog of the current shoe: %v, want ErrShoeInPlay", err)
+			}
+			if _, err := e.HandLog(hands + 1); !errors.Is(err, ErrHandNotFound) {
+				t.Errorf("HandLog of an unknown hand: %v, want ErrHandNotFound", err)
+			}
+
+			verified := 0
+			played := make(map[ActionType]int)
+			for handID := int64(1); handID <= hands; handID++ {
+				log, err := e.HandLog(handID)
+				if errors.Is(err, ErrShoeInPlay) {
+					continue
+				}
+				if err != nil {
+					t.Fatalf("HandLog(%d): %v", handID, err)
+				}
+				if _, err := log.Verify(); err != nil {
+					t.Errorf("hand %d: %v", handID, err)
+				}
+				for _, a := range log.Actions {
+					played[a.Action]++
+				}
+				verified++
+			}
+			if verified < hands/2 {
+				t.Fatalf("verified %d of %d hands, want most shoes retired", verified, hands)
+			}
+			for _, action := range []ActionType{ActionBet, ActionSideBets, ActionInsurance, ActionHit, ActionStand, ActionDouble, ActionSplit} {
+				if played[action] == 0 {
+					t.Errorf("no %s action among the verified hands", action)
+				}
+			}
+			if tc.rules.AllowSurrender && played[ActionSurrender] == 0 {
+				t.Error("no surrender among the verified hands")
+			}
+			if tc.rules.Variant == VariantSwitch && played[ActionSwitch] == 0 {
+				t.Error("no switch among the verified hands")
+			}
+		})
+	}
+}
+
+func TestReplayDetectsTampering(t *testing.T) {
+	rules := DefaultRules()
+	rules.Decks = 1
+	rules.PenetrationBps = 770 // Cut card after 4 cards: every hand retires its shoe
+
+	e := NewEngine(rules)
+	if err := e.JoinSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+	playTableHand(t, e, 7, []int{0})
+
+	log, err := e.HandLog(7)
+	if err != nil {
+		t.Fatalf("HandLog: %v", err)
+	}
+	if _, err := log.Verify(); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	// A different shoe deals different cards
+	tampered := log
+	tampered.Seed.Offset = 1
+	if _, err := tampered.Verify(); err == nil {
+		t.Error("expected a mismatch for a shifted shoe")
+	}
+
+	// A bigger payout than the replay produces is caught
+	result := *log.Result
+	result.Seats = append([]SeatOutcome(nil), result.Seats...)
+	result.Seats[0].Payout = "1000000"
+	tampered = log
+	tampered.Result = &result
+	if _, err := tampered.Verify(); err == nil {
+		t.Error("expected a mismatch for an altered payout")
+	}
+
+	// An action the engine would refuse fails the replay
+	tampered = log
+	tampered.Actions = append(append([]HandAction(nil), log.Actions...), HandAction{Seat: 0, Action: ActionHit})
+	if _, err := tampered.Verify(); err == nil {
+		t.Error("expected an error replaying an action after the hand is over")
+	}
+}
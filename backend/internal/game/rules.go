@@ -0,0 +1,414 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// DoubleRule restricts which two-card totals may double down
+type DoubleRule string
+
+const (
+	// DoubleAnyTwo allows doubling on any two cards
+	DoubleAnyTwo DoubleRule = "any"
+
+	// DoubleNineToEleven allows doubling on totals of 9, 10 and 11 only
+	DoubleNineToEleven DoubleRule = "9-11"
+
+	// DoubleTenToEleven allows doubling on totals of 10 and 11 only
+	DoubleTenToEleven DoubleRule = "10-11"
+)
+
+// SurrenderMode selects when surrender is offered relative to the dealer's peek
+type SurrenderMode string
+
+const (
+	// SurrenderLate allows surrender only after the dealer has checked for blackjack
+	SurrenderLate SurrenderMode = "late"
+
+	// SurrenderEarly allows surrender before the dealer checks for blackjack (European style)
+	SurrenderEarly SurrenderMode = "early"
+)
+
+// DealingMode selects how the dealer's second card is dealt and what a dealer blackjack takes
+type DealingMode string
+
+const (
+	// DealHoleCard deals the dealer a face-down hole card and peeks for blackjack before anyone acts (US style)
+	DealHoleCard DealingMode = "hole_card"
+
+	// DealENHC deals the dealer's second card only after every player has acted (European no hole card)
+	// A dealer blackjack then takes doubles and split bets too
+	DealENHC DealingMode = "enhc"
+
+	// DealOBO is no hole card where a dealer blackjack only takes the original bets
+	// Doubles and extra split bets are returned
+	DealOBO DealingMode = "obo"
+)
+
+// OutcomeReason names the rule that settled a hand
+type OutcomeReason string
+
+const (
+	ReasonBlackjack        OutcomeReason = "blackjack"          // Player blackjack at the table's payout
+	ReasonSuitedBlackjack  OutcomeReason = "suited_blackjack"   // Blackjack of one suit at the suited payout
+	ReasonDealerBlackjack  OutcomeReason = "dealer_blackjack"   // Dealer blackjack takes the hand, or pushes a player blackjack
+	ReasonBust             OutcomeReason = "bust"               // Player busted
+	ReasonDealerBust       OutcomeReason = "dealer_bust"        // Dealer busted
+	ReasonTotal            OutcomeReason = "total"              // Totals compared
+	ReasonCharlie          OutcomeReason = "charlie"            // N cards without busting
+	Reason678              OutcomeReason = "6-7-8"              // 6-7-8 bonus
+	Reason777              OutcomeReason = "7-7-7"              // 7-7-7 bonus
+	ReasonFiveCard21       OutcomeReason = "five_card_21"       // 21 of five or more cards (Spanish 21)
+	ReasonPlayer21         OutcomeReason = "player_21"          // A player 21 always wins (Spanish 21)
+	ReasonDealer22         OutcomeReason = "dealer_22"          // Dealer 22 pushes (Free Bet, Switch)
+	ReasonOriginalBetsOnly OutcomeReason = "original_bets_only" // Only the original bet lost to a dealer blackjack
+	ReasonSurrender        OutcomeReason = "surrender"
+	ReasonEvenMoney        OutcomeReason = "even_money"
+)
+
+// Settlement is how a finished hand is settled
+type Settlement struct {
+	Outcome string          // win, lose, push or surrender
+	Payout  decimal.Decimal // Net for the player: the winnings on a win, 0 on a push, minus what was lost on a loss or surrender
+	Reason  OutcomeReason
+}
+
+// Rules configures how the engine plays a table
+// The first block mirrors the Solidity ITable.Rules struct field for field,
+// the rest are off-chain options the contract does not need to know about
+type Rules struct {
+	// ITable.Rules
+	Decks          int  `json:"decks"`          // 7
+	PenetrationBps int  `json:"penetrationBps"` // 6700 (cut card at 67% of the shoe)
+	HitSoft17      bool `json:"hitSoft17"`      // true
+	BJPayoutBps    int  `json:"bjPayoutBps"`    // 14000 (7:5 payout)
+	AllowDAS       bool `json:"allowDAS"`       // true (non-aces)
+	AllowSurrender bool `json:"allowSurrender"` // false
+	SplitAcesOnce  bool `json:"splitAcesOnce"`  // true
+	MidShoeEntry   bool `json:"midShoeEntry"`   // false
+
+	// Engine options
+	MaxSplitHands int           `json:"maxSplitHands"` // Maximum player hands after (re-)splitting
+	DoubleOn      DoubleRule    `json:"doubleOn"`      // Two-card totals that may double down
+	SurrenderMode SurrenderMode `json:"surrenderMode"` // late or early
+	DealingMode   DealingMode   `json:"dealingMode"`   // hole_card, enhc or obo
+	Variant       VariantName   `json:"variant"`       // Game dealt at the table; it fixes some of the rules above
+
+	// Bonus hands (0 = off). Bonus payouts are in bps of the bet, like bjPayoutBps
+	CharlieCards      int `json:"charlieCards"`      // A hand of this many cards without busting wins at even money (5 = five-card Charlie)
+	Suited678Bps      int `json:"suited678Bps"`      // Suited 6-7-8 wins this much whatever the dealer makes
+	Triple7Bps        int `json:"triple7Bps"`        // 7-7-7 wins this much whatever the dealer makes
+	SuitedBJPayoutBps int `json:"suitedBJPayoutBps"` // Blackjack of one suit pays this instead of bjPayoutBps
+
+	// Decision timers: when one runs out the seat stands, or declines insurance (0 = no timer)
+	ActionTimeoutSec    int `json:"actionTimeoutSec"`    // 30
+	InsuranceTimeoutSec int `json:"insuranceTimeoutSec"` // 15
+}
+
+// DefaultRules returns the standard table rules (matches the deployed Table defaults)
+func DefaultRules() Rules {
+	return Rules{
+		Decks:          7,
+		PenetrationBps: 6700,
+		HitSoft17:      true,
+		BJPayoutBps:    14000,
+		AllowDAS:       true,
+		AllowSurrender: false,
+		SplitAcesOnce:  true,
+		MidShoeEntry:   false,
+
+		MaxSplitHands: 4,
+		DoubleOn:      DoubleAnyTwo,
+		SurrenderMode: SurrenderLate,
+		DealingMode:   DealHoleCard,
+		Variant:       VariantClassic,
+
+		ActionTimeoutSec:    30,
+		InsuranceTimeoutSec: 15,
+	}
+}
+
+// ParseRules decodes JSON rules on top of the defaults and validates them
+// Fields missing from the JSON keep their default values, and the rules the variant fixes override the JSON
+func ParseRules(data []byte) (Rules, error) {
+	rules := DefaultRules()
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return Rules{}, fmt.Errorf("invalid rules JSON: %w", err)
+	}
+
+	variant, err := LookupVariant(rules.Variant)
+	if err != nil {
+		return Rules{}, err
+	}
+	rules = variant.Rules(rules)
+
+	if err := rules.Validate(); err != nil {
+		return Rules{}, err
+	}
+
+	return rules, nil
+}
+
+// Validate checks that the rules describe a playable table
+// Decks is capped at 8, the largest shoe dealt in practice and the most the multi-deck basic strategy chart is drawn for;
+// well inside the contract's uint8, just as the bps limits fit its uint16 fields
+func (r Rules) Validate() error {
+	if r.Decks < 1 || r.Decks > 8 {
+		return fmt.Errorf("decks must be between 1 and 8, got %d", r.Decks)
+	}
+
+	if r.PenetrationBps < 1 || r.PenetrationBps > 10000 {
+		return fmt.Errorf("penetrationBps must be between 1 and 10000, got %d", r.PenetrationBps)
+	}
+
+	// Blackjack never pays less than even money
+	if r.BJPayoutBps < 10000 || r.BJPayoutBps > 65535 {
+		return fmt.Errorf("bjPayoutBps must be between 10000 and 65535, got %d", r.BJPayoutBps)
+	}
+
+	if r.MaxSplitHands < 1 {
+		return fmt.Errorf("maxSplitHands must be at least 1, got %d", r.MaxSplitHands)
+	}
+
+	switch r.DoubleOn {
+	case DoubleAnyTwo, DoubleNineToEleven, DoubleTenToEleven:
+	default:
+		return fmt.Errorf("unknown doubleOn rule %q", r.DoubleOn)
+	}
+
+	if r.AllowSurrender && r.SurrenderMode != SurrenderLate && r.SurrenderMode != SurrenderEarly {
+		return fmt.Errorf("unknown surrenderMode %q", r.SurrenderMode)
+	}
+
+	switch r.DealingMode {
+	case "", DealHoleCard, DealENHC, DealOBO:
+	default:
+		return fmt.Errorf("unknown dealingMode %q", r.DealingMode)
+	}
+
+	// Without a hole card the dealer has no blackjack to peek for while the player decides,
+	// so a late surrender would give back half the bet even against a dealer blackjack, as early surrender does
+	if r.AllowSurrender && r.SurrenderMode == SurrenderLate && !r.Peeks() {
+		return fmt.Errorf("late surrender needs a hole card, not dealingMode %q; use early surrender", r.DealingMode)
+	}
+
+	if r.CharlieCards != 0 && (r.CharlieCards < 5 || r.CharlieCards > 10) {
+		return fmt.Errorf("charlieCards must be 0 (off) or between 5 and 10, got %d", r.CharlieCards)
+	}
+
+	for name, bps := range map[string]int{"suited678Bps": r.Suited678Bps, "triple7Bps": r.Triple7Bps} {
+		if bps != 0 && (bps < 10000 || bps > 65535) {
+			return fmt.Errorf("%s must be 0 (off) or between 10000 and 65535, got %d", name, bps)
+		}
+	}
+
+	// A suited blackjack is a bonus, so it never pays less than any other blackjack
+	if r.SuitedBJPayoutBps != 0 && (r.SuitedBJPayoutBps < r.BJPayoutBps || r.SuitedBJPayoutBps > 65535) {
+		return fmt.Errorf("suitedBJPayoutBps must be 0 (off) or between bjPayoutBps %d and 65535, got %d", r.BJPayoutBps, r.SuitedBJPayoutBps)
+	}
+
+	variant, err := LookupVariant(r.Variant)
+	if err != nil {
+		return err
+	}
+	if variant.Rules(r) != r {
+		return fmt.Errorf("rules conflict with the ones the %s variant fixes", variant.Name())
+	}
+
+	if r.ActionTimeoutSec < 0 || r.InsuranceTimeoutSec < 0 {
+		return fmt.Errorf("decision timeouts can't be negative, got %ds and %ds", r.ActionTimeoutSec, r.InsuranceTimeoutSec)
+	}
+
+	return nil
+}
+
+// ReshuffleAt returns the number of dealt cards at which the cut card comes out
+// Same formula as the Table contract's reshuffleAt, for the variant's deck size
+func (r Rules) ReshuffleAt() int {
+	return r.Decks * r.DeckSize() * r.PenetrationBps / 10000
+}
+
+// DeckSize returns the number of cards in one of the table's decks: 52, or 48 without the tens of Spanish 21
+func (r Rules) DeckSize() int {
+	return len(Suits) * len(r.variant().Ranks())
+}
+
+// NewDeck returns an unshuffled shoe of the table's decks, built for its variant
+func (r Rules) NewDeck() *Deck {
+	return newDeck(r.Decks, r.variant().Ranks())
+}
+
+// variant returns the game dealt at the table
+// Unknown names fall back to classic blackjack; Validate rejects them
+func (r Rules) variant() Variant {
+	variant, err := LookupVariant(r.Variant)
+	if err != nil {
+		return classic{}
+	}
+	return variant
+}
+
+// Peeks reports whether the dealer takes a hole card and checks it for blackjack before players act
+// An empty mode is the US hole card game
+func (r Rules) Peeks() bool {
+	return r.DealingMode != DealENHC && r.DealingMode != DealOBO
+}
+
+// DealerPlay plays out the dealer's hand under these rules
+// Without a hole card the dealer starts from the upcard alone and its first draw is the second card
+func (r Rules) DealerPlay(deck *Deck, dealerCards []Card) ([]Card, error) {
+	return DealerPlay(deck, dealerCards, r.HitSoft17)
+}
+
+// DealerShouldHit reports whether the dealer draws another card under these rules
+func (r Rules) DealerShouldHit(dealerCards []Card) bool {
+	return DealerShouldHit(dealerCards, r.HitSoft17)
+}
+
+// EvaluateOutcome evaluates a player hand as dealt against the dealer at this table's payouts,
+// bonus hands and the variant's own settlement included
+func (r Rules) EvaluateOutcome(playerCards []Card, dealerCards []Card, betAmount decimal.Decimal) Settlement {
+	return r.EvaluateHand(PlayerHand{Cards: playerCards}, dealerCards, betAmount, true)
+}
+
+// EvaluateHand settles one of the engine's player hands, including split and surrendered hands
+// A surrendered hand settles as "surrender", losing half of betAmount.
+// betAmount is the player's own stake; a free bet the house put up wins with it but costs the player nothing when it loses.
+// original marks the seat's first hand, the one carrying the original bet. Under original bets only
+// a doubled hand lost to a dealer blackjack loses just the original bet, and every other split hand is a push.
+// Busted hands are lost whatever the dealer has
+// The 6-7-8 and 7-7-7 bonuses are not paid on doubled, split or switched hands
+func (r Rules) EvaluateHand(hand PlayerHand, dealerCards []Card, betAmount decimal.Decimal, original bool) Settlement {
+	if hand.Surrendered {
+		return Settlement{Outcome: "surrender", Payout: betAmount.Div(decimal.NewFromInt(2)).Neg(), Reason: ReasonSurrender}
+	}
+
+	stake := betAmount.Add(hand.freeBet())
+	dealt := !hand.FromSplit && !hand.Switched
+	s := r.evaluate(hand.Cards, dealerCards, stake, dealt, dealt && !hand.Doubled)
+	s = r.variant().Settle(r, hand, dealerCards, stake, s)
+	if s.Outcome != "lose" {
+		return s
+	}
+	s.Payout = betAmount.Neg() // A free bet costs the player nothing when it loses
+
+	if r.DealingMode != DealOBO || !IsBlackjack(dealerCards) || IsBust(hand.Cards) {
+		return s
+	}
+	switch {
+	case !original:
+		return Settlement{Outcome: "push", Payout: decimal.Zero, Reason: ReasonOriginalBetsOnly}
+	case hand.Doubled:
+		return Settlement{Outcome: "lose", Payout: betAmount.Div(decimal.NewFromInt(2)).Neg(), Reason: ReasonOriginalBetsOnly}
+	default:
+		return s
+	}
+}
+
+// BlackjackPayout returns what a player blackjack wins on a bet, at the suited payout when the table has one
+func (r Rules) BlackjackPayout(cards []Card, betAmount decimal.Decimal) (decimal.Decimal, OutcomeReason) {
+	bps, reason := r.BJPayoutBps, ReasonBlackjack
+	if r.SuitedBJPayoutBps != 0 && len(cards) == 2 && cards[0].Suit == cards[1].Suit {
+		bps, reason = r.SuitedBJPayoutBps, ReasonSuitedBlackjack
+	}
+	return betAmount.Mul(decimal.NewFromInt(int64(bps))).Div(decimal.NewFromInt(10000)), reason
+}
+
+// evaluate settles a hand against the dealer
+// natural reports whether a two-card 21 counts as blackjack, bonuses whether 6-7-8 and 7-7-7 pay
+func (r Rules) evaluate(playerCards []Card, dealerCards []Card, betAmount decimal.Decimal, natural bool, bonuses bool) Settlement {
+	playerBJ := natural && IsBlackjack(playerCards)
+	dealerBJ := IsBlackjack(dealerCards)
+
+	switch {
+	case playerBJ && dealerBJ:
+		return Settlement{Outcome: "push", Payout: decimal.Zero, Reason: ReasonDealerBlackjack}
+	case playerBJ:
+		payout, reason := r.BlackjackPayout(playerCards, betAmount)
+		return Settlement{Outcome: "win", Payout: payout, Reason: reason}
+	case dealerBJ:
+		return Settlement{Outcome: "lose", Payout: betAmount.Neg(), Reason: ReasonDealerBlackjack}
+	case IsBust(playerCards):
+		return Settlement{Outcome: "lose", Payout: betAmount.Neg(), Reason: ReasonBust}
+	}
+
+	// Bonus hands win whatever the dealer makes short of blackjack
+	if bonuses {
+		if bps, reason := r.bonusHand(playerCards); bps != 0 {
+			return Settlement{Outcome: "win", Payout: betAmount.Mul(decimal.NewFromInt(int64(bps))).Div(decimal.NewFromInt(10000)), Reason: reason}
+		}
+	}
+	if r.charlie(playerCards) {
+		return Settlement{Outcome: "win", Payout: betAmount, Reason: ReasonCharlie}
+	}
+
+	if IsBust(dealerCards) {
+		return Settlement{Outcome: "win", Payout: betAmount, Reason: ReasonDealerBust}
+	}
+
+	playerValue, _ := CalculateHandValue(playerCards)
+	dealerValue, _ := CalculateHandValue(dealerCards)
+	switch {
+	case playerValue > dealerValue:
+		return Settlement{Outcome: "win", Payout: betAmount, Reason: ReasonTotal}
+	case playerValue < dealerValue:
+		return Settlement{Outcome: "lose", Payout: betAmount.Neg(), Reason: ReasonTotal}
+	default:
+		return Settlement{Outcome: "push", Payout: decimal.Zero, Reason: ReasonTotal}
+	}
+}
+
+// bonusHand returns the payout of a 6-7-8 or 7-7-7 bonus the table pays on these cards, or 0
+func (r Rules) bonusHand(cards []Card) (int, OutcomeReason) {
+	if len(cards) != 3 {
+		return 0, ""
+	}
+
+	var ranks [King + 1]int
+	suited := true
+	for _, c := range cards {
+		ranks[c.Rank]++
+		suited = suited && c.Suit == cards[0].Suit
+	}
+	switch {
+	case ranks[Seven] == 3:
+		return r.Triple7Bps, Reason777
+	case suited && ranks[Six] == 1 && ranks[Seven] == 1 && ranks[Eight] == 1:
+		return r.Suited678Bps, Reason678
+	}
+	return 0, ""
+}
+
+// charlie reports whether a hand has reached the table's Charlie without busting
+func (r Rules) charlie(cards []Card) bool {
+	return r.CharlieCards > 0 && len(cards) >= r.CharlieCards && !IsBust(cards)
+}
+
+// earlySurrender reports whether surrender is offered before the dealer peeks
+func (r Rules) earlySurrender() bool {
+	return r.AllowSurrender && r.SurrenderMode == SurrenderEarly
+}
+
+// CanDouble reports whether a hand may double down under these rules
+func (r Rules) CanDouble(cards []Card, fromSplit bool) bool {
+	if len(cards) != 2 {
+		return false
+	}
+
+	if fromSplit && !r.AllowDAS {
+		return false
+	}
+
+	total, _ := CalculateHandValue(cards)
+	switch r.DoubleOn {
+	case DoubleNineToEleven:
+		return total >= 9 && total <= 11
+	case DoubleTenToEleven:
+		return total >= 10 && total <= 11
+	default:
+		return true
+	}
+}
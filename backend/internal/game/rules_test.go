@@ -0,0 +1,230 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDefaultRulesMatchTable(t *testing.T) {
+	rules := DefaultRules()
+
+	if err := rules.Validate(); err != nil {
+		t.Fatalf("default rules invalid: %v", err)
+	}
+
+	// Same as Table.sol: decks * 52 * penetrationBps / 10000
+	if got, want := rules.ReshuffleAt(), 7*52*6700/10000; got != want {
+		t.Fatalf("ReshuffleAt = %d, want %d", got, want)
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(r *Rules)
+	}{
+		{"zero decks", func(r *Rules) { r.Decks = 0 }},
+		{"too many decks", func(r *Rules) { r.Decks = 9 }},
+		{"zero penetration", func(r *Rules) { r.PenetrationBps = 0 }},
+		{"penetration past the shoe", func(r *Rules) { r.PenetrationBps = 10001 }},
+		{"blackjack below even money", func(r *Rules) { r.BJPayoutBps = 9000 }},
+		{"no hands", func(r *Rules) { r.MaxSplitHands = 0 }},
+		{"unknown double rule", func(r *Rules) { r.DoubleOn = "8-11" }},
+		{"unknown surrender mode", func(r *Rules) { r.AllowSurrender = true; r.SurrenderMode = "never" }},
+		{"unknown dealing mode", func(r *Rules) { r.DealingMode = "face_up" }},
+		{"late surrender without a hole card", func(r *Rules) { r.AllowSurrender = true; r.DealingMode = DealENHC }},
+		{"late surrender original bets only", func(r *Rules) { r.AllowSurrender = true; r.DealingMode = DealOBO }},
+		{"three-card charlie", func(r *Rules) { r.CharlieCards = 3 }},
+		{"6-7-8 bonus below even money", func(r *Rules) { r.Suited678Bps = 5000 }},
+		{"7-7-7 bonus past uint16", func(r *Rules) { r.Triple7Bps = 70000 }},
+		{"suited blackjack below blackjack", func(r *Rules) { r.SuitedBJPayoutBps = 12000 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			tt.mutate(&rules)
+
+			if err := rules.Validate(); err == nil {
+				t.Fatalf("expected validation error for %+v", rules)
+			}
+		})
+	}
+
+	// Early surrender is decided before the dealer's second card, hole card or not
+	rules := DefaultRules()
+	rules.AllowSurrender = true
+	rules.SurrenderMode = SurrenderEarly
+	rules.DealingMode = DealENHC
+	if err := rules.Validate(); err != nil {
+		t.Errorf("early surrender without a hole card: %v", err)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`{"decks": 6, "hitSoft17": false, "bjPayoutBps": 15000}`))
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+
+	if rules.Decks != 6 || rules.HitSoft17 || rules.BJPayoutBps != 15000 {
+		t.Fatalf("parsed rules = %+v, want 6 decks S17 3:2", rules)
+	}
+
+	// Unspecified fields keep their defaults
+	if rules.PenetrationBps != 6700 || !rules.SplitAcesOnce {
+		t.Fatalf("parsed rules lost defaults: %+v", rules)
+	}
+
+	if _, err := ParseRules([]byte(`{"decks": 0}`)); err == nil {
+		t.Fatal("expected error for invalid rules")
+	}
+}
+
+func TestEngineUsesRules(t *testing.T) {
+	rules := DefaultRules()
+	rules.HitSoft17 = false
+	rules.BJPayoutBps = 15000
+
+	// S17: dealer stands on soft 17
+	e := newTestEngine(t, rules,
+		card("6", "C"), card("A", "D"), // dealer soft 17
+		card("10", "H"), card("8", "S"), // player 18
+		card("3", "C"),
+	)
+	if err := e.PlayerStand(); err != nil {
+		t.Fatalf("PlayerStand: %v", err)
+	}
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if n := len(e.GetState().DealerCards); n != 2 {
+		t.Fatalf("dealer drew to %d cards, want stand on soft 17", n)
+	}
+
+	// Blackjack pays the table's configured payout
+	settled := rules.EvaluateOutcome(
+		[]Card{card("A", "H"), card("K", "S")},
+		[]Card{card("10", "C"), card("7", "D")},
+		decimal.NewFromInt(100),
+	)
+	if settled.Outcome != "win" || !settled.Payout.Equal(decimal.NewFromInt(150)) || settled.Reason != ReasonBlackjack {
+		t.Fatalf("blackjack = %+v, want win paying 150", settled)
+	}
+}
+
+func TestOriginalBetsOnly(t *testing.T) {
+	dealerBJ := []Card{card("10", "C"), card("A", "D")}
+	doubled := PlayerHand{Cards: []Card{card("6", "H"), card("5", "S"), card("9", "D")}, FromSplit: true, Doubled: true}
+	split := PlayerHand{Cards: []Card{card("8", "H"), card("10", "S")}, FromSplit: true}
+	bust := PlayerHand{Cards: []Card{card("8", "C"), card("6", "S"), card("K", "D")}, FromSplit: true}
+
+	tests := []struct {
+		mode     DealingMode
+		hand     PlayerHand
+		original bool
+		bet      int64
+		outcome  string
+		payout   int64
+	}{
+		{DealENHC, doubled, true, 200, "lose", -200},
+		{DealENHC, split, false, 100, "lose", -100},
+		{DealOBO, doubled, true, 200, "lose", -100}, // The double is returned
+		{DealOBO, split, false, 100, "push", 0},     // Only the original bet is taken
+		{DealOBO, split, true, 100, "lose", -100},   // The first hand carries it
+		{DealOBO, bust, false, 100, "lose", -100},   // A bust is lost whatever the dealer has
+	}
+
+	for _, tt := range tests {
+		rules := DefaultRules()
+		rules.DealingMode = tt.mode
+
+		settled := rules.EvaluateHand(tt.hand, dealerBJ, decimal.NewFromInt(tt.bet), tt.original)
+		if settled.Outcome != tt.outcome || !settled.Payout.Equal(decimal.NewFromInt(tt.payout)) {
+			t.Errorf("%s %v original=%v: %s/%s, want %s/%d", tt.mode, tt.hand.Cards, tt.original, settled.Outcome, settled.Payout, tt.outcome, tt.payout)
+		}
+	}
+}
+
+func TestBonusHands(t *testing.T) {
+	rules := DefaultRules()
+	rules.CharlieCards = 5
+	rules.Suited678Bps = 20000
+	rules.Triple7Bps = 30000
+	rules.SuitedBJPayoutBps = 20000
+
+	dealer20 := []Card{card("K", "C"), card("Q", "D")}
+	dealerBust := []Card{card("K", "C"), card("6", "D"), card("9", "H")}
+	dealerBJ := []Card{card("A", "C"), card("K", "D")}
+	charlie := []Card{card("2", "C"), card("3", "D"), card("2", "H"), card("4", "S"), card("5", "C")}
+
+	tests := []struct {
+		name    string
+		hand    PlayerHand
+		dealer  []Card
+		outcome string
+		payout  int64
+		reason  OutcomeReason
+	}{
+		{"charlie beats 20", PlayerHand{Cards: charlie}, dealer20, "win", 100, ReasonCharlie},
+		{"charlie on a split hand", PlayerHand{Cards: charlie, FromSplit: true}, dealer20, "win", 100, ReasonCharlie},
+		{"charlie loses to a dealer blackjack", PlayerHand{Cards: charlie}, dealerBJ, "lose", -100, ReasonDealerBlackjack},
+		{"busted five cards", PlayerHand{Cards: append([]Card{card("K", "S")}, charlie...)}, dealer20, "lose", -100, ReasonBust},
+		{"suited 6-7-8", PlayerHand{Cards: []Card{card("7", "H"), card("6", "H"), card("8", "H")}}, dealer20, "win", 200, Reason678},
+		{"mixed 6-7-8 is a plain 21", PlayerHand{Cards: []Card{card("7", "H"), card("6", "D"), card("8", "H")}}, dealer20, "win", 100, ReasonTotal},
+		{"7-7-7", PlayerHand{Cards: []Card{card("7", "H"), card("7", "D"), card("7", "C")}}, dealer20, "win", 300, Reason777},
+		{"doubled 7-7-7 is a plain 21", PlayerHand{Cards: []Card{card("7", "H"), card("7", "D"), card("7", "C")}, Doubled: true}, dealerBust, "win", 100, ReasonDealerBust},
+		{"split 7-7-7 is a plain 21", PlayerHand{Cards: []Card{card("7", "H"), card("7", "D"), card("7", "C")}, FromSplit: true}, dealer20, "win", 100, ReasonTotal},
+		{"suited blackjack", PlayerHand{Cards: []Card{card("A", "S"), card("J", "S")}}, dealer20, "win", 200, ReasonSuitedBlackjack},
+		{"blackjack", PlayerHand{Cards: []Card{card("A", "S"), card("J", "H")}}, dealer20, "win", 140, ReasonBlackjack},
+		{"20 vs 20", PlayerHand{Cards: []Card{card("10", "S"), card("J", "H")}}, dealer20, "push", 0, ReasonTotal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settled := rules.EvaluateHand(tt.hand, tt.dealer, decimal.NewFromInt(100), true)
+			if settled.Outcome != tt.outcome || !settled.Payout.Equal(decimal.NewFromInt(tt.payout)) || settled.Reason != tt.reason {
+				t.Errorf("%v vs %v = %+v, want %s/%d (%s)", tt.hand.Cards, tt.dealer, settled, tt.outcome, tt.payout, tt.reason)
+			}
+		})
+	}
+
+	// Every bonus is off by default
+	settled := DefaultRules().EvaluateOutcome(charlie, dealer20, decimal.NewFromInt(100))
+	if settled.Outcome != "lose" || settled.Reason != ReasonTotal {
+		t.Errorf("five-card 16 at a default table = %+v, want a loss on totals", settled)
+	}
+}
+
+func TestCharlieFinishesHand(t *testing.T) {
+	rules := DefaultRules()
+	rules.CharlieCards = 5
+
+	e := newTestEngine(t, rules,
+		card("10", "C"), card("9", "D"), // dealer 19
+		card("2", "H"), card("3", "S"), // player 5
+		card("2", "C"), card("4", "D"), card("3", "H"), // hits to a five-card 14
+	)
+	for i := 0; i < 3; i++ {
+		if err := e.PlayerHit(); err != nil {
+			t.Fatalf("PlayerHit %d: %v", i, err)
+		}
+	}
+
+	// The fifth card ends the turn without a stand
+	if phase := e.GetState().Phase; phase != PhaseDealerTurn {
+		t.Fatalf("phase = %s after the Charlie, want DEALER_TURN", phase)
+	}
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+
+	hand := e.GetState().Hands[0]
+	if hand.Outcome != "win" || hand.Reason != ReasonCharlie || hand.Payout != "100" {
+		t.Errorf("charlie hand = %s/%s (%s), want a win paying 100 for the Charlie", hand.Outcome, hand.Payout, hand.Reason)
+	}
+}
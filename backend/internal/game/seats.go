@@ -0,0 +1,480 @@
+package game
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxSeats is the number of player positions at a table
+const MaxSeats = 7
+
+// Seat is one player position at the table
+// Seats are dealt and played in order, seat 0 first
+type Seat struct {
+	Number     int    `json:"number"`
+	PlayerAddr string `json:"playerAddr"` // Empty while the seat is open
+	TokenAddr  string `json:"tokenAddr"`
+	BetAmount  string `json:"betAmount"`  // In wei as string, empty until a bet is placed
+	Wagered    string `json:"wagered"`    // Bet times the hands it is dealt to (two at Blackjack Switch); in wei
+	InRound    bool   `json:"inRound"`    // Dealt into the current round
+	HeadsUp    bool   `json:"headsUp"`    // Taken by StartHand rather than JoinSeat
+	ClientSeed string `json:"clientSeed"` // The player's share of the next shoe's client seed, empty if unset
+
+	// Hands (several after splitting)
+	Hands      []PlayerHand `json:"hands"`
+	ActiveHand int          `json:"activeHand"`
+
+	// Side bets settle on the first two cards, which are kept through splits
+	SideBets     []SideBetResult `json:"sideBets"`
+	InitialCards []Card          `json:"initialCards"`
+
+	// Insurance side-wager
+	InsuranceDecided bool   `json:"insuranceDecided"`
+	InsuranceAmount  string `json:"insuranceAmount"`  // In wei as string
+	InsuranceOutcome string `json:"insuranceOutcome"` // declined, win, lose, even_money
+	InsurancePayout  string `json:"insurancePayout"`  // Won (positive) or lost (negative), in wei as string
+	EvenMoney        bool   `json:"evenMoney"`
+
+	// Outcome
+	Outcome string `json:"outcome"` // win, lose, push, surrender (net over the seat's hands)
+	Payout  string `json:"payout"`  // Won (positive) or lost (negative) over the seat's hands, side bets and insurance aside; in wei
+}
+
+// newSeats returns an empty table
+func newSeats() []Seat {
+	seats := make([]Seat, MaxSeats)
+	for i := range seats {
+		seats[i] = Seat{Number: i}
+		seats[i].resetRound()
+	}
+	return seats
+}
+
+// resetRound clears the seat's bet and cards for a new round
+func (s *Seat) resetRound() {
+	s.BetAmount = ""
+	s.Wagered = "0"
+	s.InRound = false
+	s.Hands = []PlayerHand{}
+	s.ActiveHand = 0
+	s.SideBets = []SideBetResult{}
+	s.InitialCards = []Card{}
+	s.InsuranceDecided = false
+	s.InsuranceAmount = "0"
+	s.InsuranceOutcome = ""
+	s.InsurancePayout = "0"
+	s.EvenMoney = false
+	s.Outcome = ""
+	s.Payout = "0"
+}
+
+// vacate frees the seat
+func (s *Seat) vacate() {
+	s.PlayerAddr = ""
+	s.TokenAddr = ""
+	s.HeadsUp = false
+	s.ClientSeed = ""
+	s.resetRound()
+}
+
+// placeBet stakes the bet on each of the hands the seat will be dealt
+func (s *Seat) placeBet(tokenAddr string, bet decimal.Decimal, hands int) {
+	s.TokenAddr = tokenAddr
+	s.BetAmount = bet.String()
+	s.Wagered = bet.Mul(decimal.NewFromInt(int64(hands))).String()
+}
+
+// enterRound deals the seat into the round with its bet on each of its hands
+func (s *Seat) enterRound(hands int) {
+	s.InRound = true
+	s.Hands = make([]PlayerHand, hands)
+	for i := range s.Hands {
+		s.Hands[i] = PlayerHand{Bet: s.BetAmount, Payout: "0"}
+	}
+	s.ActiveHand = 0
+}
+
+// finished reports whether every hand of the seat has been played
+func (s *Seat) finished() bool {
+	return s.firstOpenHand() == len(s.Hands)
+}
+
+// firstOpenHand returns the index of the seat's first hand still to play, or len(Hands) if none is
+func (s *Seat) firstOpenHand() int {
+	for i, hand := range s.Hands {
+		if !hand.Done {
+			return i
+		}
+	}
+	return len(s.Hands)
+}
+
+// JoinSeat sits a player down at an open seat
+// Without midShoeEntry nobody may join an occupied table until the shoe is reshuffled
+func (e *GlobalEngine) JoinSeat(seat int, playerAddr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, err := e.seat(seat)
+	if err != nil {
+		return err
+	}
+
+	if playerAddr == "" {
+		return fmt.Errorf("player address required")
+	}
+
+	if s.PlayerAddr != "" {
+		return fmt.Errorf("seat %d is taken", seat)
+	}
+
+	if !e.rules.MidShoeEntry && e.shoeInProgress() && e.occupiedSeats() > 0 {
+		return fmt.Errorf("mid-shoe entry not allowed at this table, wait for the shuffle")
+	}
+
+	s.PlayerAddr = playerAddr
+	s.HeadsUp = false
+	s.ClientSeed = ""
+	s.resetRound()
+	e.state.LastUpdated = e.now()
+
+	log.Printf("Player %s joined seat %d", playerAddr, seat)
+	return nil
+}
+
+// LeaveSeat frees a seat between rounds (or during the betting window)
+func (e *GlobalEngine) LeaveSeat(seat int, playerAddr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, err := e.heldSeat(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+
+	if s.InRound && !e.betweenRounds() {
+		return fmt.Errorf("cannot leave seat %d during a round", seat)
+	}
+
+	s.vacate()
+	e.syncActiveSeat()
+	e.state.LastUpdated = e.now()
+
+	log.Printf("Player %s left seat %d", playerAddr, seat)
+	return nil
+}
+
+// OpenBetting starts a round for the seated players
+// Seats taken by StartHand are released first
+// Transitions: WAITING_FOR_DEAL → BETTING
+func (e *GlobalEngine) OpenBetting(handID int64) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.Phase != PhaseWaitingForDeal && e.state.Phase != PhaseComplete {
+		return fmt.Errorf("cannot open betting in phase %s, must be WAITING_FOR_DEAL or COMPLETE", e.state.Phase)
+	}
+
+	for i := range e.state.Seats {
+		if e.state.Seats[i].HeadsUp {
+			e.state.Seats[i].vacate()
+		}
+	}
+
+	if e.occupiedSeats() == 0 {
+		return fmt.Errorf("no players seated")
+	}
+
+	e.resetRound(handID)
+	if err := e.setPhase(PhaseBetting, "Place your bets"); err != nil {
+		return err
+	}
+	e.state.LastUpdated = e.now()
+	e.emit(EventBettingOpened, nil)
+
+	log.Printf("Betting open: handID=%d, seated=%d", handID, e.occupiedSeats())
+	return nil
+}
+
+// PlaceBet records a seated player's bet during the betting window
+// Betting again replaces the earlier bet
+func (e *GlobalEngine) PlaceBet(seat int, playerAddr, tokenAddr, betAmount string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.Phase != PhaseBetting {
+		return fmt.Errorf("cannot bet in phase %s, must be BETTING", e.state.Phase)
+	}
+
+	s, err := e.heldSeat(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+
+	bet, err := decimal.NewFromString(betAmount)
+	if err != nil {
+		return fmt.Errorf("invalid bet amount: %w", err)
+	}
+	if !bet.IsPositive() {
+		return fmt.Errorf("bet must be positive, got %s", betAmount)
+	}
+
+	s.placeBet(tokenAddr, bet, e.rules.variant().HandsPerBet())
+	action := HandAction{Seat: seat, Action: ActionBet, Player: playerAddr, Token: tokenAddr, Amount: s.BetAmount}
+	e.record(action)
+	e.state.LastUpdated = e.now()
+	e.emit(EventBetPlaced, &action)
+
+	log.Printf("Bet placed: seat=%d, player=%s, amount=%s, wagered=%s", seat, playerAddr, s.BetAmount, s.Wagered)
+	return nil
+}
+
+// CloseBetting ends the betting window; every seat with a bet is dealt in
+// Transitions: BETTING → SHUFFLING
+func (e *GlobalEngine) CloseBetting() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.Phase != PhaseBetting {
+		return fmt.Errorf("cannot close betting in phase %s, must be BETTING", e.state.Phase)
+	}
+
+	first := -1
+	for i := range e.state.Seats {
+		s := &e.state.Seats[i]
+		if s.PlayerAddr == "" || s.BetAmount == "" {
+			continue
+		}
+		s.enterRound(e.rules.variant().HandsPerBet())
+		if first < 0 {
+			first = i
+		}
+	}
+
+	if first < 0 {
+		return fmt.Errorf("no bets placed")
+	}
+
+	e.state.ActiveSeat = first
+	if err := e.setPhase(PhaseShuffling, "Creating and shuffling deck..."); err != nil {
+		return err
+	}
+	e.syncActiveSeat()
+	e.state.LastUpdated = e.now()
+	e.emit(EventBettingClosed, nil)
+
+	log.Printf("Betting closed: handID=%d, seats in round=%d", e.state.HandID, len(e.roundSeats()))
+	return nil
+}
+
+// PlaceSideBets places side bets for the player whose seat is active
+func (e *GlobalEngine) PlaceSideBets(wagers []SideBetWager) error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatSideBets(seat, playerAddr, wagers)
+}
+
+// SeatSideBets places a seat's side bets before the cards are dealt
+// The seat needs a main bet; placing side bets again replaces the earlier ones
+func (e *GlobalEngine) SeatSideBets(seat int, playerAddr string, wagers []SideBetWager) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
+
+	if e.state.Phase != PhaseBetting && e.state.Phase != PhaseShuffling {
+		return fmt.Errorf("cannot place side bets in phase %s, must be BETTING or SHUFFLING", e.state.Phase)
+	}
+
+	s, err := e.heldSeat(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+
+	if s.BetAmount == "" || (e.state.Phase == PhaseShuffling && !s.InRound) {
+		return fmt.Errorf("seat %d has no bet this round", seat)
+	}
+
+	if err := ValidateSideBets(wagers); err != nil {
+		return err
+	}
+
+	e.placeSideBets(s, wagers)
+	return nil
+}
+
+// placeSideBets puts validated side bets on a seat and records them
+// Caller must hold e.mu
+func (e *GlobalEngine) placeSideBets(s *Seat, wagers []SideBetWager) {
+	s.SideBets = make([]SideBetResult, len(wagers))
+	for i, wager := range wagers {
+		amount, _ := decimal.NewFromString(wager.Amount)
+		s.SideBets[i] = SideBetResult{Kind: wager.Kind, Amount: amount.String(), Payout: "0"}
+	}
+	action := HandAction{Seat: s.Number, Action: ActionSideBets, SideBets: append([]SideBetWager(nil), wagers...)}
+	e.record(action)
+	e.state.LastUpdated = e.now()
+	e.emit(EventSideBetsPlaced, &action)
+
+	log.Printf("Side bets placed: seat=%d, bets=%d", s.Number, len(wagers))
+}
+
+// settleSideBets settles a seat's side bets against its first two cards and the dealer's hand
+// Caller must hold e.mu
+func (e *GlobalEngine) settleSideBets(s *Seat) error {
+	if len(s.SideBets) == 0 {
+		return nil
+	}
+
+	wagers := make([]SideBetWager, len(s.SideBets))
+	for i, bet := range s.SideBets {
+		wagers[i] = SideBetWager{Kind: bet.Kind, Amount: bet.Amount}
+	}
+
+	results, err := SettleSideBets(wagers, s.InitialCards, e.state.DealerCards)
+	if err != nil {
+		return fmt.Errorf("failed to settle side bets on seat %d: %w", s.Number, err)
+	}
+	s.SideBets = results
+	return nil
+}
+
+// turnPlayer returns the seat whose decision the round is waiting on and the player holding it
+func (e *GlobalEngine) turnPlayer() (int, string) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.state.ActiveSeat, e.holder(e.state.ActiveSeat)
+}
+
+// holder returns the player holding a seat, or "" for an open or invalid seat
+// Caller must hold e.mu
+func (e *GlobalEngine) holder(n int) string {
+	if n < 0 || n >= len(e.state.Seats) {
+		return ""
+	}
+	return e.state.Seats[n].PlayerAddr
+}
+
+// seat returns a seat by number
+// Caller must hold e.mu
+func (e *GlobalEngine) seat(n int) (*Seat, error) {
+	if n < 0 || n >= len(e.state.Seats) {
+		return nil, fmt.Errorf("invalid seat %d, table has %d seats", n, len(e.state.Seats))
+	}
+	return &e.state.Seats[n], nil
+}
+
+// heldSeat returns the seat if the player holds it
+// Caller must hold e.mu
+func (e *GlobalEngine) heldSeat(n int, playerAddr string) (*Seat, error) {
+	s, err := e.seat(n)
+	if err != nil {
+		return nil, err
+	}
+	if s.PlayerAddr == "" || s.PlayerAddr != playerAddr {
+		return nil, fmt.Errorf("seat %d is not held by %s", n, playerAddr)
+	}
+	return s, nil
+}
+
+// checkTurn returns the seat if it is the one to act and the player holds it
+// Caller must hold e.mu
+func (e *GlobalEngine) checkTurn(n int, playerAddr string) (*Seat, error) {
+	s, err := e.heldSeat(n, playerAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !s.InRound {
+		return nil, fmt.Errorf("seat %d is not in this round", n)
+	}
+	if n != e.state.ActiveSeat {
+		return nil, fmt.Errorf("not seat %d's turn, seat %d to act", n, e.state.ActiveSeat)
+	}
+	return s, nil
+}
+
+// roundSeats returns the numbers of the seats dealt into the current round, in deal order
+// Caller must hold e.mu
+func (e *GlobalEngine) roundSeats() []int {
+	var seats []int
+	for i := range e.state.Seats {
+		if e.state.Seats[i].InRound {
+			seats = append(seats, i)
+		}
+	}
+	return seats
+}
+
+// occupiedSeats counts the seats with a player
+// Caller must hold e.mu
+func (e *GlobalEngine) occupiedSeats() int {
+	n := 0
+	for _, s := range e.state.Seats {
+		if s.PlayerAddr != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// betweenRounds reports whether no round is being dealt or played
+// Caller must hold e.mu
+func (e *GlobalEngine) betweenRounds() bool {
+	switch e.state.Phase {
+	case PhaseWaitingForDeal, PhaseBetting, PhaseComplete:
+		return true
+	default:
+		return false
+	}
+}
+
+// shoeInProgress reports whether cards have been dealt from a shoe that is not due for a shuffle
+// Caller must hold e.mu
+func (e *GlobalEngine) shoeInProgress() bool {
+	deck := e.state.Deck
+	return deck != nil && deck.Dealt() > 0 && !deck.CutCardReached()
+}
+
+// syncActiveSeat mirrors the active seat into the single-player fields
+// (PlayerAddr, BetAmount, Hands, PlayerCards, insurance and outcome)
+// Caller must hold e.mu
+func (e *GlobalEngine) syncActiveSeat() {
+	if e.state.ActiveSeat < 0 || e.state.ActiveSeat >= len(e.state.Seats) {
+		return
+	}
+	s := &e.state.Seats[e.state.ActiveSeat]
+
+	e.state.PlayerAddr = s.PlayerAddr
+	e.state.TokenAddr = s.TokenAddr
+	e.state.BetAmount = s.BetAmount
+	e.state.Wagered = s.Wagered
+	e.state.Hands = copyHands(s.Hands)
+	e.state.ActiveHand = s.ActiveHand
+	e.state.SideBets = append([]SideBetResult{}, s.SideBets...)
+	e.state.InsuranceAmount = s.InsuranceAmount
+	e.state.InsuranceOutcome = s.InsuranceOutcome
+	e.state.InsurancePayout = s.InsurancePayout
+	e.state.EvenMoney = s.EvenMoney
+	e.state.Outcome = s.Outcome
+	e.state.Payout = s.Payout
+
+	e.state.PlayerCards = []Card{}
+	e.state.PlayerHand = []string{}
+	if s.ActiveHand < len(s.Hands) {
+		hand := s.Hands[s.ActiveHand]
+		e.state.PlayerCards = append(e.state.PlayerCards, hand.Cards...)
+		e.state.PlayerHand = append(e.state.PlayerHand, hand.Images...)
+	}
+}
+
+// copyHands deep-copies player hands
+func copyHands(hands []PlayerHand) []PlayerHand {
+	out := make([]PlayerHand, len(hands))
+	for i, hand := range hands {
+		hand.Cards = append([]Card(nil), hand.Cards...)
+		hand.Images = append([]string(nil), hand.Images...)
+		out[i] = hand
+	}
+	return out
+}
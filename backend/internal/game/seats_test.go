@@ -0,0 +1,282 @@
+package game
+
+import (
+	"testing"
+)
+
+// newTableEngine returns an engine with a player at each given seat, all betting 100,
+// dealt from a deck stacked in the order given
+func newTableEngine(t *testing.T, rules Rules, seats []int, cards ...Card) *GlobalEngine {
+	t.Helper()
+
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+	for _, n := range seats {
+		if err := e.JoinSeat(n, seatPlayer(n)); err != nil {
+			t.Fatalf("JoinSeat(%d): %v", n, err)
+		}
+	}
+	if err := e.OpenBetting(1); err != nil {
+		t.Fatalf("OpenBetting: %v", err)
+	}
+	for _, n := range seats {
+		if err := e.PlaceBet(n, seatPlayer(n), "0xtoken", "100"); err != nil {
+			t.Fatalf("PlaceBet(%d): %v", n, err)
+		}
+	}
+	if err := e.CloseBetting(); err != nil {
+		t.Fatalf("CloseBetting: %v", err)
+	}
+
+	e.state.Deck = &Deck{Cards: cards}
+	e.state.DeckInitialized = true
+	e.state.ShoeID = 1
+	e.state.TotalCards = len(cards)
+	e.state.ReshuffleAt = len(cards)
+	if err := e.dealInitialCards(); err != nil {
+		t.Fatalf("dealInitialCards: %v", err)
+	}
+	return e
+}
+
+func seatPlayer(n int) string {
+	return "0xplayer" + string(rune('0'+n))
+}
+
+func TestJoinAndLeaveSeat(t *testing.T) {
+	e := &GlobalEngine{state: newDefaultState(), rules: DefaultRules()}
+
+	if err := e.JoinSeat(2, "0xa"); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+	if err := e.JoinSeat(2, "0xb"); err == nil {
+		t.Fatal("expected error joining a taken seat")
+	}
+	if err := e.JoinSeat(MaxSeats, "0xb"); err == nil {
+		t.Fatal("expected error joining a seat past the table")
+	}
+	if err := e.LeaveSeat(2, "0xb"); err == nil {
+		t.Fatal("expected error leaving another player's seat")
+	}
+	if err := e.LeaveSeat(2, "0xa"); err != nil {
+		t.Fatalf("LeaveSeat: %v", err)
+	}
+	if e.GetState().Seats[2].PlayerAddr != "" {
+		t.Fatal("seat 2 still held after leaving")
+	}
+}
+
+func TestMidShoeEntry(t *testing.T) {
+	e := newTableEngine(t, DefaultRules(), []int{0},
+		card("10", "H"), card("10", "C"), card("8", "S"), card("7", "D"),
+	)
+	playOut(t, e)
+
+	if err := e.JoinSeat(1, "0xlate"); err == nil {
+		t.Fatal("expected mid-shoe entry to be refused")
+	}
+
+	rules := DefaultRules()
+	rules.MidShoeEntry = true
+	e.rules = rules
+	if err := e.JoinSeat(1, "0xlate"); err != nil {
+		t.Fatalf("JoinSeat with mid-shoe entry allowed: %v", err)
+	}
+}
+
+func TestBettingWindow(t *testing.T) {
+	e := &GlobalEngine{state: newDefaultState(), rules: DefaultRules()}
+
+	if err := e.OpenBetting(1); err == nil {
+		t.Fatal("expected error opening betting at an empty table")
+	}
+
+	for _, n := range []int{0, 3} {
+		if err := e.JoinSeat(n, seatPlayer(n)); err != nil {
+			t.Fatalf("JoinSeat(%d): %v", n, err)
+		}
+	}
+	if err := e.PlaceBet(0, seatPlayer(0), "0xtoken", "100"); err == nil {
+		t.Fatal("expected error betting before the window opens")
+	}
+	if err := e.OpenBetting(1); err != nil {
+		t.Fatalf("OpenBetting: %v", err)
+	}
+	if err := e.CloseBetting(); err == nil {
+		t.Fatal("expected error closing betting with no bets")
+	}
+
+	if err := e.PlaceBet(3, seatPlayer(0), "0xtoken", "100"); err == nil {
+		t.Fatal("expected error betting on another player's seat")
+	}
+	if err := e.PlaceBet(3, seatPlayer(3), "0xtoken", "0"); err == nil {
+		t.Fatal("expected error for a zero bet")
+	}
+	if err := e.PlaceBet(3, seatPlayer(3), "0xtoken", "250"); err != nil {
+		t.Fatalf("PlaceBet: %v", err)
+	}
+	if err := e.CloseBetting(); err != nil {
+		t.Fatalf("CloseBetting: %v", err)
+	}
+
+	// Only the seat that bet is dealt in
+	state := e.GetState()
+	if state.Phase != PhaseShuffling || state.ActiveSeat != 3 {
+		t.Fatalf("phase=%s active seat=%d, want SHUFFLING with seat 3 first", state.Phase, state.ActiveSeat)
+	}
+	if state.Seats[0].InRound || !state.Seats[3].InRound {
+		t.Fatal("want only seat 3 in the round")
+	}
+	if state.BetAmount != "250" || state.PlayerAddr != seatPlayer(3) {
+		t.Fatalf("active seat view = %s/%s, want seat 3's bet", state.PlayerAddr, state.BetAmount)
+	}
+}
+
+func TestDealOrderAcrossSeats(t *testing.T) {
+	e := newTableEngine(t, DefaultRules(), []int{1, 4},
+		card("2", "H"), card("3", "H"), card("10", "C"), // first pass: seat 1, seat 4, dealer
+		card("4", "H"), card("5", "H"), card("7", "D"), // second pass
+	)
+
+	state := e.GetState()
+	if got := state.Seats[1].Hands[0].Cards; got[0] != card("2", "H") || got[1] != card("4", "H") {
+		t.Fatalf("seat 1 cards = %v, want 2-H 4-H", got)
+	}
+	if got := state.Seats[4].Hands[0].Cards; got[0] != card("3", "H") || got[1] != card("5", "H") {
+		t.Fatalf("seat 4 cards = %v, want 3-H 5-H", got)
+	}
+	if got := state.DealerCards; got[0] != card("10", "C") || got[1] != card("7", "D") {
+		t.Fatalf("dealer cards = %v, want 10-C 7-D", got)
+	}
+	if state.Phase != PhasePlayerTurn || state.ActiveSeat != 1 {
+		t.Fatalf("phase=%s active seat=%d, want seat 1 to act first", state.Phase, state.ActiveSeat)
+	}
+}
+
+func TestSeatTurnRouting(t *testing.T) {
+	e := newTableEngine(t, DefaultRules(), []int{0, 2},
+		card("10", "H"), card("9", "H"), card("10", "C"),
+		card("8", "H"), card("9", "S"), card("7", "D"),
+	)
+
+	if err := e.SeatStand(2, seatPlayer(2)); err == nil {
+		t.Fatal("expected error acting out of turn")
+	}
+	if err := e.SeatStand(5, seatPlayer(5)); err == nil {
+		t.Fatal("expected error acting for a seat not in the round")
+	}
+	if err := e.SeatStand(0, seatPlayer(2)); err == nil {
+		t.Fatal("expected error acting on another player's seat")
+	}
+	if err := e.SeatHit(0, ""); err == nil {
+		t.Fatal("expected error acting without a player")
+	}
+
+	if err := e.SeatStand(0, seatPlayer(0)); err != nil {
+		t.Fatalf("SeatStand(0): %v", err)
+	}
+	state := e.GetState()
+	if state.Phase != PhasePlayerTurn || state.ActiveSeat != 2 {
+		t.Fatalf("phase=%s active seat=%d, want seat 2's turn", state.Phase, state.ActiveSeat)
+	}
+	if state.PlayerAddr != seatPlayer(2) {
+		t.Fatalf("active player = %s, want %s", state.PlayerAddr, seatPlayer(2))
+	}
+
+	if err := e.SeatStand(2, seatPlayer(2)); err != nil {
+		t.Fatalf("SeatStand(2): %v", err)
+	}
+	if phase := e.GetState().Phase; phase != PhaseDealerTurn {
+		t.Fatalf("phase = %s, want DEALER_TURN after the last seat", phase)
+	}
+}
+
+func TestSeatsResolveIndependently(t *testing.T) {
+	e := newTableEngine(t, DefaultRules(), []int{0, 1, 2},
+		card("10", "H"), card("10", "S"), card("9", "H"), card("10", "C"), // seat 0 20, seat 1 17, seat 2 18
+		card("10", "D"), card("7", "S"), card("9", "C"), card("8", "D"), // dealer 18
+	)
+	playOut(t, e)
+
+	state := e.GetState()
+	want := []struct{ outcome, payout string }{
+		{"win", "100"},
+		{"lose", "-100"},
+		{"push", "0"},
+	}
+	for n, w := range want {
+		if s := state.Seats[n]; s.Outcome != w.outcome || s.Payout != w.payout {
+			t.Errorf("seat %d = %s/%s, want %s/%s", n, s.Outcome, s.Payout, w.outcome, w.payout)
+		}
+	}
+	if state.FeeNickelRef != "0.15" {
+		t.Errorf("fee = %s, want 0.15 on 300 wagered", state.FeeNickelRef)
+	}
+}
+
+func TestInsuranceAcrossSeats(t *testing.T) {
+	e := newTableEngine(t, DefaultRules(), []int{0, 1},
+		card("10", "H"), card("9", "H"), card("A", "C"),
+		card("8", "H"), card("9", "S"), card("10", "D"), // dealer blackjack
+	)
+
+	if phase := e.GetState().Phase; phase != PhaseInsuranceOffer {
+		t.Fatalf("phase = %s, want INSURANCE_OFFER", phase)
+	}
+
+	if err := e.SeatInsurance(1, seatPlayer(0), true, "50"); err == nil {
+		t.Fatal("expected error insuring another player's seat")
+	}
+
+	// Either seat may decide first; the peek waits for both
+	if err := e.SeatInsurance(1, seatPlayer(1), true, "50"); err != nil {
+		t.Fatalf("SeatInsurance(1): %v", err)
+	}
+	if err := e.SeatInsurance(1, seatPlayer(1), false, ""); err == nil {
+		t.Fatal("expected error deciding insurance twice")
+	}
+	if phase := e.GetState().Phase; phase != PhaseInsuranceOffer {
+		t.Fatalf("phase = %s, want INSURANCE_OFFER until every seat decides", phase)
+	}
+	if err := e.SeatInsurance(0, seatPlayer(0), false, ""); err != nil {
+		t.Fatalf("SeatInsurance(0): %v", err)
+	}
+
+	playOut(t, e)
+
+	state := e.GetState()
+	if s := state.Seats[1]; s.InsuranceOutcome != "win" || s.InsurancePayout != "100" || s.Outcome != "lose" {
+		t.Errorf("seat 1 = insurance %s/%s, hand %s, want insurance win 100 and hand lost", s.InsuranceOutcome, s.InsurancePayout, s.Outcome)
+	}
+	if s := state.Seats[0]; s.InsuranceOutcome != "declined" || s.Outcome != "lose" {
+		t.Errorf("seat 0 = insurance %s, hand %s, want declined and lost", s.InsuranceOutcome, s.Outcome)
+	}
+}
+
+func TestStartHandReleasesHeadsUpSeat(t *testing.T) {
+	e := &GlobalEngine{state: newDefaultState(), rules: DefaultRules()}
+	for _, amount := range []string{"0", "-100"} {
+		if err := e.StartHand(1, "0xsolo", "0xtoken", amount, 0); err == nil {
+			t.Fatalf("expected error starting a hand with a bet of %s", amount)
+		}
+	}
+	if phase := e.GetState().Phase; phase != PhaseWaitingForDeal {
+		t.Fatalf("phase = %s after refused bets, want WAITING_FOR_DEAL", phase)
+	}
+	if err := e.StartHand(1, "0xsolo", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if s := e.GetState().Seats[0]; s.PlayerAddr != "0xsolo" || !s.HeadsUp {
+		t.Fatalf("seat 0 = %+v, want heads-up seat for 0xsolo", s)
+	}
+
+	e.state.Phase = PhaseComplete
+	if err := e.JoinSeat(3, "0xother"); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+	if err := e.OpenBetting(2); err != nil {
+		t.Fatalf("OpenBetting: %v", err)
+	}
+	if s := e.GetState().Seats[0]; s.PlayerAddr != "" {
+		t.Fatalf("heads-up seat still held by %s after betting opened", s.PlayerAddr)
+	}
+}
@@ -0,0 +1,133 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestNextIntRejectsBiasedValues(t *testing.T) {
+	// 0xFFFFFFFF lies in the incomplete final block for max=3 and must be redrawn
+	state := make([]byte, 32)
+	binary.BigEndian.PutUint32(state[0:4], 0xFFFFFFFF)
+	binary.BigEndian.PutUint32(state[4:8], 5)
+
+	legacy := &seedRNG{state: append([]byte(nil), state...)}
+	if got := legacy.nextIntModulo(3); got != 0 {
+		t.Fatalf("v1 draw = %d, want 0 (0xFFFFFFFF %% 3)", got)
+	}
+
+	rng := &seedRNG{state: append([]byte(nil), state...)}
+	if got := rng.NextInt(3); got != 2 {
+		t.Fatalf("v2 draw = %d, want 2 (rejected 0xFFFFFFFF, then 5 %% 3)", got)
+	}
+	if rng.pos != 8 {
+		t.Fatalf("rng consumed %d bytes, want 8", rng.pos)
+	}
+}
+
+func TestShuffleV1Replayable(t *testing.T) {
+	seed := []byte("published hand seed")
+
+	// Reference copy of the original algorithm; V1 must keep producing exactly this order
+	want := NewDeck(2).Cards
+	state := sha256.Sum256(seed)
+	buf, pos := state[:], 0
+	for i := len(want) - 1; i > 0; i-- {
+		if pos+4 > len(buf) {
+			next := sha256.Sum256(buf)
+			buf, pos = next[:], 0
+		}
+		j := int(binary.BigEndian.Uint32(buf[pos:pos+4])) % (i + 1)
+		pos += 4
+		want[i], want[j] = want[j], want[i]
+	}
+
+	deck := NewDeck(2)
+	if err := deck.ShuffleWithVersion(seed, ShuffleV1); err != nil {
+		t.Fatalf("ShuffleWithVersion: %v", err)
+	}
+	if deck.Version() != ShuffleV1 {
+		t.Fatalf("version = %s, want v1", deck.Version())
+	}
+	for i := range want {
+		if deck.Cards[i] != want[i] {
+			t.Fatalf("v1 position %d = %+v, want %+v", i, deck.Cards[i], want[i])
+		}
+	}
+}
+
+func TestShuffleVersions(t *testing.T) {
+	deck := NewDeck(1)
+	deck.Shuffle([]byte("seed"))
+	if deck.Version() != CurrentShuffleVersion {
+		t.Fatalf("Shuffle version = %s, want %s", deck.Version(), CurrentShuffleVersion)
+	}
+
+	replay := NewDeck(1)
+	if err := replay.ShuffleWithVersion([]byte("seed"), CurrentShuffleVersion); err != nil {
+		t.Fatalf("ShuffleWithVersion: %v", err)
+	}
+	for i := range deck.Cards {
+		if deck.Cards[i] != replay.Cards[i] {
+			t.Fatalf("replay differs at position %d", i)
+		}
+	}
+
+	for _, v := range []ShuffleVersion{0, 3} {
+		if err := NewDeck(1).ShuffleWithVersion([]byte("seed"), v); err == nil {
+			t.Fatalf("version %d: expected error", v)
+		}
+	}
+
+	result, err := ResolveHand(1, "0xplayer", "0xtoken", "100", []byte("seed"))
+	if err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+	if result.ShuffleVersion != CurrentShuffleVersion {
+		t.Fatalf("hand result version = %s, want %s", result.ShuffleVersion, CurrentShuffleVersion)
+	}
+}
+
+// TestShufflePositionUniformity checks every card is equally likely at every position
+// Chi-square over the full card × position table, (n-1)² degrees of freedom
+func TestShufflePositionUniformity(t *testing.T) {
+	const (
+		deckSize   = 52
+		iterations = 20000
+	)
+
+	index := make(map[Card]int, deckSize)
+	for i, c := range NewDeck(1).Cards {
+		index[c] = i
+	}
+
+	var counts [deckSize][deckSize]int
+	seed := make([]byte, 8)
+	for n := 0; n < iterations; n++ {
+		binary.BigEndian.PutUint64(seed, uint64(n))
+		deck := NewDeck(1)
+		deck.Shuffle(seed)
+		for pos, c := range deck.Cards {
+			counts[index[c]][pos]++
+		}
+	}
+
+	expected := float64(iterations) / deckSize
+	chiSquare := 0.0
+	for _, row := range counts {
+		for _, count := range row {
+			diff := float64(count) - expected
+			chiSquare += diff * diff / expected
+		}
+	}
+
+	// Normal approximation: reject beyond ~4.5 standard deviations above the mean
+	df := float64((deckSize - 1) * (deckSize - 1))
+	critical := df + 4.5*math.Sqrt(2*df)
+	t.Logf("Chi-square statistic: %.1f (df=%.0f, critical %.1f)", chiSquare, df, critical)
+	if chiSquare > critical {
+		t.Fatalf("chi-square %.1f exceeds %.1f: shuffle shows position bias", chiSquare, critical)
+	}
+}
@@ -0,0 +1,282 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// SideBetKind identifies a side bet
+type SideBetKind string
+
+const (
+	SideBetPerfectPairs SideBetKind = "perfect_pairs" // Player's first two cards form a pair
+	SideBet21Plus3      SideBetKind = "21+3"          // Player's first two cards and the dealer upcard form a poker hand
+	SideBetLuckyLadies  SideBetKind = "lucky_ladies"  // Player's first two cards total 20
+)
+
+// SideBet is a wager settled on the initial cards, independent of the main hand
+// Evaluate returns the winning paytable line, or "" if the side bet loses
+type SideBet interface {
+	Kind() SideBetKind
+	Paytable() Paytable
+	Evaluate(playerCards, dealerCards []Card) string
+}
+
+// PaytableLine is one winning outcome of a side bet and what it pays per unit bet
+type PaytableLine struct {
+	Hand string `json:"hand"`
+	Pays int64  `json:"pays"` // Paid N:1
+}
+
+// Paytable lists a side bet's winning outcomes, best first
+type Paytable []PaytableLine
+
+// Pays returns what a paytable line pays, or 0 for a hand not on the table
+func (p Paytable) Pays(hand string) int64 {
+	for _, line := range p {
+		if line.Hand == hand {
+			return line.Pays
+		}
+	}
+	return 0
+}
+
+// SideBetWager is a side bet placed alongside the main bet
+type SideBetWager struct {
+	Kind   SideBetKind `json:"kind"`
+	Amount string      `json:"amount"` // In wei as string
+}
+
+// SideBetResult is a side bet and, once settled, its outcome
+// Payout is net like the main hand: the winning line times the bet, minus the bet on a loss
+type SideBetResult struct {
+	Kind    SideBetKind `json:"kind"`
+	Amount  string      `json:"amount"`  // In wei as string
+	Outcome string      `json:"outcome"` // win, lose; empty until settled
+	Hand    string      `json:"hand"`    // Winning paytable line
+	Pays    int64       `json:"pays"`    // Paid N:1
+	Payout  string      `json:"payout"`  // In wei as string
+}
+
+var sideBets = map[SideBetKind]SideBet{}
+
+// RegisterSideBet makes a side bet available to tables
+// Registering a kind again replaces it
+func RegisterSideBet(bet SideBet) {
+	sideBets[bet.Kind()] = bet
+}
+
+func init() {
+	RegisterSideBet(perfectPairs{})
+	RegisterSideBet(twentyOnePlus3{})
+	RegisterSideBet(luckyLadies{})
+}
+
+// LookupSideBet returns a registered side bet
+func LookupSideBet(kind SideBetKind) (SideBet, error) {
+	bet, ok := sideBets[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown side bet %q", kind)
+	}
+	return bet, nil
+}
+
+// SideBetKinds lists the registered side bets
+func SideBetKinds() []SideBetKind {
+	kinds := make([]SideBetKind, 0, len(sideBets))
+	for kind := range sideBets {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+	return kinds
+}
+
+// ValidateSideBets checks that every wager is a registered side bet with a positive amount,
+// at most one of each kind
+func ValidateSideBets(wagers []SideBetWager) error {
+	seen := make(map[SideBetKind]bool, len(wagers))
+	for _, wager := range wagers {
+		if _, err := LookupSideBet(wager.Kind); err != nil {
+			return err
+		}
+		if seen[wager.Kind] {
+			return fmt.Errorf("side bet %s placed twice", wager.Kind)
+		}
+		seen[wager.Kind] = true
+
+		amount, err := decimal.NewFromString(wager.Amount)
+		if err != nil {
+			return fmt.Errorf("invalid %s amount: %w", wager.Kind, err)
+		}
+		if !amount.IsPositive() {
+			return fmt.Errorf("%s bet must be positive, got %s", wager.Kind, wager.Amount)
+		}
+	}
+	return nil
+}
+
+// SettleSideBet evaluates a side bet against the player's first two cards and the dealer's hand
+func SettleSideBet(wager SideBetWager, playerCards, dealerCards []Card) (SideBetResult, error) {
+	bet, err := LookupSideBet(wager.Kind)
+	if err != nil {
+		return SideBetResult{}, err
+	}
+
+	amount, err := decimal.NewFromString(wager.Amount)
+	if err != nil {
+		return SideBetResult{}, fmt.Errorf("invalid %s amount: %w", wager.Kind, err)
+	}
+
+	if len(playerCards) < 2 || len(dealerCards) < 1 {
+		return SideBetResult{}, fmt.Errorf("%s needs the player's first two cards and the dealer upcard", wager.Kind)
+	}
+
+	result := SideBetResult{
+		Kind:    wager.Kind,
+		Amount:  amount.String(),
+		Outcome: "lose",
+		Payout:  amount.Neg().String(),
+	}
+
+	hand := bet.Evaluate(playerCards[:2], dealerCards)
+	if pays := bet.Paytable().Pays(hand); pays > 0 {
+		result.Outcome = "win"
+		result.Hand = hand
+		result.Pays = pays
+		result.Payout = amount.Mul(decimal.NewFromInt(pays)).String()
+	}
+	return result, nil
+}
+
+// SettleSideBets settles every wager in order
+func SettleSideBets(wagers []SideBetWager, playerCards, dealerCards []Card) ([]SideBetResult, error) {
+	results := make([]SideBetResult, 0, len(wagers))
+	for _, wager := range wagers {
+		result, err := SettleSideBet(wager, playerCards, dealerCards)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// perfectPairs pays when the player's first two cards are the same rank
+type perfectPairs struct{}
+
+func (perfectPairs) Kind() SideBetKind { return SideBetPerfectPairs }
+
+func (perfectPairs) Paytable() Paytable {
+	return Paytable{
+		{Hand: "perfect_pair", Pays: 25}, // Same rank and suit
+		{Hand: "colored_pair", Pays: 12}, // Same rank and color
+		{Hand: "mixed_pair", Pays: 6},    // Same rank, red and black
+	}
+}
+
+func (perfectPairs) Evaluate(playerCards, dealerCards []Card) string {
+	a, b := playerCards[0], playerCards[1]
+	switch {
+	case a.Rank != b.Rank:
+		return ""
+	case a.Suit == b.Suit:
+		return "perfect_pair"
+	case a.Suit.red() == b.Suit.red():
+		return "colored_pair"
+	default:
+		return "mixed_pair"
+	}
+}
+
+// twentyOnePlus3 pays on the three-card poker hand of the player's first two cards and the dealer upcard
+type twentyOnePlus3 struct{}
+
+func (twentyOnePlus3) Kind() SideBetKind { return SideBet21Plus3 }
+
+func (twentyOnePlus3) Paytable() Paytable {
+	return Paytable{
+		{Hand: "suited_trips", Pays: 100},
+		{Hand: "straight_flush", Pays: 40},
+		{Hand: "three_of_a_kind", Pays: 30},
+		{Hand: "straight", Pays: 10},
+		{Hand: "flush", Pays: 5},
+	}
+}
+
+func (twentyOnePlus3) Evaluate(playerCards, dealerCards []Card) string {
+	cards := [3]Card{playerCards[0], playerCards[1], dealerCards[0]}
+
+	flush := cards[0].Suit == cards[1].Suit && cards[1].Suit == cards[2].Suit
+	trips := cards[0].Rank == cards[1].Rank && cards[1].Rank == cards[2].Rank
+
+	switch {
+	case trips && flush:
+		return "suited_trips"
+	case trips:
+		return "three_of_a_kind"
+	}
+
+	straight := isStraight(cards[0].Rank, cards[1].Rank, cards[2].Rank)
+	switch {
+	case straight && flush:
+		return "straight_flush"
+	case straight:
+		return "straight"
+	case flush:
+		return "flush"
+	default:
+		return ""
+	}
+}
+
+// isStraight reports whether three ranks are consecutive, aces playing high or low
+func isStraight(a, b, c Rank) bool {
+	ranks := []int{int(a), int(b), int(c)}
+	sort.Ints(ranks)
+	if ranks[0] == ranks[1] || ranks[1] == ranks[2] {
+		return false
+	}
+	if ranks[2]-ranks[0] == 2 {
+		return true
+	}
+	// Q-K-A
+	return ranks[0] == int(Ace) && ranks[1] == int(Queen) && ranks[2] == int(King)
+}
+
+// luckyLadies pays when the player's first two cards total 20, most for a pair of queens of hearts
+type luckyLadies struct{}
+
+func (luckyLadies) Kind() SideBetKind { return SideBetLuckyLadies }
+
+func (luckyLadies) Paytable() Paytable {
+	return Paytable{
+		{Hand: "queen_hearts_pair_dealer_blackjack", Pays: 1000},
+		{Hand: "queen_hearts_pair", Pays: 125},
+		{Hand: "matched_20", Pays: 19}, // Same rank and suit
+		{Hand: "suited_20", Pays: 10},
+		{Hand: "any_20", Pays: 4},
+	}
+}
+
+func (luckyLadies) Evaluate(playerCards, dealerCards []Card) string {
+	a, b := playerCards[0], playerCards[1]
+	if total, _ := CalculateHandValue(playerCards); total != 20 {
+		return ""
+	}
+
+	queenHearts := Card{Suit: Hearts, Rank: Queen}
+	switch {
+	case a == queenHearts && b == queenHearts && len(dealerCards) >= 2 && IsBlackjack(dealerCards[:2]):
+		return "queen_hearts_pair_dealer_blackjack"
+	case a == queenHearts && b == queenHearts:
+		return "queen_hearts_pair"
+	case a == b:
+		return "matched_20"
+	case a.Suit == b.Suit:
+		return "suited_20"
+	default:
+		return "any_20"
+	}
+}
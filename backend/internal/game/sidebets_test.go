@@ -0,0 +1,147 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestSideBetEvaluators(t *testing.T) {
+	tests := []struct {
+		kind   SideBetKind
+		player []Card
+		dealer []Card
+		want   string
+	}{
+		{SideBetPerfectPairs, []Card{card("8", "H"), card("8", "H")}, []Card{card("2", "C")}, "perfect_pair"},
+		{SideBetPerfectPairs, []Card{card("8", "H"), card("8", "D")}, []Card{card("2", "C")}, "colored_pair"},
+		{SideBetPerfectPairs, []Card{card("8", "H"), card("8", "S")}, []Card{card("2", "C")}, "mixed_pair"},
+		{SideBetPerfectPairs, []Card{card("8", "H"), card("9", "H")}, []Card{card("2", "C")}, ""},
+		{SideBetPerfectPairs, []Card{card("K", "H"), card("Q", "H")}, []Card{card("2", "C")}, ""}, // Ten-values are not a pair
+
+		{SideBet21Plus3, []Card{card("7", "S"), card("7", "S")}, []Card{card("7", "S")}, "suited_trips"},
+		{SideBet21Plus3, []Card{card("9", "D"), card("J", "D")}, []Card{card("10", "D")}, "straight_flush"},
+		{SideBet21Plus3, []Card{card("7", "S"), card("7", "H")}, []Card{card("7", "C")}, "three_of_a_kind"},
+		{SideBet21Plus3, []Card{card("A", "S"), card("K", "H")}, []Card{card("Q", "C")}, "straight"},
+		{SideBet21Plus3, []Card{card("A", "S"), card("2", "H")}, []Card{card("3", "C")}, "straight"},
+		{SideBet21Plus3, []Card{card("K", "S"), card("A", "H")}, []Card{card("2", "C")}, ""}, // No wrap-around
+		{SideBet21Plus3, []Card{card("2", "C"), card("9", "C")}, []Card{card("K", "C")}, "flush"},
+		{SideBet21Plus3, []Card{card("2", "C"), card("9", "C")}, []Card{card("K", "H")}, ""},
+
+		{SideBetLuckyLadies, []Card{card("Q", "H"), card("Q", "H")}, []Card{card("A", "S"), card("K", "S")}, "queen_hearts_pair_dealer_blackjack"},
+		{SideBetLuckyLadies, []Card{card("Q", "H"), card("Q", "H")}, []Card{card("A", "S"), card("7", "S")}, "queen_hearts_pair"},
+		{SideBetLuckyLadies, []Card{card("J", "S"), card("J", "S")}, []Card{card("2", "C")}, "matched_20"},
+		{SideBetLuckyLadies, []Card{card("J", "S"), card("10", "S")}, []Card{card("2", "C")}, "suited_20"},
+		{SideBetLuckyLadies, []Card{card("A", "S"), card("9", "H")}, []Card{card("2", "C")}, "any_20"},
+		{SideBetLuckyLadies, []Card{card("A", "S"), card("A", "H")}, []Card{card("2", "C")}, ""},
+	}
+	for _, tt := range tests {
+		bet, err := LookupSideBet(tt.kind)
+		if err != nil {
+			t.Fatalf("LookupSideBet(%s): %v", tt.kind, err)
+		}
+		if got := bet.Evaluate(tt.player, tt.dealer); got != tt.want {
+			t.Errorf("%s on %v vs %v = %q, want %q", tt.kind, tt.player, tt.dealer, got, tt.want)
+		}
+	}
+}
+
+func TestSettleSideBet(t *testing.T) {
+	win, err := SettleSideBet(SideBetWager{Kind: SideBetPerfectPairs, Amount: "10"},
+		[]Card{card("8", "H"), card("8", "D")}, []Card{card("2", "C"), card("5", "C")})
+	if err != nil {
+		t.Fatalf("SettleSideBet: %v", err)
+	}
+	if win.Outcome != "win" || win.Hand != "colored_pair" || win.Pays != 12 || win.Payout != "120" {
+		t.Fatalf("colored pair = %+v, want win paying 12:1 (120)", win)
+	}
+
+	lose, err := SettleSideBet(SideBetWager{Kind: SideBet21Plus3, Amount: "10"},
+		[]Card{card("8", "H"), card("2", "D")}, []Card{card("K", "C")})
+	if err != nil {
+		t.Fatalf("SettleSideBet: %v", err)
+	}
+	if lose.Outcome != "lose" || lose.Payout != "-10" {
+		t.Fatalf("losing 21+3 = %+v, want lose losing 10", lose)
+	}
+}
+
+func TestValidateSideBets(t *testing.T) {
+	tests := []struct {
+		name    string
+		wagers  []SideBetWager
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"all three", []SideBetWager{{SideBetPerfectPairs, "1"}, {SideBet21Plus3, "1"}, {SideBetLuckyLadies, "1"}}, false},
+		{"unknown", []SideBetWager{{"royal_match", "1"}}, true},
+		{"twice", []SideBetWager{{SideBetPerfectPairs, "1"}, {SideBetPerfectPairs, "2"}}, true},
+		{"zero", []SideBetWager{{SideBetPerfectPairs, "0"}}, true},
+		{"not a number", []SideBetWager{{SideBetPerfectPairs, "ten"}}, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateSideBets(tt.wagers); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEngineSettlesSideBets(t *testing.T) {
+	e := &GlobalEngine{state: newDefaultState(), rules: DefaultRules()}
+	if err := e.PlaceSideBets([]SideBetWager{{SideBetPerfectPairs, "10"}}); err == nil {
+		t.Fatal("expected error placing side bets before a hand starts")
+	}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if err := e.PlaceSideBets([]SideBetWager{{SideBetPerfectPairs, "10"}, {SideBet21Plus3, "5"}}); err != nil {
+		t.Fatalf("PlaceSideBets: %v", err)
+	}
+
+	// Player splits 8-8 against a dealer 8 up; the side bets still see the original pair
+	e.state.Deck = &Deck{Cards: []Card{
+		card("8", "H"), card("8", "C"), card("8", "D"), card("10", "C"), // player 8-8, dealer 8 up, 10 hole
+		card("3", "S"), card("10", "H"),
+	}}
+	e.state.DeckInitialized = true
+	e.state.ShoeID = 1
+	e.state.TotalCards = 6
+	e.state.ReshuffleAt = 6
+	if err := e.dealInitialCards(); err != nil {
+		t.Fatalf("dealInitialCards: %v", err)
+	}
+	if err := e.PlayerSplit(); err != nil {
+		t.Fatalf("PlayerSplit: %v", err)
+	}
+	playOut(t, e)
+
+	state := e.GetState()
+	if len(state.SideBets) != 2 {
+		t.Fatalf("side bets = %v, want 2", state.SideBets)
+	}
+	if pp := state.SideBets[0]; pp.Outcome != "win" || pp.Hand != "colored_pair" || pp.Payout != "120" {
+		t.Errorf("perfect pairs = %+v, want colored pair paying 120", pp)
+	}
+	if trips := state.SideBets[1]; trips.Outcome != "win" || trips.Hand != "three_of_a_kind" || trips.Payout != "150" {
+		t.Errorf("21+3 = %+v, want three of a kind paying 150", trips)
+	}
+
+	// Side bet winnings are reported apart from the main hand
+	if state.Outcome != "lose" || state.Payout != "-100" {
+		t.Errorf("main hand = %s/%s, want lose/-100 (split 11 loses, 18 pushes dealer 18)", state.Outcome, state.Payout)
+	}
+}
+
+func TestResolveHandWithSideBets(t *testing.T) {
+	result, err := ResolveHandWithRules(DefaultRules(), 1, "0xplayer", "0xtoken", "100", []byte("seed"),
+		SideBetWager{Kind: SideBetLuckyLadies, Amount: "10"})
+	if err != nil {
+		t.Fatalf("ResolveHandWithRules: %v", err)
+	}
+	if len(result.SideBets) != 1 || result.SideBets[0].Kind != SideBetLuckyLadies || result.SideBets[0].Outcome == "" {
+		t.Fatalf("side bets = %+v, want a settled lucky ladies bet", result.SideBets)
+	}
+
+	if _, err := ResolveHandWithRules(DefaultRules(), 1, "0xplayer", "0xtoken", "100", []byte("seed"),
+		SideBetWager{Kind: "royal_match", Amount: "10"}); err == nil {
+		t.Fatal("expected error for an unknown side bet")
+	}
+}
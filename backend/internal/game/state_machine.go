@@ -1,11 +1,13 @@
 package game
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"github.com/DanDo385/blackjack/backend/internal/random"
 	"github.com/shopspring/decimal"
 )
 
@@ -16,13 +18,20 @@ const (
 	// PhaseWaitingForDeal - Initial state, waiting for player to click Deal
 	PhaseWaitingForDeal GamePhase = "WAITING_FOR_DEAL"
 
+	// PhaseBetting - Betting window, every seated player may place a bet
+	PhaseBetting GamePhase = "BETTING"
+
 	// PhaseShuffling - Deck is being created and shuffled
 	PhaseShuffling GamePhase = "SHUFFLING"
 
 	// PhaseDealing - Initial cards are being dealt
 	PhaseDealing GamePhase = "DEALING"
 
+	// PhaseInsuranceOffer - Dealer shows an Ace, player may insure (or take even money)
+	PhaseInsuranceOffer GamePhase = "INSURANCE_OFFER"
+
 	// PhasePlayerTurn - Player is making decisions (Hit, Stand, Double, Split)
+	// Seats play in order from ActiveSeat; after a split, each hand is played in turn
 	PhasePlayerTurn GamePhase = "PLAYER_TURN"
 
 	// PhaseDealerTurn - Dealer is playing according to rules
@@ -35,6 +44,46 @@ const (
 	PhaseComplete GamePhase = "COMPLETE"
 )
 
+// PlayerHand is one of the player's hands (a round has several after splitting)
+type PlayerHand struct {
+	Cards       []Card        `json:"cards"`
+	Images      []string      `json:"images"`            // Image paths
+	Bet         string        `json:"bet"`               // In wei as string
+	FreeBet     string        `json:"freeBet,omitempty"` // Part of the stake put up by the house (Free Bet); in wei
+	FromSplit   bool          `json:"fromSplit"`
+	SplitAces   bool          `json:"splitAces"`
+	Switched    bool          `json:"switched,omitempty"` // Second card swapped with the seat's other hand (Blackjack Switch)
+	Doubled     bool          `json:"doubled"`
+	Surrendered bool          `json:"surrendered"`
+	Done        bool          `json:"done"`             // Stood, busted or finished by rule
+	Outcome     string        `json:"outcome"`          // win, lose, push, surrender (set on resolution)
+	Reason      OutcomeReason `json:"reason,omitempty"` // Rule that settled the hand (set on resolution)
+	Payout      string        `json:"payout"`           // Won (positive) or lost (negative), in wei as string
+}
+
+// natural reports whether the hand is a blackjack: two cards to 21 as dealt, not split or switched into
+func (h PlayerHand) natural() bool {
+	return !h.FromSplit && !h.Switched && IsBlackjack(h.Cards)
+}
+
+// freeBet returns the part of the hand's stake the house put up
+func (h PlayerHand) freeBet() decimal.Decimal {
+	free, err := decimal.NewFromString(h.FreeBet)
+	if err != nil {
+		return decimal.Zero
+	}
+	return free
+}
+
+// stake returns the whole bet riding on the hand, free bets included
+func (h PlayerHand) stake() (decimal.Decimal, error) {
+	bet, err := decimal.NewFromString(h.Bet)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid bet amount: %w", err)
+	}
+	return bet.Add(h.freeBet()), nil
+}
+
 // EngineState represents the complete state of the game engine
 type EngineState struct {
 	// Phase tracking
@@ -42,97 +91,186 @@ type EngineState struct {
 	PhaseDetail string    `json:"phaseDetail"` // Human-readable phase description
 
 	// Game state
-	HandID      int64    `json:"handId"`
-	PlayerAddr  string   `json:"playerAddr"`
-	TokenAddr   string   `json:"tokenAddr"`
-	BetAmount   string   `json:"betAmount"` // In wei as string
-
-	// Deck state
-	Deck            *Deck  `json:"-"` // Not serialized
-	DeckInitialized bool   `json:"deckInitialized"`
-	CardsDealt      int    `json:"cardsDealt"`
-	TotalCards      int    `json:"totalCards"`
+	HandID int64 `json:"handId"`
+
+	// Seats (dealt and played in seat order)
+	Seats      []Seat `json:"seats"`
+	ActiveSeat int    `json:"activeSeat"` // Seat whose decision the round is waiting on
+
+	// Active seat's player and bet (single-player view of Seats[ActiveSeat])
+	PlayerAddr string `json:"playerAddr"`
+	TokenAddr  string `json:"tokenAddr"`
+	BetAmount  string `json:"betAmount"` // In wei as string, staked on each of the seat's hands
+	Wagered    string `json:"wagered"`   // In wei as string, the bet on every hand dealt
+
+	// Deck state (one shoe is dealt across hands until the cut card comes out)
+	Deck            *Deck          `json:"-"` // Not serialized
+	DeckInitialized bool           `json:"deckInitialized"`
+	ShoeID          int64          `json:"shoeId"`
+	CardsDealt      int            `json:"cardsDealt"`     // Dealt from the current shoe
+	CardsRemaining  int            `json:"cardsRemaining"` // Left in the current shoe
+	TotalCards      int            `json:"totalCards"`
+	ReshuffleAt     int            `json:"reshuffleAt"`    // Cut card position (cards dealt)
+	CutCardReached  bool           `json:"cutCardReached"` // Shoe is reshuffled before the next hand
+	ShuffleVersion  ShuffleVersion `json:"shuffleVersion"` // Shuffle algorithm of the current shoe
+	ShoeRoot        string         `json:"shoeRoot"`       // Merkle root of the current shoe, published before it is dealt
+	ShoeRoots       []string       `json:"shoeRoots"`      // Every root of the current shoe, oldest first: ShoeRoot and then one per mid-round refill
+
+	// Provably fair commitment the current shoe was shuffled under (nil for a VRF seed)
+	// The server seed behind it is revealed once the shoe is retired
+	Commitment *random.Commitment `json:"commitment,omitempty"`
 
 	// Hand state
 	DealerCards []Card   `json:"dealerCards"`
-	PlayerCards []Card   `json:"playerCards"`
-	DealerHand  []string `json:"dealerHand"` // Image paths
-	PlayerHand  []string `json:"playerHand"` // Image paths
-
-	// Outcome
-	Outcome        string `json:"outcome"`        // win, lose, push
-	Payout         string `json:"payout"`         // In wei as string
-	FeeLink        string `json:"feeLink"`        // In wei as string
-	FeeNickelRef   string `json:"feeNickelRef"`   // In wei as string
-
-	// Counting metrics (for display)
-	TrueCount      float64 `json:"trueCount"`
-	ShoePct        int     `json:"shoePct"`
-	RunningCount   int     `json:"runningCount"`
+	PlayerCards []Card   `json:"playerCards"` // Active seat's active hand cards
+	DealerHand  []string `json:"dealerHand"`  // Image paths
+	PlayerHand  []string `json:"playerHand"`  // Active seat's active hand image paths
+
+	// Inclusion proofs of the round's face-up cards against the shoe's Merkle root
+	// The hole card's proof is added once it is revealed
+	CardProofs []CardProof `json:"cardProofs"`
+
+	// Active seat's hands (several after splitting)
+	Hands      []PlayerHand `json:"hands"`
+	ActiveHand int          `json:"activeHand"`
+
+	// HoleCardRevealed is set once the dealer's second card is shown (and counted)
+	HoleCardRevealed bool `json:"holeCardRevealed"`
+
+	// DealerPeeked is false while early surrender is still on offer against a ten-value upcard
+	DealerPeeked bool `json:"dealerPeeked"`
+
+	// Active seat's side bets, settled with the hand
+	SideBets []SideBetResult `json:"sideBets"`
+
+	// Active seat's insurance side-wager (settled 2:1 when the dealer peeks, independent of the main hand)
+	InsuranceAmount  string `json:"insuranceAmount"`  // In wei as string
+	InsuranceOutcome string `json:"insuranceOutcome"` // declined, win, lose, even_money
+	InsurancePayout  string `json:"insurancePayout"`  // Won (positive) or lost (negative), in wei as string
+	EvenMoney        bool   `json:"evenMoney"`        // Player blackjack paid 1:1 instead of insuring
+
+	// Deadline of the decision the round is waiting on (nil when no timer runs)
+	// Once it passes the seat stands, or declines insurance, and the round plays out
+	DecisionDeadline    *time.Time `json:"decisionDeadline,omitempty"`
+	DecisionRemainingMs int64      `json:"decisionRemainingMs"`
+
+	// Outcome (active seat) and fees (whole table)
+	Outcome      string `json:"outcome"`      // win, lose, push
+	Payout       string `json:"payout"`       // Won (positive) or lost (negative), in wei as string
+	FeeLink      string `json:"feeLink"`      // In wei as string
+	FeeNickelRef string `json:"feeNickelRef"` // In wei as string
+
+	// Counting metrics (for display), updated as cards become visible
+	// RunningCount/TrueCount mirror the selected CountingSystem, Counts holds every system
+	CountingSystem CountingSystem                `json:"countingSystem"`
+	Counts         map[CountingSystem]CountState `json:"counts"`
+	TrueCount      float64                       `json:"trueCount"`
+	RunningCount   float64                       `json:"runningCount"`
+	DecksRemaining float64                       `json:"decksRemaining"`
+	ShoePct        int                           `json:"shoePct"`
 
 	// Table parameters
-	Anchor         float64 `json:"anchor"`
-	SpreadNum      float64 `json:"spreadNum"`
-	LastBet        float64 `json:"lastBet"`
-	GrowthCapBps   int     `json:"growthCapBps"`
-	TableMin       float64 `json:"tableMin"`
-	TableMax       float64 `json:"tableMax"`
+	Anchor       float64 `json:"anchor"`
+	SpreadNum    float64 `json:"spreadNum"`
+	LastBet      float64 `json:"lastBet"`
+	GrowthCapBps int     `json:"growthCapBps"`
+	TableMin     float64 `json:"tableMin"`
+	TableMax     float64 `json:"tableMax"`
 
 	// Metadata
-	CreatedAt      time.Time `json:"createdAt"`
-	LastUpdated    time.Time `json:"lastUpdated"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastUpdated time.Time `json:"lastUpdated"`
 }
 
-// GlobalEngine holds the global game state (singleton pattern)
+// GlobalEngine holds one table's game state
+// Each table has its own engine and lock; TableManager keeps them by table ID
 type GlobalEngine struct {
 	mu    sync.RWMutex
 	state *EngineState
+	rules Rules
+	clock Clock
+
+	// Hand logs for replay; a shoe's logs are published once the shoe is retired
+	hand      *HandLog   // Hand being played
+	shoeHands []*HandLog // Hands dealt from the current shoe
+	history   []HandLog  // Hands from retired shoes, oldest first
+
+	// Provably fair seeds for off-chain play
+	nextCommit *random.Commitment // Published for the next shoe
+	shoeCommit *random.Commitment // The current shoe was shuffled under it
+	reveals    []random.Reveal    // Server seeds of retired shoes, oldest first
+
+	holeProof *CardProof // Proof of the dealer's hole card, published when it is revealed
+
+	// Journal of every transition, oldest first
+	journal    []Event
+	seq        int64         // Sequence number of the last event
+	phases     []GamePhase   // Phases entered since the last event
+	phasesFrom GamePhase     // Phase before the first of them
+	journalled chan struct{} // Closed when the next event is journaled
+
+	decisionPhase GamePhase // Phase the decision timer was last armed in
 }
 
-var (
-	globalEngine     *GlobalEngine
-	globalEngineOnce sync.Once
-)
+// NewEngine returns an engine with safe default state playing by rules
+func NewEngine(rules Rules) *GlobalEngine {
+	return &GlobalEngine{
+		state: newDefaultState(),
+		rules: rules,
+		clock: SystemClock,
+	}
+}
 
-// GetEngine returns the singleton game engine instance
+// GetEngine returns the default table's engine
 // Always returns a valid engine with safe default state
 func GetEngine() *GlobalEngine {
-	globalEngineOnce.Do(func() {
-		globalEngine = &GlobalEngine{
-			state: newDefaultState(),
-		}
-		log.Println("Game engine initialized with default state")
-	})
-	return globalEngine
+	return GetTableManager().Default()
 }
 
 // newDefaultState creates a new default engine state
 func newDefaultState() *EngineState {
 	return &EngineState{
-		Phase:          PhaseWaitingForDeal,
-		PhaseDetail:    "",
-		DeckInitialized: false,
-		CardsDealt:     0,
-		TotalCards:     0,
-		DealerCards:    []Card{},
-		PlayerCards:    []Card{},
-		DealerHand:     []string{},
-		PlayerHand:     []string{},
-		Outcome:        "",
-		Payout:         "0",
-		FeeLink:        "0",
-		FeeNickelRef:   "0",
-		TrueCount:      0.0,
-		ShoePct:        0,
-		RunningCount:   0,
-		Anchor:         100.0,
-		SpreadNum:      4.0,
-		LastBet:        0.0,
-		GrowthCapBps:   3300,
-		TableMin:       5.0,
-		TableMax:       5000.0,
-		CreatedAt:      time.Now(),
-		LastUpdated:    time.Now(),
+		Phase:            PhaseWaitingForDeal,
+		PhaseDetail:      "",
+		DeckInitialized:  false,
+		ShoeID:           0,
+		CardsDealt:       0,
+		CardsRemaining:   0,
+		TotalCards:       0,
+		ReshuffleAt:      0,
+		Seats:            newSeats(),
+		ActiveSeat:       0,
+		DealerCards:      []Card{},
+		PlayerCards:      []Card{},
+		DealerHand:       []string{},
+		PlayerHand:       []string{},
+		CardProofs:       []CardProof{},
+		ShoeRoots:        []string{},
+		Hands:            []PlayerHand{},
+		ActiveHand:       0,
+		SideBets:         []SideBetResult{},
+		InsuranceAmount:  "0",
+		InsuranceOutcome: "",
+		InsurancePayout:  "0",
+		EvenMoney:        false,
+		Outcome:          "",
+		Payout:           "0",
+		FeeLink:          "0",
+		FeeNickelRef:     "0",
+		CountingSystem:   CountHiLo,
+		Counts:           newCounts(DefaultRules().Decks),
+		TrueCount:        0.0,
+		RunningCount:     0,
+		DecksRemaining:   0,
+		ShoePct:          0,
+		Anchor:           100.0,
+		SpreadNum:        4.0,
+		LastBet:          0.0,
+		GrowthCapBps:     3300,
+		TableMin:         5.0,
+		TableMax:         5000.0,
+		CreatedAt:        time.Now(),
+		LastUpdated:      time.Now(),
 	}
 }
 
@@ -143,21 +281,91 @@ func (e *GlobalEngine) GetState() *EngineState {
 
 	// Return a copy to prevent external modification
 	stateCopy := *e.state
+	stateCopy.Hands = copyHands(e.state.Hands)
+	stateCopy.Seats = make([]Seat, len(e.state.Seats))
+	stateCopy.SideBets = append([]SideBetResult(nil), e.state.SideBets...)
+	stateCopy.CardProofs = append([]CardProof(nil), e.state.CardProofs...)
+	stateCopy.ShoeRoots = append([]string{}, e.state.ShoeRoots...)
+	for i, seat := range e.state.Seats {
+		seat.Hands = copyHands(seat.Hands)
+		seat.SideBets = append([]SideBetResult(nil), seat.SideBets...)
+		seat.InitialCards = append([]Card(nil), seat.InitialCards...)
+		stateCopy.Seats[i] = seat
+	}
+	stateCopy.Counts = make(map[CountingSystem]CountState, len(e.state.Counts))
+	for system, count := range e.state.Counts {
+		stateCopy.Counts[system] = count
+	}
+	if deadline := e.state.DecisionDeadline; deadline != nil {
+		d := *deadline
+		stateCopy.DecisionDeadline = &d
+		stateCopy.DecisionRemainingMs = max(d.Sub(e.now()).Milliseconds(), 0)
+	}
 	return &stateCopy
 }
 
+// HandID returns the ID of the hand the engine is playing (or last played)
+func (e *GlobalEngine) HandID() int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.state.HandID
+}
+
+// Rules returns the rules the engine is playing by
+func (e *GlobalEngine) Rules() Rules {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.rules
+}
+
+// SetRules changes the table rules
+// Only allowed between hands so a round is never played under two rule sets
+func (e *GlobalEngine) SetRules(rules Rules) error {
+	if err := rules.Validate(); err != nil {
+		return fmt.Errorf("invalid rules: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state.Phase != PhaseWaitingForDeal && e.state.Phase != PhaseComplete {
+		return fmt.Errorf("cannot change rules in phase %s, must be WAITING_FOR_DEAL or COMPLETE", e.state.Phase)
+	}
+
+	e.rules = rules
+
+	// Retire the current shoe so the next hand is dealt from a shoe built for these rules
+	e.retireShoe()
+	e.state.Deck = nil
+	e.state.DeckInitialized = false
+
+	log.Printf("Engine rules updated: %+v", rules)
+	return nil
+}
+
 // Reset resets the engine to default state
 func (e *GlobalEngine) Reset() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	e.retireShoe()
+	e.hand = nil
+	e.phases = nil
 	e.state = newDefaultState()
+	e.state.CreatedAt = e.now()
+	e.state.LastUpdated = e.state.CreatedAt
 	log.Println("Engine state reset to default")
 }
 
-// StartHand initializes a new hand with bet information
+// StartHand starts a heads-up round for one player with bet information
+// The player plays from their seat, or seat 0 if they are not seated; the seat is
+// released again when the next betting window opens. Tables with other seated
+// players go through OpenBetting/PlaceBet/CloseBetting instead.
+// Side bets are placed with the bet, so the hand starts with both or not at all.
 // Transitions: WAITING_FOR_DEAL → SHUFFLING
-func (e *GlobalEngine) StartHand(handID int64, playerAddr, tokenAddr, betAmount string, betAmountFloat float64) error {
+func (e *GlobalEngine) StartHand(handID int64, playerAddr, tokenAddr, betAmount string, betAmountFloat float64, sideBets ...SideBetWager) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -166,150 +374,1133 @@ func (e *GlobalEngine) StartHand(handID int64, playerAddr, tokenAddr, betAmount
 		return fmt.Errorf("cannot start hand in phase %s, must be WAITING_FOR_DEAL or COMPLETE", e.state.Phase)
 	}
 
+	amount, err := decimal.NewFromString(betAmount)
+	if err != nil {
+		return fmt.Errorf("invalid bet amount: %w", err)
+	}
+	if !amount.IsPositive() {
+		return fmt.Errorf("bet must be positive, got %s", betAmount)
+	}
+	if err := ValidateSideBets(sideBets); err != nil {
+		return fmt.Errorf("invalid side bets: %w", err)
+	}
+
+	// Heads-up seats from earlier rounds are released; seats taken with JoinSeat are not
+	seat := -1
+	for i := range e.state.Seats {
+		s := &e.state.Seats[i]
+		switch {
+		case s.PlayerAddr == "":
+		case s.PlayerAddr == playerAddr:
+			if seat < 0 {
+				seat = i
+			}
+		case s.HeadsUp:
+			s.vacate()
+		default:
+			return fmt.Errorf("seat %d is taken by another player, use the betting window", i)
+		}
+	}
+	if seat < 0 {
+		seat = 0
+		e.state.Seats[seat].PlayerAddr = playerAddr
+		e.state.Seats[seat].HeadsUp = true
+	}
+
 	// Initialize new hand
-	e.state.Phase = PhaseShuffling
-	e.state.PhaseDetail = "Creating and shuffling deck..."
-	e.state.HandID = handID
-	e.state.PlayerAddr = playerAddr
-	e.state.TokenAddr = tokenAddr
-	e.state.BetAmount = betAmount
+	e.resetRound(handID)
+	s := &e.state.Seats[seat]
+	s.placeBet(tokenAddr, amount, e.rules.variant().HandsPerBet())
+	s.enterRound(e.rules.variant().HandsPerBet())
+	bet := HandAction{Seat: seat, Action: ActionBet, Player: playerAddr, Token: tokenAddr, Amount: betAmount}
+	e.record(bet)
+
+	if err := e.setPhase(PhaseShuffling, "Creating and shuffling deck..."); err != nil {
+		return err
+	}
+	e.state.ActiveSeat = seat
 	e.state.LastBet = betAmountFloat
-	e.state.DealerCards = []Card{}
-	e.state.PlayerCards = []Card{}
-	e.state.DealerHand = []string{}
-	e.state.PlayerHand = []string{}
-	e.state.Outcome = ""
-	e.state.Payout = "0"
-	e.state.LastUpdated = time.Now()
+	e.syncActiveSeat()
+	e.state.LastUpdated = e.now()
+	e.emit(EventHandStarted, &bet)
+	if len(sideBets) > 0 {
+		e.placeSideBets(s, sideBets)
+	}
 
-	log.Printf("Hand started: handID=%d, player=%s, amount=%s", handID, playerAddr, betAmount)
+	log.Printf("Hand started: handID=%d, player=%s, seat=%d, amount=%s, wagered=%s", handID, playerAddr, seat, betAmount, s.Wagered)
 	return nil
 }
 
-// ShuffleAndDeal creates deck, shuffles with seed, and deals initial cards
+// resetRound clears the table and every seat for a new round
+// Caller must hold e.mu
+func (e *GlobalEngine) resetRound(handID int64) {
+	e.state.HandID = handID
+	e.state.DealerCards = []Card{}
+	e.state.DealerHand = []string{}
+	e.state.DealerPeeked = false
+	e.state.HoleCardRevealed = false
+	e.state.CardProofs = []CardProof{}
+	e.holeProof = nil
+	e.state.FeeLink = "0"
+	e.state.FeeNickelRef = "0"
+	e.state.ActiveSeat = 0
+	e.hand = &HandLog{HandID: handID, Rules: e.rules}
+
+	for i := range e.state.Seats {
+		e.state.Seats[i].resetRound()
+	}
+	e.syncActiveSeat()
+}
+
+// ShuffleAndDeal deals the initial cards from the current shoe
+// A new shoe is shuffled with seed only when there is none yet or the cut card has come out
 // Transitions: SHUFFLING → DEALING → PLAYER_TURN
 func (e *GlobalEngine) ShuffleAndDeal(seed []byte) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	return e.shuffleAndDeal(seed, false)
+}
+
+// ShuffleAndDealFair deals like ShuffleAndDeal, shuffling a new shoe from the published
+// commitment: the committed server seed, the player's client seed and the nonce
+// Transitions: SHUFFLING → DEALING → PLAYER_TURN
+func (e *GlobalEngine) ShuffleAndDealFair() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.shuffleAndDeal(nil, true)
+}
+
+// shuffleAndDeal deals the round, first shuffling a new shoe if one is due
+// Caller must hold e.mu
+func (e *GlobalEngine) shuffleAndDeal(seed []byte, fair bool) error {
 	// Validate current phase
 	if e.state.Phase != PhaseShuffling {
 		return fmt.Errorf("cannot shuffle in phase %s, must be SHUFFLING", e.state.Phase)
 	}
 
-	// Create and shuffle deck (7 decks standard)
-	deck := NewDeck(7)
+	// The cut card only takes effect between rounds, so the round that drew it was finished first
+	if e.state.Deck == nil || e.state.Deck.CutCardReached() {
+		var commit *random.Commitment
+		if fair {
+			var err error
+			if commit, err = e.useCommitment(); err != nil {
+				e.voidRound(err)
+				return err
+			}
+			seed = commit.ShuffleSeed()
+		}
+		e.newShoe(seed)
+		e.shoeCommit = commit
+		if commit != nil {
+			public := commit.Public()
+			e.state.Commitment = &public
+		}
+	} else {
+		log.Printf("Continuing shoe %d: %d cards dealt, cut card at %d", e.state.ShoeID, e.state.CardsDealt, e.state.ReshuffleAt)
+	}
+	e.logDeal()
+
+	err := e.dealInitialCards()
+	e.syncActiveSeat()
+	if err != nil {
+		e.voidRound(err)
+		return err
+	}
+	e.emit(EventCardsDealt, nil)
+	return nil
+}
+
+// voidRound calls off a round whose deal failed, so the table can start the next one
+// The seats stay seated but leave the round with their bets and side bets returned; cards
+// already drawn are burned, and the hand is dropped from the shoe's logs
+// Caller must hold e.mu
+func (e *GlobalEngine) voidRound(cause error) {
+	if e.state.Phase != PhaseShuffling && e.state.Phase != PhaseDealing {
+		return
+	}
+
+	if n := len(e.shoeHands); n > 0 && e.shoeHands[n-1] == e.hand {
+		e.shoeHands = e.shoeHands[:n-1]
+	}
+	handID := e.state.HandID
+	e.resetRound(handID)
+	e.hand = nil
+	if err := e.setPhase(PhaseWaitingForDeal, fmt.Sprintf("Round voided: %v", cause)); err != nil {
+		log.Printf("Void hand %d: %v", handID, err)
+		return
+	}
+	e.updateShoeState()
+	e.state.LastUpdated = e.now()
+	e.emit(EventRoundVoided, nil)
+
+	log.Printf("Round voided: handID=%d: %v", handID, cause)
+}
+
+// newShoe builds and shuffles a fresh shoe for the table's rules
+// Caller must hold e.mu
+func (e *GlobalEngine) newShoe(seed []byte) {
+	deck := e.rules.NewDeck()
 	deck.Shuffle(seed)
+	deck.SetCutCard(e.rules.ReshuffleAt())
+	e.useShoe(deck)
+}
 
+// useShoe puts a shuffled shoe on the table, retiring the one before it
+// Caller must hold e.mu
+func (e *GlobalEngine) useShoe(deck *Deck) {
+	e.retireShoe()
 	e.state.Deck = deck
 	e.state.DeckInitialized = true
+	e.state.Commitment = nil
+	e.state.ShoeID++
+	e.state.ShuffleVersion = deck.Version()
+	e.state.ShoeRoot = deck.Commitment().Root
+	e.state.ShoeRoots = []string{e.state.ShoeRoot}
 	e.state.TotalCards = len(deck.Cards)
-	e.state.CardsDealt = 0
+	e.state.ReshuffleAt = e.rules.ReshuffleAt()
+	e.updateShoeState()
+	e.resetCounts()
 
+	log.Printf("Reshuffle: shoe %d, %d cards, cut card at %d, shuffle %s, root %s", e.state.ShoeID, e.state.TotalCards, e.state.ReshuffleAt, e.state.ShuffleVersion, e.state.ShoeRoot)
+}
+
+// updateShoeState refreshes the shoe position metrics from the deck
+// Caller must hold e.mu
+func (e *GlobalEngine) updateShoeState() {
+	deck := e.state.Deck
+	if deck == nil {
+		return
+	}
+
+	e.state.CardsDealt = deck.Dealt()
+	e.state.CardsRemaining = deck.Remaining()
+	e.state.CutCardReached = deck.CutCardReached()
+	if e.state.TotalCards > 0 {
+		e.state.ShoePct = (e.state.CardsDealt * 100) / e.state.TotalCards
+	}
+	e.refreshCounts()
+}
+
+// dealInitialCards deals the opening cards from the current deck
+// Caller must hold e.mu
+func (e *GlobalEngine) dealInitialCards() error {
 	// Update phase to dealing
-	e.state.Phase = PhaseDealing
-	e.state.PhaseDetail = "Dealing initial cards..."
-	e.state.LastUpdated = time.Now()
+	if err := e.setPhase(PhaseDealing, "Dealing initial cards..."); err != nil {
+		return err
+	}
+	e.state.LastUpdated = e.now()
+
+	// Deal in casino order: one card to each seat in turn, then the dealer, twice
+	// Without a hole card the dealer's second card waits until every seat has acted
+	// Cards go on the table as they are drawn so a refill never reshuffles them
+	seats := e.roundSeats()
+	e.state.DealerCards = nil
+	e.state.HoleCardRevealed = false
+	e.state.CardProofs = []CardProof{}
+	e.holeProof = nil
+	for _, n := range seats {
+		for h := range e.state.Seats[n].Hands {
+			hand := &e.state.Seats[n].Hands[h]
+			hand.Cards = nil
+			hand.Images = nil
+		}
+	}
+	for pass := 0; pass < 2; pass++ {
+		for _, n := range seats {
+			for h := range e.state.Seats[n].Hands {
+				if _, err := e.dealToHand(&e.state.Seats[n].Hands[h]); err != nil {
+					return fmt.Errorf("failed to deal seat %d: %w", n, err)
+				}
+			}
+		}
+		if pass == 1 && !e.rules.Peeks() {
+			break
+		}
 
-	// Deal initial hands (dealer-player-dealer-player pattern)
-	e.state.DealerCards = []Card{deck.Deal(), deck.Deal()}
-	e.state.PlayerCards = []Card{deck.Deal(), deck.Deal()}
-	e.state.CardsDealt = 4
+		card, err := e.drawCard()
+		if err != nil {
+			return fmt.Errorf("failed to deal dealer card: %w", err)
+		}
+		e.state.DealerCards = append(e.state.DealerCards, card)
+		if pass == 0 {
+			e.countCard(card) // Hole card is counted when revealed
+		} else {
+			e.holdHoleProof()
+		}
+	}
+	for _, n := range seats {
+		s := &e.state.Seats[n]
+		s.InitialCards = append([]Card(nil), s.Hands[0].Cards...)
+	}
 
 	// Convert to image paths
-	e.state.DealerHand = []string{
-		CardToImagePath(e.state.DealerCards[0]),
-		"/cards/back.png", // Second dealer card is face-down
+	e.state.DealerHand = []string{CardToImagePath(e.state.DealerCards[0])}
+	if e.rules.Peeks() {
+		e.state.DealerHand = append(e.state.DealerHand, "/cards/back.png") // Second dealer card is face-down
+	}
+	e.state.ActiveSeat = seats[0]
+
+	// Dealer showing an Ace offers insurance before peeking at the hole card
+	upcard := e.state.DealerCards[0]
+	switch {
+	case upcard.Rank == Ace:
+		if err := e.setPhase(PhaseInsuranceOffer, ""); err != nil {
+			return err
+		}
+		e.offerInsurance()
+	case e.rules.earlySurrender() && e.rules.Peeks() && isTenValue(upcard) && e.anyHandWithoutBlackjack():
+		// Early surrender is decided before the dealer checks the hole card
+		e.markNaturals()
+		if err := e.startPlayerTurn(); err != nil {
+			return err
+		}
+		if e.state.Phase == PhasePlayerTurn {
+			e.state.PhaseDetail = "Player's turn - early surrender available"
+		}
+	default:
+		if err := e.checkBlackjacks(); err != nil {
+			return err
+		}
+	}
+
+	e.state.LastUpdated = e.now()
+	log.Printf("Cards dealt: dealer=%v, seats=%v, phase=%s", e.state.DealerCards, seats, e.state.Phase)
+	return nil
+}
+
+// anyHandWithoutBlackjack reports whether some hand in the round was not dealt a natural
+// Caller must hold e.mu
+func (e *GlobalEngine) anyHandWithoutBlackjack() bool {
+	for _, n := range e.roundSeats() {
+		for _, hand := range e.state.Seats[n].Hands {
+			if !hand.natural() {
+				return true
+			}
+		}
 	}
+	return false
+}
 
-	e.state.PlayerHand = []string{
-		CardToImagePath(e.state.PlayerCards[0]),
-		CardToImagePath(e.state.PlayerCards[1]),
+// markNaturals finishes every hand dealt a blackjack; they are settled at resolution
+// Caller must hold e.mu
+func (e *GlobalEngine) markNaturals() {
+	for _, n := range e.roundSeats() {
+		for h := range e.state.Seats[n].Hands {
+			hand := &e.state.Seats[n].Hands[h]
+			if hand.natural() {
+				hand.Done = true
+			}
+		}
 	}
+}
 
-	// Check for blackjack
-	playerBJ := IsBlackjack(e.state.PlayerCards)
-	dealerBJ := IsBlackjack(e.state.DealerCards)
+// checkBlackjacks peeks for naturals and moves to resolution or the player's turn
+// Without a hole card there is nothing to peek at, and naturals wait for the dealer's second card
+// Caller must hold e.mu
+func (e *GlobalEngine) checkBlackjacks() error {
+	if e.rules.Peeks() {
+		e.state.DealerPeeked = true
+	}
 
-	if playerBJ || dealerBJ {
+	if IsBlackjack(e.state.DealerCards) {
 		// Skip to resolution
-		e.state.Phase = PhaseResolution
+		if err := e.setPhase(PhaseResolution, "Resolving blackjack..."); err != nil {
+			return err
+		}
+		e.revealHoleCard()
+		return nil
+	}
+
+	// Player naturals are paid without playing; everyone else plays in seat order
+	e.markNaturals()
+	if err := e.startPlayerTurn(); err != nil {
+		return err
+	}
+	if e.state.Phase == PhaseResolution {
 		e.state.PhaseDetail = "Resolving blackjack..."
-		// Reveal dealer's hole card
-		e.state.DealerHand[1] = CardToImagePath(e.state.DealerCards[1])
-	} else {
-		// Move to player's turn
-		e.state.Phase = PhasePlayerTurn
-		e.state.PhaseDetail = "Player's turn - choose action"
+	}
+	return nil
+}
+
+// startPlayerTurn hands the turn to the first seat with a hand left to play
+// Caller must hold e.mu
+func (e *GlobalEngine) startPlayerTurn() error {
+	if err := e.setPhase(PhasePlayerTurn, "Player's turn - choose action"); err != nil {
+		return err
+	}
+	for _, n := range e.roundSeats() {
+		if !e.state.Seats[n].finished() {
+			e.state.ActiveSeat = n
+			e.state.Seats[n].ActiveHand = e.state.Seats[n].firstOpenHand()
+			return nil
+		}
+	}
+	return e.finishPlayerTurns()
+}
+
+// offerInsurance points the round at the first seat still to decide on insurance
+// Caller must hold e.mu
+func (e *GlobalEngine) offerInsurance() {
+	for _, n := range e.roundSeats() {
+		s := &e.state.Seats[n]
+		if s.InsuranceDecided {
+			continue
+		}
+
+		e.state.ActiveSeat = n
+		if len(s.Hands) == 1 && s.Hands[0].natural() {
+			e.state.PhaseDetail = "Dealer shows an Ace - even money offered"
+		} else {
+			e.state.PhaseDetail = "Dealer shows an Ace - insurance offered"
+		}
+		return
+	}
+}
+
+// PlayerInsurance settles the insurance decision for the seat whose turn it is
+// amount may be empty to insure for the maximum (half the bet)
+// Transitions: INSURANCE_OFFER → PLAYER_TURN (or RESOLUTION on a blackjack or even money)
+func (e *GlobalEngine) PlayerInsurance(buy bool, amount string) error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatInsurance(seat, playerAddr, buy, amount)
+}
+
+// SeatInsurance records one seat's insurance decision; seats may decide in any order
+// Once every seat has decided the dealer peeks and insurance is settled 2:1
+// Transitions: INSURANCE_OFFER → PLAYER_TURN (or RESOLUTION on a blackjack or even money)
+func (e *GlobalEngine) SeatInsurance(seat int, playerAddr string, buy bool, amount string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
+
+	return e.seatInsurance(seat, playerAddr, buy, amount, false)
+}
+
+// seatInsurance records a seat's insurance decision; timedOut marks a decline forced by the decision timer
+// Caller must hold e.mu
+func (e *GlobalEngine) seatInsurance(seat int, playerAddr string, buy bool, amount string, timedOut bool) error {
+	if e.state.Phase != PhaseInsuranceOffer {
+		return fmt.Errorf("cannot take insurance in phase %s, must be INSURANCE_OFFER", e.state.Phase)
+	}
+
+	s, err := e.heldSeat(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+	if !s.InRound || s.InsuranceDecided {
+		return fmt.Errorf("seat %d has no insurance decision to make", seat)
+	}
+
+	bet, err := decimal.NewFromString(s.Hands[0].Bet)
+	if err != nil {
+		return fmt.Errorf("invalid bet amount: %w", err)
 	}
 
-	e.state.LastUpdated = time.Now()
-	log.Printf("Cards dealt: dealer=%v, player=%v, phase=%s", e.state.DealerCards, e.state.PlayerCards, e.state.Phase)
+	// Even money: a player blackjack is paid 1:1 now, whatever the hole card
+	if buy && len(s.Hands) == 1 && s.Hands[0].natural() {
+		s.EvenMoney = true
+		s.InsuranceOutcome = "even_money"
+		s.Hands[0].Done = true
+		log.Printf("Seat %d took even money", seat)
+	} else {
+		insurance := decimal.Zero
+		if buy {
+			maxInsurance := bet.Div(decimal.NewFromInt(2))
+			insurance = maxInsurance
+			if amount != "" {
+				insurance, err = decimal.NewFromString(amount)
+				if err != nil {
+					return fmt.Errorf("invalid insurance amount: %w", err)
+				}
+			}
+
+			if !insurance.IsPositive() || insurance.GreaterThan(maxInsurance) {
+				return fmt.Errorf("insurance must be between 0 and %s, got %s", maxInsurance.String(), insurance.String())
+			}
+		}
+		s.InsuranceAmount = insurance.String()
+		log.Printf("Seat %d insurance: amount=%s", seat, s.InsuranceAmount)
+	}
+	s.InsuranceDecided = true
+	action := HandAction{Seat: seat, Action: ActionInsurance, Buy: buy, Amount: amount, TimedOut: timedOut}
+	e.record(action)
 
+	if err := e.closeInsurance(); err != nil {
+		return err
+	}
+	e.state.LastUpdated = e.now()
+	e.emit(EventInsuranceDecided, &action)
 	return nil
 }
 
-// PlayerHit adds a card to player's hand
-// Stays in: PLAYER_TURN (or moves to DEALER_TURN if bust)
+// closeInsurance peeks and settles insurance once every seat has decided
+// Without a hole card insurance is settled once the dealer's second card is dealt
+// Caller must hold e.mu
+func (e *GlobalEngine) closeInsurance() error {
+	for _, n := range e.roundSeats() {
+		if !e.state.Seats[n].InsuranceDecided {
+			e.offerInsurance()
+			return nil
+		}
+	}
+
+	if e.rules.Peeks() {
+		e.settleInsurance()
+	}
+	return e.checkBlackjacks()
+}
+
+// settleInsurance pays insurance 2:1 if the dealer has blackjack
+// Caller must hold e.mu
+func (e *GlobalEngine) settleInsurance() {
+	dealerBJ := IsBlackjack(e.state.DealerCards)
+	for _, n := range e.roundSeats() {
+		s := &e.state.Seats[n]
+		if !s.InsuranceDecided || s.InsuranceOutcome != "" {
+			continue
+		}
+
+		insurance, _ := decimal.NewFromString(s.InsuranceAmount)
+		switch {
+		case s.EvenMoney:
+		case !insurance.IsPositive():
+			s.InsuranceOutcome = "declined"
+		case dealerBJ:
+			s.InsuranceOutcome = "win"
+			s.InsurancePayout = insurance.Mul(decimal.NewFromInt(2)).String()
+		default:
+			s.InsuranceOutcome = "lose"
+			s.InsurancePayout = insurance.Neg().String()
+		}
+	}
+
+	log.Printf("Insurance closed: dealerBJ=%v", dealerBJ)
+}
+
+// PlayerHit adds a card to the active hand of the seat whose turn it is
+// Stays in: PLAYER_TURN (or moves on once every hand is finished)
 func (e *GlobalEngine) PlayerHit() error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatHit(seat, playerAddr)
+}
+
+// SeatHit adds a card to the seat's active hand
+// Stays in: PLAYER_TURN (or moves on once every hand is finished)
+func (e *GlobalEngine) SeatHit(seat int, playerAddr string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
 
 	if e.state.Phase != PhasePlayerTurn {
 		return fmt.Errorf("cannot hit in phase %s, must be PLAYER_TURN", e.state.Phase)
 	}
 
+	s, err := e.checkTurn(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+
+	if peeked, err := e.resolvePendingPeek(); err != nil {
+		return err
+	} else if peeked {
+		action := HandAction{Seat: seat, Action: ActionHit}
+		e.record(action)
+		e.emit(EventPlayerHit, &action)
+		return nil
+	}
+
 	if e.state.Deck == nil {
 		return fmt.Errorf("deck not initialized")
 	}
 
-	// Deal one card to player
-	card := e.state.Deck.Deal()
-	e.state.PlayerCards = append(e.state.PlayerCards, card)
-	e.state.PlayerHand = append(e.state.PlayerHand, CardToImagePath(card))
-	e.state.CardsDealt++
-
-	// Check for bust
-	if IsBust(e.state.PlayerCards) {
-		e.state.Phase = PhaseResolution
-		e.state.PhaseDetail = "Player bust - resolving hand..."
-		// Reveal dealer's hole card
-		e.state.DealerHand[1] = CardToImagePath(e.state.DealerCards[1])
+	// Deal one card to the active hand
+	hand := &s.Hands[s.ActiveHand]
+	card, err := e.dealToHand(hand)
+	if err != nil {
+		return fmt.Errorf("failed to hit: %w", err)
 	}
+	bust := IsBust(hand.Cards)
+
+	log.Printf("Player hit: seat=%d, hand=%d, card=%v, total cards=%d, bust=%v", seat, s.ActiveHand, card, len(hand.Cards), bust)
+	action := HandAction{Seat: seat, Action: ActionHit}
+	e.record(action)
 
-	e.state.LastUpdated = time.Now()
-	log.Printf("Player hit: card=%v, total cards=%d, bust=%v", card, len(e.state.PlayerCards), IsBust(e.state.PlayerCards))
+	// A bust or a Charlie finishes this hand; play moves on to the next one
+	if bust || e.rules.charlie(hand.Cards) {
+		hand.Done = true
+		if err := e.advanceHand(); err != nil {
+			return err
+		}
+	}
 
+	e.state.LastUpdated = e.now()
+	e.emit(EventPlayerHit, &action)
 	return nil
 }
 
-// PlayerStand ends player's turn and starts dealer's turn
-// Transitions: PLAYER_TURN → DEALER_TURN
+// PlayerStand finishes the active hand of the seat whose turn it is
+// Transitions: PLAYER_TURN → DEALER_TURN once every hand is finished
 func (e *GlobalEngine) PlayerStand() error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatStand(seat, playerAddr)
+}
+
+// SeatStand finishes the seat's active hand
+// Transitions: PLAYER_TURN → DEALER_TURN once every hand is finished
+func (e *GlobalEngine) SeatStand(seat int, playerAddr string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
+
+	return e.seatStand(seat, playerAddr, false)
+}
 
+// seatStand stands the seat's active hand; timedOut marks a stand forced by the decision timer
+// Caller must hold e.mu
+func (e *GlobalEngine) seatStand(seat int, playerAddr string, timedOut bool) error {
 	if e.state.Phase != PhasePlayerTurn {
 		return fmt.Errorf("cannot stand in phase %s, must be PLAYER_TURN", e.state.Phase)
 	}
 
-	e.state.Phase = PhaseDealerTurn
-	e.state.PhaseDetail = "Dealer's turn..."
-	e.state.LastUpdated = time.Now()
+	s, err := e.checkTurn(seat, playerAddr)
+	if err != nil {
+		return err
+	}
 
-	// Reveal dealer's hole card
-	e.state.DealerHand[1] = CardToImagePath(e.state.DealerCards[1])
+	if peeked, err := e.resolvePendingPeek(); err != nil {
+		return err
+	} else if peeked {
+		action := HandAction{Seat: seat, Action: ActionStand, TimedOut: timedOut}
+		e.record(action)
+		e.emit(EventPlayerStand, &action)
+		return nil
+	}
+
+	log.Printf("Player stands: seat=%d, hand=%d, timedOut=%v", seat, s.ActiveHand, timedOut)
+	action := HandAction{Seat: seat, Action: ActionStand, TimedOut: timedOut}
+	e.record(action)
+
+	s.Hands[s.ActiveHand].Done = true
+	if err := e.advanceHand(); err != nil {
+		return err
+	}
+
+	e.state.LastUpdated = e.now()
+	e.emit(EventPlayerStand, &action)
+	return nil
+}
+
+// PlayerDouble doubles down for the seat whose turn it is
+// Transitions: PLAYER_TURN → DEALER_TURN (or next hand, or RESOLUTION on bust)
+func (e *GlobalEngine) PlayerDouble() error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatDouble(seat, playerAddr)
+}
+
+// SeatDouble doubles the seat's active hand bet, deals exactly one card and stands
+// Transitions: PLAYER_TURN → DEALER_TURN (or next hand, or RESOLUTION on bust)
+func (e *GlobalEngine) SeatDouble(seat int, playerAddr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
+
+	if e.state.Phase != PhasePlayerTurn {
+		return fmt.Errorf("cannot double in phase %s, must be PLAYER_TURN", e.state.Phase)
+	}
+
+	s, err := e.checkTurn(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+
+	if peeked, err := e.resolvePendingPeek(); err != nil {
+		return err
+	} else if peeked {
+		action := HandAction{Seat: seat, Action: ActionDouble}
+		e.record(action)
+		e.emit(EventPlayerDouble, &action)
+		return nil
+	}
+
+	if e.state.Deck == nil {
+		return fmt.Errorf("deck not initialized")
+	}
 
-	log.Println("Player stands, dealer's turn begins")
+	hand := &s.Hands[s.ActiveHand]
+	if len(hand.Cards) != 2 {
+		return fmt.Errorf("can only double on the first two cards")
+	}
+
+	if hand.FromSplit && !e.rules.AllowDAS {
+		return fmt.Errorf("double after split not allowed")
+	}
+
+	if !e.rules.CanDouble(hand.Cards, hand.FromSplit) {
+		total, _ := CalculateHandValue(hand.Cards)
+		return fmt.Errorf("cannot double on %d, table allows doubling on %s", total, e.rules.DoubleOn)
+	}
+
+	bet, err := decimal.NewFromString(hand.Bet)
+	if err != nil {
+		return fmt.Errorf("invalid bet amount: %w", err)
+	}
+	stake := bet.Add(hand.freeBet())
+	free := e.rules.variant().FreeDouble(*hand)
+
+	card, err := e.dealToHand(hand)
+	if err != nil {
+		return fmt.Errorf("failed to double: %w", err)
+	}
+	if free {
+		hand.FreeBet = hand.freeBet().Add(stake).String()
+	} else {
+		hand.Bet = bet.Add(stake).String()
+	}
+	hand.Doubled = true
+	hand.Done = true
+
+	log.Printf("Player doubled: seat=%d, hand=%d, card=%v, bet=%s, free=%v", seat, s.ActiveHand, card, hand.Bet, free)
+	action := HandAction{Seat: seat, Action: ActionDouble}
+	e.record(action)
+
+	if err := e.advanceHand(); err != nil {
+		return err
+	}
+
+	e.state.LastUpdated = e.now()
+	e.emit(EventPlayerDouble, &action)
 	return nil
 }
 
+// PlayerSurrender surrenders for the seat whose turn it is
+// Transitions: PLAYER_TURN (or INSURANCE_OFFER for early surrender) → next seat or RESOLUTION
+func (e *GlobalEngine) PlayerSurrender() error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatSurrender(seat, playerAddr)
+}
+
+// SeatSurrender forfeits half the bet as the first decision on a two-card hand
+// Late surrender is offered after the dealer peeks; early surrender before the peek
+// Transitions: PLAYER_TURN (or INSURANCE_OFFER for early surrender) → next seat or RESOLUTION
+func (e *GlobalEngine) SeatSurrender(seat int, playerAddr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
+
+	if !e.rules.AllowSurrender {
+		return fmt.Errorf("surrender not allowed at this table")
+	}
+
+	var s *Seat
+	var err error
+	switch {
+	case e.state.Phase == PhasePlayerTurn:
+		s, err = e.checkTurn(seat, playerAddr)
+	case e.state.Phase == PhaseInsuranceOffer && e.rules.earlySurrender():
+		// Early surrender against an Ace replaces the insurance decision
+		s, err = e.heldSeat(seat, playerAddr)
+		if err == nil && (!s.InRound || s.InsuranceDecided) {
+			err = fmt.Errorf("seat %d has no decision to make", seat)
+		}
+	default:
+		return fmt.Errorf("cannot surrender in phase %s, must be PLAYER_TURN", e.state.Phase)
+	}
+	if err != nil {
+		return err
+	}
+
+	hand := &s.Hands[s.ActiveHand]
+	if len(s.Hands) != 1 || len(hand.Cards) != 2 || hand.Done {
+		return fmt.Errorf("surrender is only allowed as the first decision on a two-card hand")
+	}
+
+	hand.Surrendered = true
+	hand.Done = true
+
+	log.Printf("Player surrendered (%s): seat=%d, dealerPeeked=%v", e.rules.SurrenderMode, seat, e.state.DealerPeeked)
+	action := HandAction{Seat: seat, Action: ActionSurrender}
+	e.record(action)
+
+	if e.state.Phase == PhaseInsuranceOffer {
+		s.InsuranceDecided = true
+		if err := e.closeInsurance(); err != nil {
+			return err
+		}
+	} else if err := e.advanceHand(); err != nil {
+		return err
+	}
+
+	e.state.LastUpdated = e.now()
+	e.emit(EventPlayerSurrender, &action)
+	return nil
+}
+
+// resolvePendingPeek runs the dealer's deferred blackjack check once a player
+// passes on early surrender by taking any other action
+// Returns true if the dealer had blackjack and the hand moved to resolution
+// Caller must hold e.mu
+func (e *GlobalEngine) resolvePendingPeek() (bool, error) {
+	if e.state.DealerPeeked || !e.rules.Peeks() {
+		return false, nil
+	}
+
+	if err := e.checkBlackjacks(); err != nil {
+		return false, err
+	}
+	if e.state.Phase != PhaseResolution {
+		return false, nil
+	}
+
+	e.state.LastUpdated = e.now()
+	log.Println("Dealer peeked after early surrender was declined: blackjack")
+	return true, nil
+}
+
+// PlayerSplit splits the active hand for the seat whose turn it is
+// Stays in: PLAYER_TURN (or moves on once split aces are dealt out)
+func (e *GlobalEngine) PlayerSplit() error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatSplit(seat, playerAddr)
+}
+
+// SeatSplit splits the seat's active pair into two hands with equal bets
+// Stays in: PLAYER_TURN (or moves on once split aces are dealt out)
+func (e *GlobalEngine) SeatSplit(seat int, playerAddr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
+
+	if e.state.Phase != PhasePlayerTurn {
+		return fmt.Errorf("cannot split in phase %s, must be PLAYER_TURN", e.state.Phase)
+	}
+
+	s, err := e.checkTurn(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+
+	if peeked, err := e.resolvePendingPeek(); err != nil {
+		return err
+	} else if peeked {
+		action := HandAction{Seat: seat, Action: ActionSplit}
+		e.record(action)
+		e.emit(EventPlayerSplit, &action)
+		return nil
+	}
+
+	if e.state.Deck == nil {
+		return fmt.Errorf("deck not initialized")
+	}
+
+	hand := &s.Hands[s.ActiveHand]
+	if len(hand.Cards) != 2 || hand.Cards[0].Rank != hand.Cards[1].Rank {
+		return fmt.Errorf("can only split a pair")
+	}
+
+	if len(s.Hands) >= e.rules.MaxSplitHands {
+		return fmt.Errorf("split limit reached: %d hands", e.rules.MaxSplitHands)
+	}
+
+	aces := hand.Cards[0].Rank == Ace
+	if aces && hand.SplitAces && e.rules.SplitAcesOnce {
+		return fmt.Errorf("split aces cannot be re-split")
+	}
+
+	stake, err := hand.stake()
+	if err != nil {
+		return err
+	}
+
+	// Second card moves to a new hand right after the active one, staked like it (by the house for a free split)
+	newHand := PlayerHand{
+		Cards:     []Card{hand.Cards[1]},
+		Images:    []string{hand.Images[1]},
+		Bet:       stake.String(),
+		FromSplit: true,
+		SplitAces: aces,
+		Payout:    "0",
+	}
+	if e.rules.variant().FreeSplit(*hand) {
+		newHand.Bet = "0"
+		newHand.FreeBet = stake.String()
+	}
+	hand.Cards = hand.Cards[:1]
+	hand.Images = hand.Images[:1]
+	hand.FromSplit = true
+	hand.SplitAces = aces
+
+	idx := s.ActiveHand
+	s.Hands = append(s.Hands, PlayerHand{})
+	copy(s.Hands[idx+2:], s.Hands[idx+1:])
+	s.Hands[idx+1] = newHand
+
+	// Active hand receives its second card now; the new hand gets one when its turn comes
+	hand = &s.Hands[idx]
+	card, err := e.dealToHand(hand)
+	if err != nil {
+		return fmt.Errorf("failed to split: %w", err)
+	}
+
+	log.Printf("Player split: seat=%d, hand=%d, card=%v, hands=%d", seat, idx, card, len(s.Hands))
+	action := HandAction{Seat: seat, Action: ActionSplit}
+	e.record(action)
+
+	if aces && e.rules.SplitAcesOnce {
+		hand.Done = true
+		if err := e.advanceHand(); err != nil {
+			return err
+		}
+	}
+
+	e.state.LastUpdated = e.now()
+	e.emit(EventPlayerSplit, &action)
+	return nil
+}
+
+// PlayerSwitch swaps the second cards of the two hands of the seat whose turn it is
+// Stays in: PLAYER_TURN (or moves on if both hands are finished)
+func (e *GlobalEngine) PlayerSwitch() error {
+	seat, playerAddr := e.turnPlayer()
+	return e.SeatSwitch(seat, playerAddr)
+}
+
+// SeatSwitch swaps the second cards of the seat's two hands (Blackjack Switch)
+// Only allowed as the seat's first decision; a switched hand can make 21 but not blackjack
+// Stays in: PLAYER_TURN (or moves on if both hands are finished)
+func (e *GlobalEngine) SeatSwitch(seat int, playerAddr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer e.syncActiveSeat()
+
+	if !e.rules.variant().CanSwitch() {
+		return fmt.Errorf("switching not allowed at this table")
+	}
+
+	if e.state.Phase != PhasePlayerTurn {
+		return fmt.Errorf("cannot switch in phase %s, must be PLAYER_TURN", e.state.Phase)
+	}
+
+	s, err := e.checkTurn(seat, playerAddr)
+	if err != nil {
+		return err
+	}
+
+	if len(s.Hands) != 2 || s.Hands[0].Switched {
+		return fmt.Errorf("switch is only allowed as the first decision on two hands")
+	}
+	a, b := &s.Hands[0], &s.Hands[1]
+	if len(a.Cards) != 2 || len(b.Cards) != 2 || a.Done || b.Done {
+		return fmt.Errorf("switch is only allowed as the first decision on two hands")
+	}
+
+	a.Cards[1], b.Cards[1] = b.Cards[1], a.Cards[1]
+	a.Images[1], b.Images[1] = b.Images[1], a.Images[1]
+	a.Switched = true
+	b.Switched = true
+	s.ActiveHand = 0
+
+	log.Printf("Player switched: seat=%d, hands=%v / %v", seat, a.Cards, b.Cards)
+	action := HandAction{Seat: seat, Action: ActionSwitch}
+	e.record(action)
+
+	e.state.LastUpdated = e.now()
+	e.emit(EventPlayerSwitch, &action)
+	return nil
+}
+
+// dealToHand deals one card from the deck onto a player hand
+// Caller must hold e.mu
+func (e *GlobalEngine) dealToHand(hand *PlayerHand) (Card, error) {
+	card, err := e.drawCard()
+	if err != nil {
+		return Card{}, err
+	}
+	hand.Cards = append(hand.Cards, card)
+	hand.Images = append(hand.Images, CardToImagePath(card))
+	e.countCard(card)
+	return card, nil
+}
+
+// drawCard deals the next card from the shoe
+// If the shoe runs dry mid-round (possible with deep penetration and several splits),
+// the discards are reshuffled from a seed derived from the shoe seed and dealing continues
+// Caller must hold e.mu
+func (e *GlobalEngine) drawCard() (Card, error) {
+	deck := e.state.Deck
+	if deck == nil {
+		return Card{}, fmt.Errorf("deck not initialized")
+	}
+
+	card, err := deck.Draw()
+	if errors.Is(err, ErrDeckExhausted) {
+		if err := deck.ReshuffleDiscards(e.cardsInPlay()); err != nil {
+			return Card{}, fmt.Errorf("shoe %d: %w", e.state.ShoeID, err)
+		}
+		// The refill is a new arrangement under a new root; the roots before it still back the proofs already given
+		root := deck.Commitment().Root
+		e.state.ShoeRoots = append(e.state.ShoeRoots, root)
+		log.Printf("Shoe %d exhausted mid-round: reshuffled %d discards, root %s", e.state.ShoeID, deck.Remaining(), root)
+		e.updateShoeState()
+		e.recountVisible()
+		card, err = deck.Draw()
+	}
+	if err != nil {
+		return Card{}, err
+	}
+
+	proof, err := deck.Prove(deck.Dealt() - 1)
+	if err != nil {
+		return Card{}, err
+	}
+	e.state.CardProofs = append(e.state.CardProofs, proof)
+
+	e.updateShoeState()
+	return card, nil
+}
+
+// holdHoleProof keeps the proof of the card just dealt, the dealer's hole card, back until it is revealed
+// Caller must hold e.mu
+func (e *GlobalEngine) holdHoleProof() {
+	last := len(e.state.CardProofs) - 1
+	if last < 0 {
+		return
+	}
+	proof := e.state.CardProofs[last]
+	e.holeProof = &proof
+	e.state.CardProofs = e.state.CardProofs[:last]
+}
+
+// cardsInPlay returns every card on the table this round
+// Caller must hold e.mu
+func (e *GlobalEngine) cardsInPlay() []Card {
+	cards := append([]Card(nil), e.state.DealerCards...)
+	for _, n := range e.roundSeats() {
+		for _, hand := range e.state.Seats[n].Hands {
+			cards = append(cards, hand.Cards...)
+		}
+	}
+	return cards
+}
+
+// advanceHand moves play to the next unfinished hand, then to the next seat
+// Once every seat is finished the dealer's turn begins, or resolution if no hand is left to beat
+// Caller must hold e.mu
+func (e *GlobalEngine) advanceHand() error {
+	s := &e.state.Seats[e.state.ActiveSeat]
+	for i := s.ActiveHand + 1; i < len(s.Hands); i++ {
+		hand := &s.Hands[i]
+		if hand.Done {
+			continue
+		}
+
+		// Split hands are dealt their second card when play reaches them
+		if len(hand.Cards) == 1 {
+			if _, err := e.dealToHand(hand); err != nil {
+				return fmt.Errorf("failed to deal split hand %d: %w", i, err)
+			}
+		}
+
+		// Split aces receive a single card and stand
+		if hand.SplitAces && e.rules.SplitAcesOnce {
+			hand.Done = true
+			continue
+		}
+
+		s.ActiveHand = i
+		e.state.PhaseDetail = fmt.Sprintf("Player's turn - hand %d of %d", i+1, len(s.Hands))
+		return nil
+	}
+
+	// This seat is done; the next seat with a hand to play takes the turn
+	for _, n := range e.roundSeats() {
+		if n <= e.state.ActiveSeat || e.state.Seats[n].finished() {
+			continue
+		}
+
+		e.state.ActiveSeat = n
+		e.state.Seats[n].ActiveHand = e.state.Seats[n].firstOpenHand()
+		e.state.PhaseDetail = fmt.Sprintf("Seat %d's turn - choose action", n+1)
+		return nil
+	}
+
+	return e.finishPlayerTurns()
+}
+
+// finishPlayerTurns reveals the hole card and hands over to the dealer
+// The dealer only draws if some hand is still live (not bust, surrendered or a paid natural)
+// Without a hole card the dealer also deals its second card for naturals and insurance bets
+// Caller must hold e.mu
+func (e *GlobalEngine) finishPlayerTurns() error {
+	e.revealHoleCard()
+
+	live := e.liveHands()
+	allBust := true
+	waiting := false // Naturals and insurance bets settled by the dealer's second card
+	for _, n := range e.roundSeats() {
+		s := e.state.Seats[n]
+		for _, hand := range s.Hands {
+			if !IsBust(hand.Cards) {
+				allBust = false
+			}
+			if hand.natural() && !s.EvenMoney {
+				waiting = true
+			}
+		}
+		if insurance, _ := decimal.NewFromString(s.InsuranceAmount); insurance.IsPositive() {
+			waiting = true
+		}
+	}
+
+	if !e.rules.Peeks() && len(e.state.DealerCards) == 1 {
+		if live || waiting {
+			log.Println("Player's turn complete, dealer deals its second card")
+			return e.setPhase(PhaseDealerTurn, "Dealer's turn...")
+		}
+		e.settleInsurance()
+	}
+
+	switch {
+	case allBust:
+		log.Println("All player hands bust, skipping dealer's turn")
+		return e.setPhase(PhaseResolution, "Player bust - resolving hand...")
+	case !live:
+		log.Println("No live player hands, skipping dealer's turn")
+		return e.setPhase(PhaseResolution, "Resolving hand...")
+	default:
+		log.Println("Player's turn complete, dealer's turn begins")
+		return e.setPhase(PhaseDealerTurn, "Dealer's turn...")
+	}
+}
+
+// liveHands reports whether some hand is left for the dealer to beat
+// Bust and surrendered hands are settled already, naturals and even money don't play against the dealer's total
+// Caller must hold e.mu
+func (e *GlobalEngine) liveHands() bool {
+	for _, n := range e.roundSeats() {
+		s := e.state.Seats[n]
+		for _, hand := range s.Hands {
+			if !IsBust(hand.Cards) && !hand.Surrendered && !hand.natural() && !s.EvenMoney {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // DealerPlay executes dealer's turn according to rules
 // Transitions: DEALER_TURN → RESOLUTION
 func (e *GlobalEngine) DealerPlay() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	return e.dealerPlay()
+}
+
+// dealerPlay plays the dealer's hand
+// Caller must hold e.mu
+func (e *GlobalEngine) dealerPlay() error {
 	if e.state.Phase != PhaseDealerTurn {
 		return fmt.Errorf("cannot dealer play in phase %s, must be DEALER_TURN", e.state.Phase)
 	}
@@ -318,8 +1509,27 @@ func (e *GlobalEngine) DealerPlay() error {
 		return fmt.Errorf("deck not initialized")
 	}
 
-	// Dealer plays according to rules
-	e.state.DealerCards = DealerPlay(e.state.Deck, e.state.DealerCards, true) // hitSoft17 = true
+	// Without a hole card the second card comes first; it settles naturals and insurance
+	if len(e.state.DealerCards) == 1 {
+		card, err := e.drawCard()
+		if err != nil {
+			return fmt.Errorf("failed to deal dealer card: %w", err)
+		}
+		e.state.DealerCards = append(e.state.DealerCards, card)
+		e.state.HoleCardRevealed = true
+		e.countCard(card)
+		e.settleInsurance()
+	}
+
+	// Dealer plays according to rules, as long as a hand is left to beat
+	for e.liveHands() && e.rules.DealerShouldHit(e.state.DealerCards) {
+		card, err := e.drawCard()
+		if err != nil {
+			return fmt.Errorf("failed to deal dealer card: %w", err)
+		}
+		e.state.DealerCards = append(e.state.DealerCards, card)
+		e.countCard(card)
+	}
 
 	// Update dealer hand display
 	e.state.DealerHand = make([]string, len(e.state.DealerCards))
@@ -327,85 +1537,242 @@ func (e *GlobalEngine) DealerPlay() error {
 		e.state.DealerHand[i] = CardToImagePath(card)
 	}
 
-	e.state.CardsDealt += len(e.state.DealerCards) - 2 // Count additional cards
+	e.updateShoeState()
 
-	e.state.Phase = PhaseResolution
-	e.state.PhaseDetail = "Resolving hand outcome..."
-	e.state.LastUpdated = time.Now()
+	if err := e.setPhase(PhaseResolution, "Resolving hand outcome..."); err != nil {
+		return err
+	}
+	e.state.LastUpdated = e.now()
+	e.emit(EventDealerPlayed, nil)
 
 	log.Printf("Dealer played: cards=%v, total=%d", e.state.DealerCards, len(e.state.DealerCards))
 	return nil
 }
 
-// ResolveHand calculates outcome and payout
+// ResolveHand calculates every seat's outcome and payout
 // Transitions: RESOLUTION → COMPLETE
 func (e *GlobalEngine) ResolveHand() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	return e.resolveHand()
+}
+
+// FinishRound plays the dealer's turn and resolves the hand once the players are done
+// Both run under one hold of the lock, so racing callers finish a round once; it only acts in DEALER_TURN and RESOLUTION
+func (e *GlobalEngine) FinishRound() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.finishRound()
+}
+
+// finishRound plays the dealer's turn and resolves the hand, as far as the phase calls for
+// Caller must hold e.mu
+func (e *GlobalEngine) finishRound() error {
+	if e.state.Phase == PhaseDealerTurn {
+		if err := e.dealerPlay(); err != nil {
+			return fmt.Errorf("dealer play: %w", err)
+		}
+	}
+	if e.state.Phase == PhaseResolution {
+		if err := e.resolveHand(); err != nil {
+			return fmt.Errorf("resolve hand: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveHand settles every seat in the round
+// Caller must hold e.mu
+func (e *GlobalEngine) resolveHand() error {
+	defer e.syncActiveSeat()
+
 	if e.state.Phase != PhaseResolution {
 		return fmt.Errorf("cannot resolve in phase %s, must be RESOLUTION", e.state.Phase)
 	}
 
-	// Parse bet amount
-	betAmount, err := decimal.NewFromString(e.state.BetAmount)
-	if err != nil {
-		return fmt.Errorf("invalid bet amount: %w", err)
-	}
+	tableBet := decimal.Zero
+	tablePayout := decimal.Zero
+	for _, n := range e.roundSeats() {
+		s := &e.state.Seats[n]
+
+		// Evaluate every hand against the dealer
+		totalBet := decimal.Zero
+		net := decimal.Zero
+		for i := range s.Hands {
+			hand := &s.Hands[i]
+
+			betAmount, err := decimal.NewFromString(hand.Bet)
+			if err != nil {
+				return fmt.Errorf("invalid bet amount on seat %d hand %d: %w", n, i, err)
+			}
+
+			var settled Settlement
+			if s.EvenMoney {
+				settled = Settlement{Outcome: "win", Payout: betAmount, Reason: ReasonEvenMoney}
+			} else {
+				settled = e.rules.EvaluateHand(*hand, e.state.DealerCards, betAmount, i == 0)
+			}
+
+			hand.Outcome = settled.Outcome
+			hand.Reason = settled.Reason
+			hand.Payout = settled.Payout.String()
+			totalBet = totalBet.Add(betAmount)
+			net = net.Add(settled.Payout)
+		}
+
+		// Single hand reports its own outcome; split rounds report the net result
+		outcome := s.Hands[0].Outcome
+		if len(s.Hands) > 1 {
+			switch net.Sign() {
+			case 1:
+				outcome = "win"
+			case -1:
+				outcome = "lose"
+			default:
+				outcome = "push"
+			}
+		}
+
+		if err := e.settleSideBets(s); err != nil {
+			return err
+		}
+
+		s.Outcome = outcome
+		s.Payout = net.String()
+		tableBet = tableBet.Add(totalBet)
+		tablePayout = tablePayout.Add(net)
 
-	// Evaluate outcome
-	outcome, payout := EvaluateOutcome(e.state.PlayerCards, e.state.DealerCards, betAmount, 14000) // 140% = 3:2 blackjack
+		log.Printf("Seat %d resolved: outcome=%s, payout=%s, hands=%d", n, outcome, s.Payout, len(s.Hands))
+	}
 
 	// Calculate fees
 	feeLink := decimal.Zero
-	feeNickelRef := betAmount.Mul(decimal.NewFromInt(5)).Div(decimal.NewFromInt(10000)) // 0.05%
+	feeNickelRef := tableBet.Mul(decimal.NewFromInt(5)).Div(decimal.NewFromInt(10000)) // 0.05%
 
-	e.state.Outcome = outcome
-	e.state.Payout = payout.String()
 	e.state.FeeLink = feeLink.String()
 	e.state.FeeNickelRef = feeNickelRef.String()
 
-	e.state.Phase = PhaseComplete
-	e.state.PhaseDetail = fmt.Sprintf("Hand complete - %s", outcome)
-	e.state.LastUpdated = time.Now()
+	if err := e.setPhase(PhaseComplete, fmt.Sprintf("Hand complete - %s", e.state.Seats[e.state.ActiveSeat].Outcome)); err != nil {
+		return err
+	}
+	e.state.LastUpdated = e.now()
+	e.logResult()
+	e.emit(EventHandResolved, nil)
 
-	log.Printf("Hand resolved: outcome=%s, payout=%s", outcome, payout.String())
+	log.Printf("Hand resolved: handID=%d, seats=%d, payout=%s", e.state.HandID, len(e.roundSeats()), tablePayout.String())
 	return nil
 }
 
-// UpdateCounting updates the card counting metrics
-func (e *GlobalEngine) UpdateCounting(runningCount int) {
+// SetCountingSystem selects the system mirrored into RunningCount/TrueCount
+// Every system is tracked all the time, so switching mid-shoe is exact
+func (e *GlobalEngine) SetCountingSystem(system CountingSystem) error {
+	if err := system.Validate(); err != nil {
+		return err
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	e.state.RunningCount = runningCount
+	e.state.CountingSystem = system
+	e.refreshCounts()
+	e.state.LastUpdated = e.now()
+	return nil
+}
+
+// revealHoleCard turns the dealer's second card face up and counts it
+// Caller must hold e.mu
+func (e *GlobalEngine) revealHoleCard() {
+	if e.state.HoleCardRevealed || len(e.state.DealerCards) < 2 {
+		return
+	}
 
-	if e.state.DeckInitialized && e.state.TotalCards > 0 {
-		cardsRemaining := e.state.TotalCards - e.state.CardsDealt
-		decksRemaining := float64(cardsRemaining) / 52.0
+	e.state.DealerHand[1] = CardToImagePath(e.state.DealerCards[1])
+	e.state.HoleCardRevealed = true
+	e.countCard(e.state.DealerCards[1])
+	if e.holeProof != nil {
+		e.state.CardProofs = append(e.state.CardProofs, *e.holeProof)
+		e.holeProof = nil
+	}
+}
 
-		if decksRemaining > 0 {
-			e.state.TrueCount = float64(runningCount) / decksRemaining
-		} else {
-			e.state.TrueCount = 0
+// newCounts returns every system's count at the start of a shoe
+func newCounts(decks int) map[CountingSystem]CountState {
+	counts := make(map[CountingSystem]CountState, len(CountingSystems))
+	for _, system := range CountingSystems {
+		counts[system] = CountState{RunningCount: system.InitialRunningCount(decks)}
+	}
+	return counts
+}
+
+// resetCounts starts every system over for a fresh shoe
+// Caller must hold e.mu
+func (e *GlobalEngine) resetCounts() {
+	e.state.Counts = newCounts(e.rules.Decks)
+	e.refreshCounts()
+}
+
+// countCard adds a newly visible card to every system's running count
+// Caller must hold e.mu
+func (e *GlobalEngine) countCard(card Card) {
+	if e.state.Counts == nil {
+		e.state.Counts = newCounts(e.rules.Decks)
+	}
+	for system, count := range e.state.Counts {
+		count.RunningCount += system.Tag(card)
+		e.state.Counts[system] = count
+	}
+	e.refreshCounts()
+}
+
+// recountVisible restarts the counts from the cards face up on the table
+// Used after discards are reshuffled back into the shoe mid-round
+// Caller must hold e.mu
+func (e *GlobalEngine) recountVisible() {
+	e.resetCounts()
+	for i, card := range e.state.DealerCards {
+		if i == 1 && !e.state.HoleCardRevealed {
+			continue
+		}
+		e.countCard(card)
+	}
+	for _, n := range e.roundSeats() {
+		for _, hand := range e.state.Seats[n].Hands {
+			for _, card := range hand.Cards {
+				e.countCard(card)
+			}
 		}
+	}
+}
 
-		e.state.ShoePct = (e.state.CardsDealt * 100) / e.state.TotalCards
+// refreshCounts recomputes true counts from the decks left in the shoe
+// Caller must hold e.mu
+func (e *GlobalEngine) refreshCounts() {
+	e.state.DecksRemaining = float64(e.state.CardsRemaining) / float64(e.rules.DeckSize())
+
+	for system, count := range e.state.Counts {
+		count.TrueCount = system.TrueCount(count.RunningCount, e.state.DecksRemaining)
+		e.state.Counts[system] = count
 	}
 
-	e.state.LastUpdated = time.Now()
+	selected := e.state.Counts[e.state.CountingSystem]
+	e.state.RunningCount = selected.RunningCount
+	e.state.TrueCount = selected.TrueCount
 }
 
 // ValidateTransition checks if a phase transition is valid
 func ValidateTransition(from, to GamePhase) error {
 	validTransitions := map[GamePhase][]GamePhase{
-		PhaseWaitingForDeal: {PhaseShuffling},
-		PhaseShuffling:      {PhaseDealing},
-		PhaseDealing:        {PhasePlayerTurn, PhaseResolution}, // Direct to resolution for blackjack
-		PhasePlayerTurn:     {PhaseDealerTurn, PhaseResolution}, // Direct to resolution for bust
+		PhaseWaitingForDeal: {PhaseBetting, PhaseShuffling},
+		PhaseBetting:        {PhaseShuffling},
+		PhaseShuffling:      {PhaseDealing, PhaseWaitingForDeal},                                          // Back to waiting when the deal fails
+		PhaseDealing:        {PhaseInsuranceOffer, PhasePlayerTurn, PhaseResolution, PhaseWaitingForDeal}, // Direct to resolution for blackjack
+		PhaseInsuranceOffer: {PhasePlayerTurn, PhaseResolution},                                           // Resolution on dealer blackjack or even money
+		PhasePlayerTurn:     {PhaseDealerTurn, PhaseResolution},                                           // Direct to resolution for bust
 		PhaseDealerTurn:     {PhaseResolution},
 		PhaseResolution:     {PhaseComplete},
-		PhaseComplete:       {PhaseWaitingForDeal, PhaseShuffling},
+		PhaseComplete:       {PhaseWaitingForDeal, PhaseBetting, PhaseShuffling},
 	}
 
 	allowed, exists := validTransitions[from]
@@ -0,0 +1,720 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// card is a shorthand for building test cards from their string forms
+func card(value, suit string) Card {
+	c, err := ParseCard(value + "-" + suit)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// newTestEngine returns an engine mid-hand with a stacked deck
+// Cards are listed as dealer, dealer, player, player, then any further draws,
+// and stacked in casino deal order (player, dealer, player, dealer)
+func newTestEngine(t *testing.T, rules Rules, cards ...Card) *GlobalEngine {
+	t.Helper()
+
+	if len(cards) >= 4 {
+		stacked := []Card{cards[2], cards[0], cards[3], cards[1]}
+		cards = append(stacked, cards[4:]...)
+	}
+
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+
+	e.state.Deck = &Deck{Cards: cards}
+	e.state.DeckInitialized = true
+	e.state.ShoeID = 1
+	e.state.TotalCards = len(cards)
+	e.state.ReshuffleAt = len(cards)
+	if err := e.dealInitialCards(); err != nil {
+		t.Fatalf("dealInitialCards: %v", err)
+	}
+	return e
+}
+
+func TestPlayerSplitPlaysEachHand(t *testing.T) {
+	e := newTestEngine(t, DefaultRules(),
+		card("10", "C"), card("7", "D"), // dealer 17
+		card("8", "H"), card("8", "S"), // player pair
+		card("3", "C"),  // first split hand: 8+3
+		card("10", "H"), // second split hand: 8+10
+	)
+
+	if err := e.PlayerSplit(); err != nil {
+		t.Fatalf("PlayerSplit: %v", err)
+	}
+
+	state := e.GetState()
+	if len(state.Hands) != 2 || state.ActiveHand != 0 {
+		t.Fatalf("hands=%d active=%d, want 2 hands with first active", len(state.Hands), state.ActiveHand)
+	}
+	if len(state.Hands[0].Cards) != 2 || len(state.Hands[1].Cards) != 1 {
+		t.Fatalf("card counts = %d/%d, want 2/1 before second hand is played", len(state.Hands[0].Cards), len(state.Hands[1].Cards))
+	}
+
+	if err := e.PlayerStand(); err != nil {
+		t.Fatalf("PlayerStand first hand: %v", err)
+	}
+	if state = e.GetState(); state.Phase != PhasePlayerTurn || state.ActiveHand != 1 {
+		t.Fatalf("phase=%s active=%d, want PLAYER_TURN on second hand", state.Phase, state.ActiveHand)
+	}
+
+	if err := e.PlayerStand(); err != nil {
+		t.Fatalf("PlayerStand second hand: %v", err)
+	}
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+
+	state = e.GetState()
+	if state.Hands[0].Outcome != "lose" || state.Hands[1].Outcome != "win" {
+		t.Fatalf("outcomes = %s/%s, want lose/win", state.Hands[0].Outcome, state.Hands[1].Outcome)
+	}
+	if state.Outcome != "push" || state.Payout != "0" || state.Hands[0].Payout != "-100" || state.Hands[1].Payout != "100" {
+		t.Fatalf("round outcome=%s payout=%s, want push netting 0", state.Outcome, state.Payout)
+	}
+}
+
+func TestPlayerSplitRequiresPair(t *testing.T) {
+	e := newTestEngine(t, DefaultRules(),
+		card("10", "C"), card("7", "D"),
+		card("8", "H"), card("9", "S"),
+	)
+
+	if err := e.PlayerSplit(); err == nil {
+		t.Fatal("expected error splitting a non-pair")
+	}
+}
+
+func TestPlayerSplitLimit(t *testing.T) {
+	rules := DefaultRules()
+	rules.MaxSplitHands = 2
+
+	e := newTestEngine(t, rules,
+		card("10", "C"), card("7", "D"),
+		card("8", "H"), card("8", "S"),
+		card("8", "D"), // first split hand pairs up again
+	)
+
+	if err := e.PlayerSplit(); err != nil {
+		t.Fatalf("PlayerSplit: %v", err)
+	}
+	if err := e.PlayerSplit(); err == nil {
+		t.Fatal("expected error re-splitting past the hand limit")
+	}
+}
+
+func TestSplitAcesOnce(t *testing.T) {
+	e := newTestEngine(t, DefaultRules(),
+		card("10", "C"), card("7", "D"),
+		card("A", "H"), card("A", "S"),
+		card("K", "C"), // first ace makes 21
+		card("9", "D"), // second ace makes 20
+	)
+
+	if err := e.PlayerSplit(); err != nil {
+		t.Fatalf("PlayerSplit: %v", err)
+	}
+
+	state := e.GetState()
+	if state.Phase != PhaseDealerTurn {
+		t.Fatalf("phase = %s, want DEALER_TURN after split aces receive one card each", state.Phase)
+	}
+
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+
+	// 21 on a split ace is not a blackjack: it pays even money
+	state = e.GetState()
+	if state.Hands[0].Outcome != "win" || state.Hands[0].Payout != "100" {
+		t.Fatalf("split ace 21 = %s/%s, want win paying 100", state.Hands[0].Outcome, state.Hands[0].Payout)
+	}
+	if state.Payout != "200" {
+		t.Fatalf("total payout = %s, want 200", state.Payout)
+	}
+}
+
+func TestSplitHandBustStillPlaysDealer(t *testing.T) {
+	e := newTestEngine(t, DefaultRules(),
+		card("10", "C"), card("6", "D"), // dealer 16
+		card("8", "H"), card("8", "S"),
+		card("5", "C"),  // first hand 13
+		card("K", "D"),  // first hand busts
+		card("9", "H"),  // second hand 17
+		card("10", "S"), // dealer busts
+	)
+
+	if err := e.PlayerSplit(); err != nil {
+		t.Fatalf("PlayerSplit: %v", err)
+	}
+	if err := e.PlayerHit(); err != nil {
+		t.Fatalf("PlayerHit: %v", err)
+	}
+	if state := e.GetState(); state.ActiveHand != 1 || state.Phase != PhasePlayerTurn {
+		t.Fatalf("phase=%s active=%d, want play to move on to second hand", state.Phase, state.ActiveHand)
+	}
+	if err := e.PlayerStand(); err != nil {
+		t.Fatalf("PlayerStand: %v", err)
+	}
+	if state := e.GetState(); state.Phase != PhaseDealerTurn {
+		t.Fatalf("phase = %s, want DEALER_TURN while a split hand is live", state.Phase)
+	}
+}
+
+func TestPlayerDouble(t *testing.T) {
+	e := newTestEngine(t, DefaultRules(),
+		card("10", "C"), card("7", "D"), // dealer 17
+		card("6", "H"), card("5", "S"), // player 11
+		card("9", "C"), // double to 20
+	)
+
+	if err := e.PlayerDouble(); err != nil {
+		t.Fatalf("PlayerDouble: %v", err)
+	}
+
+	state := e.GetState()
+	if state.Phase != PhaseDealerTurn {
+		t.Fatalf("phase = %s, want DEALER_TURN after doubling", state.Phase)
+	}
+	if !state.Hands[0].Doubled || state.Hands[0].Bet != "200" || len(state.Hands[0].Cards) != 3 {
+		t.Fatalf("hand = %+v, want doubled bet of 200 with exactly one extra card", state.Hands[0])
+	}
+
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+	if state = e.GetState(); state.Outcome != "win" || state.Payout != "200" {
+		t.Fatalf("outcome=%s payout=%s, want win paying the doubled bet", state.Outcome, state.Payout)
+	}
+}
+
+func TestPlayerDoubleRestrictions(t *testing.T) {
+	tests := []struct {
+		name     string
+		doubleOn DoubleRule
+		player   []Card
+		wantErr  bool
+	}{
+		{"any two cards allows soft 18", DoubleAnyTwo, []Card{card("A", "H"), card("7", "S")}, false},
+		{"9-11 allows 9", DoubleNineToEleven, []Card{card("5", "H"), card("4", "S")}, false},
+		{"9-11 rejects 8", DoubleNineToEleven, []Card{card("5", "H"), card("3", "S")}, true},
+		{"10-11 rejects 9", DoubleTenToEleven, []Card{card("5", "H"), card("4", "S")}, true},
+		{"10-11 allows 11", DoubleTenToEleven, []Card{card("5", "H"), card("6", "S")}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := DefaultRules()
+			rules.DoubleOn = tt.doubleOn
+
+			e := newTestEngine(t, rules,
+				card("10", "C"), card("7", "D"),
+				tt.player[0], tt.player[1],
+				card("2", "C"),
+			)
+
+			err := e.PlayerDouble()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlayerDouble error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlayerDoubleAfterSplit(t *testing.T) {
+	for _, allowDAS := range []bool{true, false} {
+		rules := DefaultRules()
+		rules.AllowDAS = allowDAS
+
+		e := newTestEngine(t, rules,
+			card("10", "C"), card("7", "D"),
+			card("8", "H"), card("8", "S"),
+			card("3", "C"), // first split hand 11
+			card("9", "D"), // double card
+			card("2", "H"), // second split hand
+		)
+
+		if err := e.PlayerSplit(); err != nil {
+			t.Fatalf("PlayerSplit: %v", err)
+		}
+
+		err := e.PlayerDouble()
+		if allowDAS && err != nil {
+			t.Fatalf("double after split rejected with allowDAS: %v", err)
+		}
+		if !allowDAS && err == nil {
+			t.Fatal("double after split accepted without allowDAS")
+		}
+	}
+}
+
+func TestInsuranceOffer(t *testing.T) {
+	tests := []struct {
+		name        string
+		holeCard    Card
+		buy         bool
+		amount      string
+		wantOutcome string
+		wantPayout  string
+		wantPhase   GamePhase
+		wantErr     bool
+	}{
+		{"insured dealer blackjack", card("K", "D"), true, "", "win", "100", PhaseResolution, false},
+		{"insured no blackjack", card("7", "D"), true, "20", "lose", "-20", PhasePlayerTurn, false},
+		{"declined", card("7", "D"), false, "", "declined", "0", PhasePlayerTurn, false},
+		{"over half the bet", card("7", "D"), true, "60", "", "0", PhaseInsuranceOffer, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEngine(t, DefaultRules(),
+				card("A", "C"), tt.holeCard,
+				card("10", "H"), card("8", "S"),
+			)
+
+			if phase := e.GetState().Phase; phase != PhaseInsuranceOffer {
+				t.Fatalf("phase = %s, want INSURANCE_OFFER with dealer Ace up", phase)
+			}
+
+			err := e.PlayerInsurance(tt.buy, tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlayerInsurance error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			state := e.GetState()
+			if state.Phase != tt.wantPhase {
+				t.Fatalf("phase = %s, want %s", state.Phase, tt.wantPhase)
+			}
+			if state.InsuranceOutcome != tt.wantOutcome || state.InsurancePayout != tt.wantPayout {
+				t.Fatalf("insurance = %s/%s, want %s/%s", state.InsuranceOutcome, state.InsurancePayout, tt.wantOutcome, tt.wantPayout)
+			}
+		})
+	}
+}
+
+func TestEvenMoney(t *testing.T) {
+	e := newTestEngine(t, DefaultRules(),
+		card("A", "C"), card("K", "D"), // dealer blackjack
+		card("A", "H"), card("Q", "S"), // player blackjack
+	)
+
+	if err := e.PlayerInsurance(true, ""); err != nil {
+		t.Fatalf("PlayerInsurance: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+
+	// Even money pays 1:1 even though the dealer also had blackjack
+	state := e.GetState()
+	if !state.EvenMoney || state.Outcome != "win" || state.Payout != "100" {
+		t.Fatalf("evenMoney=%v outcome=%s payout=%s, want even money win paying 100", state.EvenMoney, state.Outcome, state.Payout)
+	}
+}
+
+func TestLateSurrender(t *testing.T) {
+	rules := DefaultRules()
+	rules.AllowSurrender = true
+	rules.SurrenderMode = SurrenderLate
+
+	e := newTestEngine(t, rules,
+		card("10", "C"), card("7", "D"),
+		card("10", "H"), card("6", "S"),
+	)
+
+	if err := e.PlayerSurrender(); err != nil {
+		t.Fatalf("PlayerSurrender: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+
+	state := e.GetState()
+	if state.Outcome != "surrender" || state.Payout != "-50" {
+		t.Fatalf("outcome=%s payout=%s, want surrender losing half the bet", state.Outcome, state.Payout)
+	}
+}
+
+func TestSurrenderOnlyAsFirstDecision(t *testing.T) {
+	rules := DefaultRules()
+	rules.AllowSurrender = true
+
+	e := newTestEngine(t, rules,
+		card("10", "C"), card("7", "D"),
+		card("5", "H"), card("4", "S"),
+		card("2", "C"),
+	)
+
+	if err := e.PlayerHit(); err != nil {
+		t.Fatalf("PlayerHit: %v", err)
+	}
+	if err := e.PlayerSurrender(); err == nil {
+		t.Fatal("expected error surrendering a three-card hand")
+	}
+
+	e = newTestEngine(t, DefaultRules(),
+		card("10", "C"), card("7", "D"),
+		card("10", "H"), card("6", "S"),
+	)
+	if err := e.PlayerSurrender(); err == nil {
+		t.Fatal("expected error surrendering when the table does not allow it")
+	}
+}
+
+func TestLateSurrenderNotOfferedBeforePeek(t *testing.T) {
+	rules := DefaultRules()
+	rules.AllowSurrender = true
+	rules.SurrenderMode = SurrenderLate
+
+	e := newTestEngine(t, rules,
+		card("A", "C"), card("K", "D"),
+		card("10", "H"), card("6", "S"),
+	)
+
+	if err := e.PlayerSurrender(); err == nil {
+		t.Fatal("expected late surrender to be refused before the dealer peeks")
+	}
+}
+
+func TestEarlySurrender(t *testing.T) {
+	rules := DefaultRules()
+	rules.AllowSurrender = true
+	rules.SurrenderMode = SurrenderEarly
+
+	// Against an Ace: surrender before the peek keeps half the bet even against blackjack
+	e := newTestEngine(t, rules,
+		card("A", "C"), card("K", "D"),
+		card("10", "H"), card("6", "S"),
+	)
+	if err := e.PlayerSurrender(); err != nil {
+		t.Fatalf("PlayerSurrender against Ace: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+	if state := e.GetState(); state.Outcome != "surrender" || state.Payout != "-50" {
+		t.Fatalf("outcome=%s payout=%s, want surrender losing half the bet", state.Outcome, state.Payout)
+	}
+
+	// Against a ten: passing on surrender makes the dealer peek before the action applies
+	e = newTestEngine(t, rules,
+		card("K", "C"), card("A", "D"),
+		card("10", "H"), card("6", "S"),
+		card("5", "C"),
+	)
+	if state := e.GetState(); state.Phase != PhasePlayerTurn || state.DealerPeeked {
+		t.Fatalf("phase=%s peeked=%v, want PLAYER_TURN before the peek", state.Phase, state.DealerPeeked)
+	}
+	if err := e.PlayerHit(); err != nil {
+		t.Fatalf("PlayerHit: %v", err)
+	}
+	state := e.GetState()
+	if state.Phase != PhaseResolution || len(state.PlayerCards) != 2 {
+		t.Fatalf("phase=%s cards=%d, want dealer blackjack resolved before the hit", state.Phase, len(state.PlayerCards))
+	}
+}
+
+func TestNoHoleCardDealing(t *testing.T) {
+	for _, tc := range []struct {
+		mode    DealingMode
+		payout  string // Lost from the doubled 200 to the dealer's blackjack
+		outcome string
+	}{
+		{DealENHC, "-200", "lose"},
+		{DealOBO, "-100", "lose"},
+	} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			rules := DefaultRules()
+			rules.DealingMode = tc.mode
+
+			// Without a hole card the card after the player's is the double, then the dealer's second card
+			e := newTestEngine(t, rules,
+				card("10", "C"), card("9", "D"), // dealer upcard, player's double card
+				card("6", "H"), card("5", "S"), // player 11
+				card("A", "H"), // dealer's second card: blackjack
+			)
+			state := e.GetState()
+			if state.Phase != PhasePlayerTurn || len(state.DealerCards) != 1 || len(state.DealerHand) != 1 || state.DealerPeeked {
+				t.Fatalf("phase=%s dealer=%v peeked=%v, want the player to act against the upcard alone",
+					state.Phase, state.DealerHand, state.DealerPeeked)
+			}
+
+			if err := e.PlayerDouble(); err != nil {
+				t.Fatalf("PlayerDouble: %v", err)
+			}
+			if err := e.DealerPlay(); err != nil {
+				t.Fatalf("DealerPlay: %v", err)
+			}
+			if err := e.ResolveHand(); err != nil {
+				t.Fatalf("ResolveHand: %v", err)
+			}
+
+			state = e.GetState()
+			if !IsBlackjack(state.DealerCards) || !state.HoleCardRevealed {
+				t.Fatalf("dealer = %v, want blackjack from the second card", state.DealerCards)
+			}
+			if state.Outcome != tc.outcome || state.Payout != tc.payout {
+				t.Fatalf("outcome=%s payout=%s, want %s/%s", state.Outcome, state.Payout, tc.outcome, tc.payout)
+			}
+		})
+	}
+}
+
+func TestNoHoleCardNaturals(t *testing.T) {
+	rules := DefaultRules()
+	rules.DealingMode = DealENHC
+
+	// A natural against a ten waits for the dealer's second card, and the dealer draws no further
+	e := newTestEngine(t, rules,
+		card("10", "C"), card("6", "D"), // dealer upcard, then the second card: 16
+		card("A", "H"), card("K", "S"), // player blackjack
+		card("5", "C"),
+	)
+	if phase := e.GetState().Phase; phase != PhaseDealerTurn {
+		t.Fatalf("phase = %s, want DEALER_TURN to settle the natural", phase)
+	}
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+	if state := e.GetState(); len(state.DealerCards) != 2 || state.Outcome != "win" || state.Payout != "140" {
+		t.Fatalf("dealer=%v outcome=%s payout=%s, want two dealer cards and blackjack paying 140",
+			state.DealerCards, state.Outcome, state.Payout)
+	}
+
+	// Insurance is settled by the second card, not before the players act
+	e = newTestEngine(t, rules,
+		card("A", "C"), card("K", "D"), // dealer upcard, then the second card: blackjack
+		card("10", "H"), card("8", "S"),
+	)
+	if err := e.PlayerInsurance(true, ""); err != nil {
+		t.Fatalf("PlayerInsurance: %v", err)
+	}
+	if state := e.GetState(); state.Phase != PhasePlayerTurn || state.InsuranceOutcome != "" {
+		t.Fatalf("phase=%s insurance=%s, want PLAYER_TURN with insurance open", state.Phase, state.InsuranceOutcome)
+	}
+	if err := e.PlayerStand(); err != nil {
+		t.Fatalf("PlayerStand: %v", err)
+	}
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+	if state := e.GetState(); state.InsuranceOutcome != "win" || state.InsurancePayout != "100" || state.Outcome != "lose" {
+		t.Fatalf("insurance=%s/%s outcome=%s, want insurance paying 100 and the hand lost",
+			state.InsuranceOutcome, state.InsurancePayout, state.Outcome)
+	}
+}
+
+// playOut finishes the current round, standing on every decision
+func playOut(t *testing.T, e *GlobalEngine) {
+	t.Helper()
+
+	for {
+		var err error
+		switch e.GetState().Phase {
+		case PhaseInsuranceOffer:
+			err = e.PlayerInsurance(false, "")
+		case PhasePlayerTurn:
+			err = e.PlayerStand()
+		case PhaseDealerTurn:
+			err = e.DealerPlay()
+		case PhaseResolution:
+			err = e.ResolveHand()
+		default:
+			return
+		}
+		if err != nil {
+			t.Fatalf("playing out round in phase %s: %v", e.GetState().Phase, err)
+		}
+	}
+}
+
+func TestShoePersistsAcrossHands(t *testing.T) {
+	rules := DefaultRules()
+	rules.Decks = 1
+
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if err := e.ShuffleAndDeal([]byte("first seed")); err != nil {
+		t.Fatalf("ShuffleAndDeal: %v", err)
+	}
+	playOut(t, e)
+
+	first := e.GetState()
+	if first.ShoeID != 1 || first.ReshuffleAt != rules.ReshuffleAt() {
+		t.Fatalf("shoeId=%d reshuffleAt=%d, want shoe 1 cut at %d", first.ShoeID, first.ReshuffleAt, rules.ReshuffleAt())
+	}
+
+	if err := e.StartHand(2, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if err := e.ShuffleAndDeal([]byte("second seed")); err != nil {
+		t.Fatalf("ShuffleAndDeal: %v", err)
+	}
+
+	second := e.GetState()
+	if second.ShoeID != 1 {
+		t.Fatalf("shoeId = %d, want the shoe to continue before the cut card", second.ShoeID)
+	}
+	if second.CardsDealt <= first.CardsDealt || second.CardsDealt+second.CardsRemaining != 52 {
+		t.Fatalf("cardsDealt=%d remaining=%d after %d dealt, want counts to carry over", second.CardsDealt, second.CardsRemaining, first.CardsDealt)
+	}
+}
+
+func TestShoeReshufflesAtCutCard(t *testing.T) {
+	rules := DefaultRules()
+	rules.Decks = 1
+	rules.PenetrationBps = 770 // Cut card after 4 cards: every hand reaches it
+
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+
+	for handID := int64(1); handID <= 3; handID++ {
+		if err := e.StartHand(handID, "0xplayer", "0xtoken", "100", 100); err != nil {
+			t.Fatalf("StartHand: %v", err)
+		}
+		if err := e.ShuffleAndDeal([]byte{byte(handID)}); err != nil {
+			t.Fatalf("ShuffleAndDeal: %v", err)
+		}
+
+		if state := e.GetState(); state.ShoeID != handID || state.CardsDealt < 4 {
+			t.Fatalf("hand %d: shoeId=%d cardsDealt=%d, want a fresh shoe each hand", handID, state.ShoeID, state.CardsDealt)
+		}
+		playOut(t, e)
+	}
+}
+
+func TestShoeFinishesRoundAfterCutCard(t *testing.T) {
+	rules := DefaultRules()
+	rules.Decks = 1
+	rules.PenetrationBps = 770 // Cut card after 4 cards
+
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if err := e.ShuffleAndDeal([]byte("seed")); err != nil {
+		t.Fatalf("ShuffleAndDeal: %v", err)
+	}
+
+	// The cut card came out on the initial deal, but the round keeps dealing from the same shoe
+	state := e.GetState()
+	if !state.CutCardReached {
+		t.Fatalf("cutCardReached = false after %d cards, want true", state.CardsDealt)
+	}
+	if state.Phase == PhasePlayerTurn {
+		if err := e.PlayerHit(); err != nil {
+			t.Fatalf("PlayerHit after cut card: %v", err)
+		}
+	}
+	playOut(t, e)
+
+	if state = e.GetState(); state.ShoeID != 1 || state.CardsDealt <= rules.ReshuffleAt() {
+		t.Fatalf("shoeId=%d cardsDealt=%d, want the round finished from shoe 1", state.ShoeID, state.CardsDealt)
+	}
+}
+
+func TestShoeExhaustedMidRoundReshufflesDiscards(t *testing.T) {
+	// Three discards from earlier rounds, then the cards for this round; the shoe runs dry on the hit
+	e := &GlobalEngine{state: newDefaultState(), rules: DefaultRules()}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	e.state.Deck = &Deck{Cards: []Card{
+		card("2", "C"), card("3", "C"), card("4", "C"), // discards
+		card("10", "H"), card("10", "C"), // player 10, dealer 10
+		card("2", "S"), card("7", "D"), // player 12, dealer 17
+	}, index: 3, seed: []byte("seed")}
+	e.state.DeckInitialized = true
+	e.state.ShoeID = 1
+	if err := e.dealInitialCards(); err != nil {
+		t.Fatalf("dealInitialCards: %v", err)
+	}
+
+	if err := e.PlayerHit(); err != nil {
+		t.Fatalf("PlayerHit on an empty shoe: %v", err)
+	}
+
+	state := e.GetState()
+	if len(state.PlayerCards) != 3 {
+		t.Fatalf("player cards = %v, want a third card from the discards", state.PlayerCards)
+	}
+	switch state.PlayerCards[2].Rank {
+	case Two, Three, Four:
+	default:
+		t.Fatalf("hit card = %v, want one of the discards", state.PlayerCards[2])
+	}
+
+	playOut(t, e)
+	if state = e.GetState(); state.Phase != PhaseComplete {
+		t.Fatalf("phase = %s, want COMPLETE", state.Phase)
+	}
+}
+
+func TestFailedDealVoidsRound(t *testing.T) {
+	e := &GlobalEngine{state: newDefaultState(), rules: DefaultRules()}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100, SideBetWager{SideBetPerfectPairs, "0"}); err == nil {
+		t.Fatal("expected error starting a hand with an invalid side bet")
+	}
+	if state := e.GetState(); state.Phase != PhaseWaitingForDeal || state.HandID != 0 {
+		t.Fatalf("phase %s, hand %d after a rejected start, want the table untouched", state.Phase, state.HandID)
+	}
+
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100, SideBetWager{SideBetPerfectPairs, "10"}); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if bets := e.GetState().Seats[0].SideBets; len(bets) != 1 || bets[0].Amount != "10" {
+		t.Fatalf("side bets = %+v, want perfect pairs placed with the bet", bets)
+	}
+
+	// Three cards and no discards: the dealer's hole card can't be dealt
+	e.state.Deck = &Deck{Cards: []Card{card("10", "H"), card("9", "C"), card("8", "D")}, seed: []byte("seed")}
+	e.state.DeckInitialized = true
+	e.state.ShoeID = 1
+	if err := e.ShuffleAndDeal(nil); err == nil {
+		t.Fatal("expected error dealing from a short shoe")
+	}
+
+	state := e.GetState()
+	if state.Phase != PhaseWaitingForDeal || !strings.HasPrefix(state.PhaseDetail, "Round voided") {
+		t.Fatalf("phase %s (%s), want WAITING_FOR_DEAL after a voided round", state.Phase, state.PhaseDetail)
+	}
+	if s := state.Seats[0]; s.PlayerAddr != "0xplayer" || s.InRound || s.BetAmount != "" || len(s.SideBets) != 0 {
+		t.Fatalf("seat 0 = %+v, want the player seated with the bet returned", s)
+	}
+	if _, err := e.HandLog(1); !errors.Is(err, ErrHandNotFound) {
+		t.Errorf("HandLog of the voided hand: %v, want ErrHandNotFound", err)
+	}
+
+	// The table is free for the next hand
+	if err := e.StartHand(2, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand after a voided round: %v", err)
+	}
+}
@@ -0,0 +1,302 @@
+package game
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DanDo385/blackjack/backend/internal/random"
+)
+
+// DefaultTableID is the table used by requests that name no table
+// It is never evicted
+const DefaultTableID = "default"
+
+// DefaultIdleTTL is how long a table may go unused before it is evicted
+const DefaultIdleTTL = 30 * time.Minute
+
+// maxClosedTables is the number of closed tables whose reveals and hand logs are kept
+const maxClosedTables = 100
+
+// TableManager creates, looks up and expires independent engines keyed by table ID
+// Each engine has its own lock; the manager's lock only guards the table map
+type TableManager struct {
+	mu         sync.Mutex
+	tables     map[string]*table
+	closed     []closedTable // Oldest first
+	idleTTL    time.Duration
+	lastHandID int64
+	now        func() time.Time
+}
+
+// closedTable is what a table had published when it was closed or evicted
+// Its shoes were retired on the way out, so every hand it dealt stays verifiable
+type closedTable struct {
+	id      string
+	reveals []random.Reveal
+	history []HandLog
+}
+
+// table is one engine and when it was last used
+type table struct {
+	engine   *GlobalEngine
+	lastUsed time.Time
+}
+
+// TableInfo summarizes a table for listings
+type TableInfo struct {
+	ID       string    `json:"id"`
+	Phase    GamePhase `json:"phase"`
+	HandID   int64     `json:"handId"`
+	Seated   int       `json:"seated"`
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+var (
+	tableManager     *TableManager
+	tableManagerOnce sync.Once
+)
+
+// GetTableManager returns the process-wide table manager
+func GetTableManager() *TableManager {
+	tableManagerOnce.Do(func() {
+		tableManager = NewTableManager(DefaultIdleTTL)
+		log.Println("Table manager initialized")
+	})
+	return tableManager
+}
+
+// NewTableManager returns a manager holding only the default table
+// Tables unused for idleTTL are removed by Evict
+func NewTableManager(idleTTL time.Duration) *TableManager {
+	m := &TableManager{
+		tables:  make(map[string]*table),
+		idleTTL: idleTTL,
+		now:     time.Now,
+	}
+	m.tables[DefaultTableID] = &table{engine: NewEngine(DefaultRules()), lastUsed: m.now()}
+	return m
+}
+
+// Default returns the default table's engine
+func (m *TableManager) Default() *GlobalEngine {
+	engine, _ := m.Get(DefaultTableID)
+	return engine
+}
+
+// Get returns a table's engine and marks the table as used
+func (m *TableManager) Get(tableID string) (*GlobalEngine, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tables[tableID]
+	if !ok {
+		return nil, false
+	}
+	t.lastUsed = m.now()
+	return t.engine, true
+}
+
+// Create opens a new table with a generated ID playing by rules
+func (m *TableManager) Create(rules Rules) (string, *GlobalEngine, error) {
+	if err := rules.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid rules: %w", err)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, fmt.Errorf("failed to generate table ID: %w", err)
+	}
+	tableID := hex.EncodeToString(id)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	engine := NewEngine(rules)
+	m.tables[tableID] = &table{engine: engine, lastUsed: m.now()}
+
+	log.Printf("Table %s opened: %d decks", tableID, rules.Decks)
+	return tableID, engine, nil
+}
+
+// Close removes a table
+// The default table cannot be closed
+func (m *TableManager) Close(tableID string) error {
+	if tableID == DefaultTableID {
+		return fmt.Errorf("cannot close the default table")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tables[tableID]
+	if !ok {
+		return fmt.Errorf("table %s not found", tableID)
+	}
+	reveals, history := t.engine.Retire()
+	m.retire(tableID, reveals, history)
+
+	log.Printf("Table %s closed", tableID)
+	return nil
+}
+
+// retire removes a table whose shoe was retired, keeping its revealed server seeds and hand logs so its hands can be verified
+// Caller must hold m.mu
+func (m *TableManager) retire(tableID string, reveals []random.Reveal, history []HandLog) {
+	delete(m.tables, tableID)
+
+	m.closed = append(m.closed, closedTable{id: tableID, reveals: reveals, history: history})
+	if over := len(m.closed) - maxClosedTables; over > 0 {
+		m.closed = append([]closedTable(nil), m.closed[over:]...)
+	}
+}
+
+// ClosedReveals returns the revealed server seeds of a closed table, oldest first
+func (m *TableManager) ClosedReveals(tableID string) ([]random.Reveal, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.closed) - 1; i >= 0; i-- {
+		if m.closed[i].id == tableID {
+			return append([]random.Reveal(nil), m.closed[i].reveals...), true
+		}
+	}
+	return nil, false
+}
+
+// FindHand returns the table currently playing handID
+func (m *TableManager) FindHand(handID int64) (string, *GlobalEngine, bool) {
+	if handID == 0 {
+		return "", nil, false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, t := range m.tables {
+		if t.engine.HandID() == handID {
+			t.lastUsed = m.now()
+			return id, t.engine, true
+		}
+	}
+	return "", nil, false
+}
+
+// HandLog returns a hand's replay log from whichever table dealt it, open or closed
+// A hand whose shoe is still in play returns ErrShoeInPlay
+func (m *TableManager) HandLog(handID int64) (HandLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	err := fmt.Errorf("hand %d: %w", handID, ErrHandNotFound)
+	for _, t := range m.tables {
+		l, lerr := t.engine.HandLog(handID)
+		if lerr == nil {
+			return l, nil
+		}
+		if errors.Is(lerr, ErrShoeInPlay) {
+			err = lerr
+		}
+	}
+	for _, c := range m.closed {
+		for _, l := range c.history {
+			if l.HandID == handID {
+				l.Actions = append([]HandAction(nil), l.Actions...)
+				return l, nil
+			}
+		}
+	}
+	return HandLog{}, err
+}
+
+// NextHandID returns a hand ID no other table in this process has used
+// IDs start from the current Unix time in milliseconds and only increase
+func (m *TableManager) NextHandID() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := m.now().UnixMilli()
+	if id <= m.lastHandID {
+		id = m.lastHandID + 1
+	}
+	m.lastHandID = id
+	return id
+}
+
+// Tables lists every open table, sorted by ID
+func (m *TableManager) Tables() []TableInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	infos := make([]TableInfo, 0, len(m.tables))
+	for id, t := range m.tables {
+		state := t.engine.GetState()
+		seated := 0
+		for _, s := range state.Seats {
+			if s.PlayerAddr != "" {
+				seated++
+			}
+		}
+		infos = append(infos, TableInfo{
+			ID:       id,
+			Phase:    state.Phase,
+			HandID:   state.HandID,
+			Seated:   seated,
+			LastUsed: t.lastUsed,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// Evict removes tables unused for longer than the idle TTL and returns how many it removed
+// Their shoes are retired as on Close; the default table and tables with a round in play are kept
+func (m *TableManager) Evict() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := m.now().Add(-m.idleTTL)
+	evicted := 0
+	for id, t := range m.tables {
+		if id == DefaultTableID || !t.lastUsed.Before(cutoff) {
+			continue
+		}
+		reveals, history, ok := t.engine.RetireIdle()
+		if !ok {
+			continue
+		}
+		m.retire(id, reveals, history)
+		evicted++
+		log.Printf("Table %s evicted after %s idle", id, m.now().Sub(t.lastUsed).Round(time.Second))
+	}
+	return evicted
+}
+
+// StartEviction evicts idle tables every interval until stop is called
+func (m *TableManager) StartEviction(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				m.Evict()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTablesAreIndependent(t *testing.T) {
+	m := NewTableManager(time.Minute)
+
+	_, a, err := m.Create(DefaultRules())
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	idB, b, err := m.Create(DefaultRules())
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if a == b || a == m.Default() {
+		t.Fatal("want a separate engine per table")
+	}
+
+	if err := a.StartHand(m.NextHandID(), "0xa", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand on table a: %v", err)
+	}
+	if phase := b.GetState().Phase; phase != PhaseWaitingForDeal {
+		t.Fatalf("table b phase = %s, want WAITING_FOR_DEAL while table a plays", phase)
+	}
+	if err := b.StartHand(m.NextHandID(), "0xb", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand on table b: %v", err)
+	}
+
+	id, engine, ok := m.FindHand(b.HandID())
+	if !ok || id != idB || engine != b {
+		t.Fatalf("FindHand = %s/%v, want %s", id, ok, idB)
+	}
+	if a.HandID() == b.HandID() {
+		t.Fatal("hand IDs collide across tables")
+	}
+	if _, _, ok := m.FindHand(0); ok {
+		t.Fatal("hand 0 should not match a fresh table")
+	}
+}
+
+func TestTableCreateAndClose(t *testing.T) {
+	m := NewTableManager(time.Minute)
+
+	rules := DefaultRules()
+	rules.Decks = 2
+	id, engine, err := m.Create(rules)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got, ok := m.Get(id); !ok || got != engine || got.Rules().Decks != 2 {
+		t.Fatalf("Get(%s) did not return the created table", id)
+	}
+
+	rules.Decks = 0
+	if _, _, err := m.Create(rules); err == nil {
+		t.Fatal("expected error creating a table with invalid rules")
+	}
+
+	if err := m.Close(DefaultTableID); err == nil {
+		t.Fatal("expected error closing the default table")
+	}
+	if err := m.Close(id); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, ok := m.Get(id); ok {
+		t.Fatal("closed table still found")
+	}
+	if len(m.Tables()) != 1 {
+		t.Fatalf("tables = %v, want only the default table", m.Tables())
+	}
+}
+
+func TestIdleTablesEvicted(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m := NewTableManager(10 * time.Minute)
+	m.now = func() time.Time { return now }
+
+	idle, _, _ := m.Create(DefaultRules())
+	busy, _, _ := m.Create(DefaultRules())
+	playing, e, _ := m.Create(DefaultRules())
+	if err := e.StartHand(m.NextHandID(), "0xa", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+
+	now = now.Add(8 * time.Minute)
+	m.Get(busy)
+
+	now = now.Add(5 * time.Minute)
+	if n := m.Evict(); n != 1 {
+		t.Fatalf("evicted %d tables, want 1", n)
+	}
+	if _, ok := m.Get(idle); ok {
+		t.Fatal("idle table survived eviction")
+	}
+	if _, ok := m.Get(busy); !ok {
+		t.Fatal("recently used table was evicted")
+	}
+	if _, ok := m.Get(playing); !ok {
+		t.Fatal("table with a round in play was evicted")
+	}
+	if _, ok := m.Get(DefaultTableID); !ok {
+		t.Fatal("default table was evicted")
+	}
+}
+
+func TestNextHandIDIncreases(t *testing.T) {
+	m := NewTableManager(time.Minute)
+	m.now = func() time.Time { return time.UnixMilli(5000) }
+
+	first := m.NextHandID()
+	second := m.NextHandID()
+	if first != 5000 || second != 5001 {
+		t.Fatalf("hand IDs = %d, %d, want 5000, 5001", first, second)
+	}
+}
+
+func TestHandLogAcrossTables(t *testing.T) {
+	m := NewTableManager(time.Minute)
+	rules := DefaultRules()
+	rules.Decks = 1
+	rules.PenetrationBps = 770 // Cut card after 4 cards: every hand retires its shoe
+
+	_, e, err := m.Create(rules)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	handID := m.NextHandID()
+	if err := e.StartHand(handID, "0xa", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if err := e.ShuffleAndDeal([]byte("heads-up shoe")); err != nil {
+		t.Fatalf("ShuffleAndDeal: %v", err)
+	}
+	if _, err := m.HandLog(handID); !errors.Is(err, ErrShoeInPlay) {
+		t.Fatalf("HandLog mid-hand: %v, want ErrShoeInPlay", err)
+	}
+	playOut(t, e)
+
+	log, err := m.HandLog(handID)
+	if err != nil {
+		t.Fatalf("HandLog: %v", err)
+	}
+	if _, err := log.Verify(); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if _, err := m.HandLog(handID + 1); !errors.Is(err, ErrHandNotFound) {
+		t.Errorf("HandLog of an unknown hand: %v, want ErrHandNotFound", err)
+	}
+}
+
+func TestClosedTableRevealsShoe(t *testing.T) {
+	m := NewTableManager(time.Minute)
+
+	id, e, err := m.Create(DefaultRules())
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := e.JoinSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+	handID := m.NextHandID()
+	playFairHand(t, e, handID)
+	if _, err := m.HandLog(handID); !errors.Is(err, ErrShoeInPlay) {
+		t.Fatalf("HandLog before close: %v, want ErrShoeInPlay", err)
+	}
+	commitment := e.GetState().Commitment
+
+	// Closing the table retires the shoe in play, so its seed is revealed and the hand stays verifiable
+	if err := m.Close(id); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	reveals, ok := m.ClosedReveals(id)
+	if !ok || len(reveals) != 1 || reveals[0].ServerSeedHash != commitment.ServerSeedHash {
+		t.Fatalf("closed table reveals = %+v, want the seed of the shoe in play", reveals)
+	}
+	log, err := m.HandLog(handID)
+	if err != nil {
+		t.Fatalf("HandLog after close: %v", err)
+	}
+	if log.Seed.Fair == nil {
+		t.Fatal("closed table's hand logged without its revealed seeds")
+	}
+	if _, err := log.Verify(); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if _, ok := m.ClosedReveals("never-opened"); ok {
+		t.Error("ClosedReveals found a table that was never opened")
+	}
+}
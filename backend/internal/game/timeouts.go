@@ -0,0 +1,160 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Clock tells the engine the time
+// Decision deadlines are read from it, so tests can drive timeouts with a fake clock
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the wall clock engines use by default
+var SystemClock Clock = systemClock{}
+
+// now reads the engine's clock
+// Caller must hold e.mu
+func (e *GlobalEngine) now() time.Time {
+	if e.clock == nil {
+		return SystemClock.Now()
+	}
+	return e.clock.Now()
+}
+
+// SetClock replaces the engine's clock
+func (e *GlobalEngine) SetClock(clock Clock) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.clock = clock
+	e.armDecision(true)
+}
+
+// ExpireDecision applies the default action once the decision deadline has passed
+// A seat that timed out on its turn stands; every seat still deciding on insurance declines.
+// The round is then played out as far as the next decision, all under one hold of the lock.
+// Returns true if it acted.
+func (e *GlobalEngine) ExpireDecision() (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	deadline := e.state.DecisionDeadline
+	if deadline == nil || e.now().Before(*deadline) {
+		return false, nil
+	}
+
+	err := e.applyTimeout()
+	e.syncActiveSeat()
+	if err != nil {
+		return true, fmt.Errorf("hand %d: %w", e.state.HandID, err)
+	}
+
+	if err := e.finishRound(); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// applyTimeout takes the default action for the decision the round is waiting on
+// Caller must hold e.mu
+func (e *GlobalEngine) applyTimeout() error {
+	switch e.state.Phase {
+	case PhaseInsuranceOffer:
+		for _, n := range e.roundSeats() {
+			if e.state.Seats[n].InsuranceDecided || e.state.Phase != PhaseInsuranceOffer {
+				continue
+			}
+			log.Printf("Seat %d timed out on insurance: declined", n)
+			if err := e.seatInsurance(n, e.holder(n), false, "", true); err != nil {
+				return err
+			}
+		}
+		return nil
+	case PhasePlayerTurn:
+		log.Printf("Seat %d timed out: standing", e.state.ActiveSeat)
+		return e.seatStand(e.state.ActiveSeat, e.holder(e.state.ActiveSeat), true)
+	default:
+		e.state.DecisionDeadline = nil
+		return nil
+	}
+}
+
+// armDecision starts the timer for the decision the round now waits on, or stops it
+// The insurance offer has one window for every seat; each player decision gets a fresh timer.
+// Caller must hold e.mu
+func (e *GlobalEngine) armDecision(newDecision bool) {
+	var timeout int
+	switch e.state.Phase {
+	case PhaseInsuranceOffer:
+		timeout = e.rules.InsuranceTimeoutSec
+		newDecision = newDecision || e.decisionPhase != PhaseInsuranceOffer
+	case PhasePlayerTurn:
+		timeout = e.rules.ActionTimeoutSec
+		newDecision = true
+	}
+	e.decisionPhase = e.state.Phase
+
+	if timeout <= 0 {
+		e.state.DecisionDeadline = nil
+		return
+	}
+	if newDecision || e.state.DecisionDeadline == nil {
+		deadline := e.now().Add(time.Duration(timeout) * time.Second)
+		e.state.DecisionDeadline = &deadline
+	}
+}
+
+// ExpireDecisions applies the default action on every table whose decision timer has run out
+// Returns how many tables it acted on
+func (m *TableManager) ExpireDecisions() int {
+	m.mu.Lock()
+	engines := make(map[string]*GlobalEngine, len(m.tables))
+	for id, t := range m.tables {
+		engines[id] = t.engine
+	}
+	m.mu.Unlock()
+
+	expired := 0
+	for id, engine := range engines {
+		acted, err := engine.ExpireDecision()
+		if err != nil {
+			log.Printf("Table %s: error applying decision timeout: %v", id, err)
+		}
+		if acted {
+			expired++
+		}
+	}
+	return expired
+}
+
+// StartDecisionTimers checks every table's decision timer each interval until stop is called
+func (m *TableManager) StartDecisionTimers(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				m.ExpireDecisions()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// dealUntil deals heads-up hands until one stops in the wanted phase
+func dealUntil(t *testing.T, e *GlobalEngine, phase GamePhase) int64 {
+	t.Helper()
+
+	for seed := 0; seed < 200; seed++ {
+		handID := int64(seed + 1)
+		if err := e.StartHand(handID, seatPlayer(0), "0xtoken", "100", 100); err != nil {
+			t.Fatalf("StartHand: %v", err)
+		}
+		if err := e.ShuffleAndDeal([]byte(fmt.Sprintf("timeout %d", seed))); err != nil {
+			t.Fatalf("ShuffleAndDeal: %v", err)
+		}
+		if e.GetState().Phase == phase {
+			return handID
+		}
+		e.Reset()
+	}
+	t.Fatalf("no hand stopped in %s", phase)
+	return 0
+}
+
+func TestDecisionTimeoutStands(t *testing.T) {
+	rules := DefaultRules()
+	rules.ActionTimeoutSec = 10
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	e := NewEngine(rules)
+	e.SetClock(clock)
+	handID := dealUntil(t, e, PhasePlayerTurn)
+
+	if got := e.GetState().DecisionRemainingMs; got != 10000 {
+		t.Fatalf("remaining = %dms, want 10000ms", got)
+	}
+
+	clock.advance(9 * time.Second)
+	if acted, err := e.ExpireDecision(); acted || err != nil {
+		t.Fatalf("ExpireDecision before the deadline = %v, %v", acted, err)
+	}
+	if got := e.GetState().DecisionRemainingMs; got != 1000 {
+		t.Errorf("remaining = %dms, want 1000ms", got)
+	}
+
+	clock.advance(2 * time.Second)
+	acted, err := e.ExpireDecision()
+	if !acted || err != nil {
+		t.Fatalf("ExpireDecision after the deadline = %v, %v", acted, err)
+	}
+
+	state := e.GetState()
+	if state.Phase != PhaseComplete || state.DecisionDeadline != nil || state.DecisionRemainingMs != 0 {
+		t.Fatalf("phase %s, deadline %v after the timeout, want a resolved hand and no timer", state.Phase, state.DecisionDeadline)
+	}
+	if hands := state.Seats[0].Hands; len(hands) != 1 || len(hands[0].Cards) != 2 || hands[0].Outcome == "" {
+		t.Errorf("hands = %+v, want the two-card hand stood and settled", hands)
+	}
+
+	events, _ := e.Events(handID, 0)
+	var stand *HandAction
+	for _, ev := range events {
+		if ev.Type == EventPlayerStand {
+			stand = ev.Action
+			if !ev.Time.Equal(clock.now) {
+				t.Errorf("stand journaled at %v, want the engine clock's %v", ev.Time, clock.now)
+			}
+		}
+	}
+	if stand == nil || !stand.TimedOut {
+		t.Errorf("stand = %+v, want a journaled stand marked as timed out", stand)
+	}
+	if !state.LastUpdated.Equal(clock.now) {
+		t.Errorf("last updated %v, want the engine clock's %v", state.LastUpdated, clock.now)
+	}
+
+	// The next hand can start: the table is not left waiting on the absent player
+	if err := e.StartHand(handID+1, seatPlayer(0), "0xtoken", "100", 100); err != nil {
+		t.Errorf("StartHand after the timeout: %v", err)
+	}
+}
+
+func TestDecisionTimerRestartsEachDecision(t *testing.T) {
+	rules := DefaultRules()
+	rules.ActionTimeoutSec = 10
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	e := NewEngine(rules)
+	e.SetClock(clock)
+	dealUntil(t, e, PhasePlayerTurn)
+
+	for e.GetState().Phase == PhasePlayerTurn {
+		total, _ := CalculateHandValue(e.GetState().PlayerCards)
+		if total >= 21 {
+			break
+		}
+		clock.advance(8 * time.Second)
+		if err := e.PlayerHit(); err != nil {
+			t.Fatalf("PlayerHit: %v", err)
+		}
+		if state := e.GetState(); state.Phase == PhasePlayerTurn && state.DecisionRemainingMs != 10000 {
+			t.Fatalf("remaining = %dms after a hit, want a fresh 10000ms", state.DecisionRemainingMs)
+		}
+	}
+}
+
+func TestInsuranceTimeoutDeclines(t *testing.T) {
+	rules := DefaultRules()
+	rules.InsuranceTimeoutSec = 5
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	e := NewEngine(rules)
+	e.SetClock(clock)
+	dealUntil(t, e, PhaseInsuranceOffer)
+
+	if got := e.GetState().DecisionRemainingMs; got != 5000 {
+		t.Fatalf("remaining = %dms, want 5000ms", got)
+	}
+
+	clock.advance(5 * time.Second)
+	if acted, err := e.ExpireDecision(); !acted || err != nil {
+		t.Fatalf("ExpireDecision = %v, %v", acted, err)
+	}
+
+	state := e.GetState()
+	seat := state.Seats[0]
+	if !seat.InsuranceDecided || seat.InsuranceAmount != "0" || seat.EvenMoney {
+		t.Errorf("seat insurance = %s (decided %v), want declined", seat.InsuranceAmount, seat.InsuranceDecided)
+	}
+	switch state.Phase {
+	case PhasePlayerTurn:
+		if state.DecisionRemainingMs != int64(rules.ActionTimeoutSec)*1000 {
+			t.Errorf("remaining = %dms, want the action timer started", state.DecisionRemainingMs)
+		}
+	case PhaseComplete:
+	default:
+		t.Errorf("phase = %s after declining insurance", state.Phase)
+	}
+}
+
+func TestDecisionTimersDisabled(t *testing.T) {
+	rules := DefaultRules()
+	rules.ActionTimeoutSec = 0
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	e := NewEngine(rules)
+	e.SetClock(clock)
+	dealUntil(t, e, PhasePlayerTurn)
+
+	if state := e.GetState(); state.DecisionDeadline != nil {
+		t.Fatalf("deadline = %v with timers off", state.DecisionDeadline)
+	}
+	clock.advance(time.Hour)
+	if acted, _ := e.ExpireDecision(); acted {
+		t.Error("ExpireDecision acted with timers off")
+	}
+}
+
+func TestDecisionTimeoutRacesFinishRound(t *testing.T) {
+	rules := DefaultRules()
+	rules.ActionTimeoutSec = 10
+
+	for i := 0; i < 20; i++ {
+		clock := &fakeClock{now: time.Unix(1700000000, 0)}
+		e := NewEngine(rules)
+		e.SetClock(clock)
+		handID := dealUntil(t, e, PhasePlayerTurn)
+		clock.advance(11 * time.Second)
+
+		// The timer stands the seat while a request stands it too and finishes the round
+		var wg sync.WaitGroup
+		errs := make(chan error, 2)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := e.ExpireDecision(); err != nil {
+				errs <- fmt.Errorf("ExpireDecision: %w", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			e.PlayerStand() // Fails if the timer stood first
+			if err := e.FinishRound(); err != nil {
+				errs <- fmt.Errorf("FinishRound: %w", err)
+			}
+		}()
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+
+		resolved := 0
+		events, _ := e.Events(handID, 0)
+		for _, ev := range events {
+			if ev.Type == EventHandResolved {
+				resolved++
+			}
+		}
+		if phase := e.GetState().Phase; phase != PhaseComplete || resolved != 1 {
+			t.Fatalf("phase %s with %d resolutions, want the round finished once", phase, resolved)
+		}
+	}
+}
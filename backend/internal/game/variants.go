@@ -0,0 +1,220 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// VariantName names a blackjack game the engine can deal
+type VariantName string
+
+const (
+	// VariantClassic is standard blackjack
+	VariantClassic VariantName = "classic"
+
+	// VariantSpanish21 is dealt from 48-card decks without the tens
+	// A player 21 always wins, and 21s of five or more cards, 6-7-8 and 7-7-7 pay a bonus
+	VariantSpanish21 VariantName = "spanish21"
+
+	// VariantFreeBet gives free doubles on hard 9 to 11 and free splits on every pair but tens
+	// A dealer 22 pushes every hand still standing except a blackjack
+	VariantFreeBet VariantName = "free_bet"
+
+	// VariantSwitch deals two hands per bet and lets the player swap their second cards
+	// A dealer 22 pushes every hand still standing except a blackjack, and blackjack pays even money
+	VariantSwitch VariantName = "switch"
+)
+
+// Variant is a blackjack game played on the engine's shoe and state machine
+// The engine asks the table's variant wherever the games differ
+type Variant interface {
+	// Name identifies the variant in table rules
+	Name() VariantName
+
+	// Rules returns base with the rules the variant fixes applied on top
+	Rules(base Rules) Rules
+
+	// Ranks returns the ranks dealt in each suit of a deck
+	Ranks() []Rank
+
+	// HandsPerBet is the number of hands a seat's bet is dealt
+	HandsPerBet() int
+
+	// FreeDouble and FreeSplit report whether the house puts up the extra bet for a hand
+	FreeDouble(hand PlayerHand) bool
+	FreeSplit(hand PlayerHand) bool
+
+	// CanSwitch reports whether a seat may swap the second cards of its two hands
+	CanSwitch() bool
+
+	// Settle adjusts the standard settlement of a finished hand
+	// stake is the whole bet on the hand, free bets included
+	Settle(rules Rules, hand PlayerHand, dealerCards []Card, stake decimal.Decimal, s Settlement) Settlement
+}
+
+// variants are the games a table can deal, by name
+var variants = map[VariantName]Variant{
+	VariantClassic:   classic{},
+	VariantSpanish21: spanish21{},
+	VariantFreeBet:   freeBet{},
+	VariantSwitch:    blackjackSwitch{},
+}
+
+// LookupVariant returns a variant by name; an empty name is classic blackjack
+func LookupVariant(name VariantName) (Variant, error) {
+	if name == "" {
+		return classic{}, nil
+	}
+
+	variant, ok := variants[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown variant %q", name)
+	}
+	return variant, nil
+}
+
+// Variants returns every variant a table can deal, classic first
+func Variants() []Variant {
+	return []Variant{classic{}, spanish21{}, freeBet{}, blackjackSwitch{}}
+}
+
+// classic is standard blackjack; the other variants embed it and override what they change
+type classic struct{}
+
+func (classic) Name() VariantName          { return VariantClassic }
+func (classic) Rules(base Rules) Rules     { return base }
+func (classic) Ranks() []Rank              { return Ranks }
+func (classic) HandsPerBet() int           { return 1 }
+func (classic) FreeDouble(PlayerHand) bool { return false }
+func (classic) FreeSplit(PlayerHand) bool  { return false }
+func (classic) CanSwitch() bool            { return false }
+
+func (classic) Settle(_ Rules, _ PlayerHand, _ []Card, _ decimal.Decimal, s Settlement) Settlement {
+	return s
+}
+
+// spanish21 is Spanish 21: no tens in the shoe, late surrender, and every player 21 wins
+type spanish21 struct{ classic }
+
+func (spanish21) Name() VariantName { return VariantSpanish21 }
+
+func (spanish21) Rules(base Rules) Rules {
+	base.BJPayoutBps = 15000
+	base.AllowSurrender = true
+	base.SurrenderMode = SurrenderLate
+	base.DealingMode = DealHoleCard
+	return base
+}
+
+// Ranks leaves out the tens; jacks, queens and kings stay
+func (spanish21) Ranks() []Rank {
+	return []Rank{Ace, Two, Three, Four, Five, Six, Seven, Eight, Nine, Jack, Queen, King}
+}
+
+func (spanish21) Settle(rules Rules, hand PlayerHand, dealerCards []Card, stake decimal.Decimal, s Settlement) Settlement {
+	if total, _ := CalculateHandValue(hand.Cards); total != 21 {
+		return s
+	}
+
+	// A player blackjack beats a dealer blackjack; any other 21 wins, with a bonus unless doubled
+	if hand.natural() {
+		payout, reason := rules.BlackjackPayout(hand.Cards, stake)
+		return Settlement{Outcome: "win", Payout: payout, Reason: reason}
+	}
+	if hand.Doubled {
+		return Settlement{Outcome: "win", Payout: stake, Reason: ReasonPlayer21}
+	}
+	odds, reason := spanish21Bonus(hand.Cards)
+	return Settlement{Outcome: "win", Payout: stake.Mul(odds), Reason: reason}
+}
+
+// spanish21Bonus returns the odds a 21 pays: 3:2 up to 3:1 for 6-7-8, 7-7-7 and five cards or more, otherwise 1:1
+func spanish21Bonus(cards []Card) (decimal.Decimal, OutcomeReason) {
+	if len(cards) == 3 {
+		ranks := map[Rank]int{}
+		suited, spades := true, true
+		for _, c := range cards {
+			ranks[c.Rank]++
+			suited = suited && c.Suit == cards[0].Suit
+			spades = spades && c.Suit == Spades
+		}
+		reason := Reason678
+		if ranks[Seven] == 3 {
+			reason = Reason777
+		}
+		if ranks[Seven] == 3 || (ranks[Six] == 1 && ranks[Seven] == 1 && ranks[Eight] == 1) {
+			switch {
+			case spades:
+				return decimal.NewFromInt(3), reason
+			case suited:
+				return decimal.NewFromInt(2), reason
+			default:
+				return decimal.NewFromFloat(1.5), reason
+			}
+		}
+	}
+
+	switch {
+	case len(cards) >= 7:
+		return decimal.NewFromInt(3), ReasonFiveCard21
+	case len(cards) == 6:
+		return decimal.NewFromInt(2), ReasonFiveCard21
+	case len(cards) == 5:
+		return decimal.NewFromFloat(1.5), ReasonFiveCard21
+	default:
+		return decimal.NewFromInt(1), ReasonPlayer21
+	}
+}
+
+// freeBet is Free Bet blackjack: the house puts up doubles on hard 9 to 11 and splits of every pair but tens
+type freeBet struct{ classic }
+
+func (freeBet) Name() VariantName { return VariantFreeBet }
+
+func (freeBet) Rules(base Rules) Rules {
+	base.BJPayoutBps = 15000
+	base.AllowSurrender = false
+	base.DealingMode = DealHoleCard
+	return base
+}
+
+func (freeBet) FreeDouble(hand PlayerHand) bool {
+	total, soft := CalculateHandValue(hand.Cards)
+	return len(hand.Cards) == 2 && !soft && total >= 9 && total <= 11
+}
+
+func (freeBet) FreeSplit(hand PlayerHand) bool {
+	return len(hand.Cards) == 2 && hand.Cards[0].Rank == hand.Cards[1].Rank && !isTenValue(hand.Cards[0])
+}
+
+func (freeBet) Settle(_ Rules, _ PlayerHand, dealerCards []Card, _ decimal.Decimal, s Settlement) Settlement {
+	return dealer22Pushes(dealerCards, s)
+}
+
+// blackjackSwitch is Blackjack Switch: two hands per bet whose second cards may be swapped
+type blackjackSwitch struct{ classic }
+
+func (blackjackSwitch) Name() VariantName { return VariantSwitch }
+
+func (blackjackSwitch) Rules(base Rules) Rules {
+	base.BJPayoutBps = 10000
+	base.AllowSurrender = false
+	base.DealingMode = DealHoleCard
+	return base
+}
+
+func (blackjackSwitch) HandsPerBet() int { return 2 }
+func (blackjackSwitch) CanSwitch() bool  { return true }
+
+func (blackjackSwitch) Settle(_ Rules, _ PlayerHand, dealerCards []Card, _ decimal.Decimal, s Settlement) Settlement {
+	return dealer22Pushes(dealerCards, s)
+}
+
+// dealer22Pushes turns a win on a dealer bust at 22 into a push; blackjacks and bonus hands still win
+func dealer22Pushes(dealerCards []Card, s Settlement) Settlement {
+	if total, _ := CalculateHandValue(dealerCards); total != 22 || s.Reason != ReasonDealerBust {
+		return s
+	}
+	return Settlement{Outcome: "push", Payout: decimal.Zero, Reason: ReasonDealer22}
+}
@@ -0,0 +1,301 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// variantRules returns the default rules for a variant
+func variantRules(t *testing.T, name VariantName) Rules {
+	t.Helper()
+
+	v, err := LookupVariant(name)
+	if err != nil {
+		t.Fatalf("LookupVariant: %v", err)
+	}
+	rules := v.Rules(DefaultRules())
+	rules.Variant = name
+	if err := rules.Validate(); err != nil {
+		t.Fatalf("%s rules invalid: %v", name, err)
+	}
+	return rules
+}
+
+func TestVariantRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`{"variant": "switch", "bjPayoutBps": 15000, "decks": 6}`))
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+	if rules.BJPayoutBps != 10000 || rules.Decks != 6 {
+		t.Errorf("switch rules = %+v, want blackjack at even money and the table's 6 decks", rules)
+	}
+
+	// Rules that contradict the variant are refused
+	rules.BJPayoutBps = 15000
+	if err := rules.Validate(); err == nil {
+		t.Error("expected a conflict for 3:2 blackjack at a switch table")
+	}
+	if _, err := ParseRules([]byte(`{"variant": "pontoon"}`)); err == nil {
+		t.Error("expected an error for an unknown variant")
+	}
+
+	// Spanish 21 deals 48-card decks, and the cut card sits at the same depth of the smaller shoe
+	rules = variantRules(t, VariantSpanish21)
+	deck := rules.NewDeck()
+	if len(deck.Cards) != rules.Decks*48 {
+		t.Fatalf("spanish 21 shoe has %d cards, want %d", len(deck.Cards), rules.Decks*48)
+	}
+	for _, c := range deck.Cards {
+		if c.Rank == Ten {
+			t.Fatalf("spanish 21 shoe holds %v", c)
+		}
+	}
+	if got, want := rules.ReshuffleAt(), rules.Decks*48*rules.PenetrationBps/10000; got != want {
+		t.Errorf("ReshuffleAt = %d, want %d", got, want)
+	}
+
+	// True counts divide by the decks left of the smaller shoe
+	e := NewEngine(rules)
+	if err := e.StartHand(1, "0xa", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	if err := e.ShuffleAndDeal([]byte("spanish 21 decks")); err != nil {
+		t.Fatalf("ShuffleAndDeal: %v", err)
+	}
+	if state := e.GetState(); state.DecksRemaining != float64(state.CardsRemaining)/48 {
+		t.Errorf("decks remaining = %v with %d cards left, want 48-card decks", state.DecksRemaining, state.CardsRemaining)
+	}
+}
+
+func TestSpanish21Settlement(t *testing.T) {
+	rules := variantRules(t, VariantSpanish21)
+	dealer21 := []Card{card("K", "C"), card("5", "D"), card("6", "H")}
+	dealerBJ := []Card{card("A", "C"), card("K", "D")}
+
+	tests := []struct {
+		name    string
+		hand    PlayerHand
+		dealer  []Card
+		outcome string
+		payout  int64
+		reason  OutcomeReason
+	}{
+		{"21 beats a dealer 21", PlayerHand{Cards: []Card{card("9", "C"), card("5", "D"), card("7", "H")}}, dealer21, "win", 100, ReasonPlayer21},
+		{"blackjack beats a dealer blackjack", PlayerHand{Cards: []Card{card("A", "H"), card("Q", "S")}}, dealerBJ, "win", 150, ReasonBlackjack},
+		{"five-card 21", PlayerHand{Cards: []Card{card("2", "C"), card("3", "D"), card("4", "H"), card("5", "S"), card("7", "C")}}, dealer21, "win", 150, ReasonFiveCard21},
+		{"six-card 21", PlayerHand{Cards: []Card{card("A", "C"), card("2", "D"), card("3", "H"), card("4", "S"), card("5", "C"), card("6", "D")}}, dealer21, "win", 200, ReasonFiveCard21},
+		{"mixed 6-7-8", PlayerHand{Cards: []Card{card("6", "C"), card("7", "D"), card("8", "H")}}, dealer21, "win", 150, Reason678},
+		{"suited 7-7-7", PlayerHand{Cards: []Card{card("7", "H"), card("7", "H"), card("7", "H")}}, dealer21, "win", 200, Reason777},
+		{"spade 6-7-8", PlayerHand{Cards: []Card{card("8", "S"), card("6", "S"), card("7", "S")}}, dealer21, "win", 300, Reason678},
+		{"doubled 21 pays even money", PlayerHand{Cards: []Card{card("6", "S"), card("7", "S"), card("8", "S")}, Doubled: true}, dealer21, "win", 100, ReasonPlayer21},
+		{"20 still loses to 21", PlayerHand{Cards: []Card{card("K", "S"), card("Q", "S")}}, dealer21, "lose", -100, ReasonTotal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settled := rules.EvaluateHand(tt.hand, tt.dealer, decimal.NewFromInt(100), true)
+			if settled.Outcome != tt.outcome || !settled.Payout.Equal(decimal.NewFromInt(tt.payout)) || settled.Reason != tt.reason {
+				t.Errorf("%v vs %v = %+v, want %s/%d (%s)", tt.hand.Cards, tt.dealer, settled, tt.outcome, tt.payout, tt.reason)
+			}
+		})
+	}
+}
+
+func TestFreeBet(t *testing.T) {
+	rules := variantRules(t, VariantFreeBet)
+
+	// A free split of eights, then a free double on the second hand's 11
+	e := newTestEngine(t, rules,
+		card("10", "C"), card("6", "D"), // dealer 16
+		card("8", "H"), card("8", "S"), // player pair
+		card("K", "C"), // first hand: 8+K
+		card("3", "H"), // second hand: 8+3
+		card("9", "D"), // double card: 20
+		card("6", "C"), // dealer draws to 22
+	)
+	if err := e.PlayerSplit(); err != nil {
+		t.Fatalf("PlayerSplit: %v", err)
+	}
+	if hands := e.GetState().Hands; hands[1].Bet != "0" || hands[1].FreeBet != "100" {
+		t.Fatalf("split hand staked %s + free %s, want the house's 100", hands[1].Bet, hands[1].FreeBet)
+	}
+	if err := e.PlayerStand(); err != nil {
+		t.Fatalf("PlayerStand: %v", err)
+	}
+	if err := e.PlayerDouble(); err != nil {
+		t.Fatalf("PlayerDouble: %v", err)
+	}
+	if hands := e.GetState().Hands; hands[1].Bet != "0" || hands[1].FreeBet != "200" {
+		t.Fatalf("doubled hand staked %s + free %s, want the house's 200", hands[1].Bet, hands[1].FreeBet)
+	}
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+
+	// The dealer's 22 pushes both hands
+	state := e.GetState()
+	if total, _ := CalculateHandValue(state.DealerCards); total != 22 {
+		t.Fatalf("dealer = %v, want 22", state.DealerCards)
+	}
+	for i, hand := range state.Hands {
+		if hand.Outcome != "push" || hand.Payout != "0" {
+			t.Errorf("hand %d = %s/%s, want a push against 22", i, hand.Outcome, hand.Payout)
+		}
+	}
+
+	// A free bet wins with the player's bet, and costs the player nothing when it loses
+	free := PlayerHand{Cards: []Card{card("8", "H"), card("K", "S")}, FromSplit: true, FreeBet: "100"}
+	if s := rules.EvaluateHand(free, []Card{card("10", "C"), card("7", "D")}, decimal.Zero, false); s.Outcome != "win" || !s.Payout.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("free hand 18 vs 17 = %s/%s, want a win paying 100", s.Outcome, s.Payout)
+	}
+	if s := rules.EvaluateHand(free, []Card{card("10", "C"), card("9", "D")}, decimal.Zero, false); s.Outcome != "lose" || !s.Payout.IsZero() {
+		t.Errorf("free hand 18 vs 19 = %s/%s, want a loss of nothing", s.Outcome, s.Payout)
+	}
+}
+
+func TestBlackjackSwitch(t *testing.T) {
+	rules := variantRules(t, VariantSwitch)
+
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+
+	// Deal order: first hand, second hand, dealer, twice
+	cards := []Card{
+		card("K", "C"), card("6", "S"), card("10", "H"), // K+5 and 6+A against a ten
+		card("5", "H"), card("A", "D"), card("2", "C"),
+		card("Q", "D"), // dealer draws to 22
+	}
+	e.state.Deck = &Deck{Cards: cards}
+	e.state.DeckInitialized = true
+	e.state.TotalCards = len(cards)
+	if err := e.dealInitialCards(); err != nil {
+		t.Fatalf("dealInitialCards: %v", err)
+	}
+
+	state := e.GetState()
+	if state.Phase != PhasePlayerTurn || len(state.Hands) != 2 || state.Hands[0].Bet != "100" || state.Hands[1].Bet != "100" {
+		t.Fatalf("phase=%s hands=%+v, want two 100 hands to play", state.Phase, state.Hands)
+	}
+
+	// Swapping the second cards makes K+A and 6+5
+	if err := e.PlayerSwitch(); err != nil {
+		t.Fatalf("PlayerSwitch: %v", err)
+	}
+	hands := e.GetState().Hands
+	if hands[0].Cards[1] != card("A", "D") || hands[1].Cards[1] != card("5", "H") || !hands[0].Switched || !hands[1].Switched {
+		t.Fatalf("hands after the switch = %v / %v", hands[0].Cards, hands[1].Cards)
+	}
+	if err := e.PlayerSwitch(); err == nil {
+		t.Fatal("expected a second switch to be refused")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := e.PlayerStand(); err != nil {
+			t.Fatalf("PlayerStand: %v", err)
+		}
+	}
+	if err := e.DealerPlay(); err != nil {
+		t.Fatalf("DealerPlay: %v", err)
+	}
+	if err := e.ResolveHand(); err != nil {
+		t.Fatalf("ResolveHand: %v", err)
+	}
+
+	// A switched 21 is no blackjack, so the dealer's 22 pushes it like any other hand
+	for i, hand := range e.GetState().Hands {
+		if hand.Outcome != "push" || hand.Payout != "0" {
+			t.Errorf("hand %d %v = %s/%s, want a push against 22", i, hand.Cards, hand.Outcome, hand.Payout)
+		}
+	}
+
+	// A dealt blackjack beats the 22 and pays even money
+	natural := PlayerHand{Cards: []Card{card("A", "H"), card("K", "S")}}
+	dealer22 := []Card{card("10", "H"), card("2", "C"), card("Q", "D")}
+	if s := rules.EvaluateHand(natural, dealer22, decimal.NewFromInt(100), true); s.Outcome != "win" || !s.Payout.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("blackjack vs 22 = %s/%s, want a win paying 100", s.Outcome, s.Payout)
+	}
+
+	// Classic tables can't switch
+	e = newTestEngine(t, DefaultRules(), card("10", "C"), card("7", "D"), card("9", "H"), card("5", "S"))
+	if err := e.PlayerSwitch(); err == nil {
+		t.Error("expected switching to be refused at a classic table")
+	}
+}
+
+func TestBlackjackSwitchStakes(t *testing.T) {
+	rules := variantRules(t, VariantSwitch)
+
+	// A bet at a switch table is placed on each of the two hands
+	e := &GlobalEngine{state: newDefaultState(), rules: rules}
+	if err := e.JoinSeat(0, seatPlayer(0)); err != nil {
+		t.Fatalf("JoinSeat: %v", err)
+	}
+	if err := e.OpenBetting(1); err != nil {
+		t.Fatalf("OpenBetting: %v", err)
+	}
+	if err := e.PlaceBet(0, seatPlayer(0), "0xtoken", "100"); err != nil {
+		t.Fatalf("PlaceBet: %v", err)
+	}
+	if s := e.GetState().Seats[0]; s.BetAmount != "100" || s.Wagered != "200" {
+		t.Fatalf("seat bet %s wagered %s, want 100 on each of two hands", s.BetAmount, s.Wagered)
+	}
+
+	// Both hands lose: the seat loses the two wagers it put up, no more
+	e = &GlobalEngine{state: newDefaultState(), rules: rules}
+	if err := e.StartHand(1, "0xplayer", "0xtoken", "100", 100); err != nil {
+		t.Fatalf("StartHand: %v", err)
+	}
+	cards := []Card{
+		card("K", "C"), card("9", "S"), card("10", "H"), // K+7 and 9+8 against 10+9
+		card("7", "H"), card("8", "D"), card("9", "C"),
+	}
+	e.state.Deck = &Deck{Cards: cards}
+	e.state.DeckInitialized = true
+	e.state.TotalCards = len(cards)
+	if err := e.dealInitialCards(); err != nil {
+		t.Fatalf("dealInitialCards: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := e.PlayerStand(); err != nil {
+			t.Fatalf("PlayerStand: %v", err)
+		}
+	}
+	if err := e.FinishRound(); err != nil {
+		t.Fatalf("FinishRound: %v", err)
+	}
+
+	s := e.GetState().Seats[0]
+	wagered, _ := decimal.NewFromString(s.Wagered)
+	net, _ := decimal.NewFromString(s.Payout)
+	if s.Outcome != "lose" || !net.Equal(wagered.Neg()) || wagered.String() != "200" {
+		t.Errorf("seat = %s, payout %s on %s wagered, want both hands lost for -200", s.Outcome, s.Payout, s.Wagered)
+	}
+}
+
+func TestEvaluateOutcomeSettlesVariant(t *testing.T) {
+	bet := decimal.NewFromInt(100)
+	dealer22 := []Card{card("10", "C"), card("6", "D"), card("6", "H")}
+	if s := variantRules(t, VariantFreeBet).EvaluateOutcome([]Card{card("10", "H"), card("8", "S")}, dealer22, bet); s.Outcome != "push" || s.Reason != ReasonDealer22 {
+		t.Errorf("free bet 18 vs dealer 22 = %+v, want a push", s)
+	}
+
+	dealer21 := []Card{card("10", "C"), card("A", "D"), card("K", "H")}
+	spanish := variantRules(t, VariantSpanish21)
+	if s := spanish.EvaluateOutcome([]Card{card("9", "C"), card("5", "D"), card("7", "H")}, dealer21, bet); s.Outcome != "win" || s.Reason != ReasonPlayer21 {
+		t.Errorf("spanish 21 player 21 vs dealer 21 = %+v, want a win", s)
+	}
+	if _, err := ResolveHandWithRules(spanish, 1, "0xplayer", "0xtoken", "100", []byte("seed")); err != nil {
+		t.Errorf("ResolveHandWithRules spanish 21: %v", err)
+	}
+
+	// The resolver deals one hand, and a switch bet plays two
+	if _, err := ResolveHandWithRules(variantRules(t, VariantSwitch), 1, "0xplayer", "0xtoken", "100", []byte("seed")); err == nil {
+		t.Error("expected an error resolving a blackjack switch hand")
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+	"github.com/DanDo385/blackjack/backend/internal/strategy"
+)
+
+// GetAdvice returns the basic-strategy play for the hand the table is waiting on
+func GetAdvice(w http.ResponseWriter, r *http.Request) {
+	handID, _ := strconv.ParseInt(r.URL.Query().Get("handId"), 10, 64)
+	engine, err := engineFor(r, handID)
+	if err != nil {
+		log.Printf("[GetAdvice] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Table not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	state := engine.GetState()
+	advice, err := strategy.NewAdvisor(engine.Rules()).AdviseState(state)
+	if err != nil {
+		log.Printf("[GetAdvice] No advice for hand %d: %v", state.HandID, err)
+		status := http.StatusConflict
+		if errors.Is(err, strategy.ErrUnsupportedVariant) {
+			status = http.StatusNotImplemented
+		}
+		http.Error(w, fmt.Sprintf("No decision to advise: %v", err), status)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"handId":     state.HandID,
+		"phase":      state.Phase,
+		"activeSeat": state.ActiveSeat,
+		"activeHand": state.ActiveHand,
+		"action":     advice.Action,
+		"code":       advice.Code,
+		"reason":     advice.Reason,
+		"total":      advice.Total,
+		"soft":       advice.Soft,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetHint returns the index play for the hand the table is waiting on, for count training
+// The deviation sets come from ?sets= (default illustrious18,fab4) and are played off the Hi-Lo true count
+func GetHint(w http.ResponseWriter, r *http.Request) {
+	handID, _ := strconv.ParseInt(r.URL.Query().Get("handId"), 10, 64)
+	engine, err := engineFor(r, handID)
+	if err != nil {
+		log.Printf("[GetHint] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Table not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	sets := r.URL.Query().Get("sets")
+	if sets == "" {
+		sets = "illustrious18,fab4"
+	}
+	deviations, err := strategy.LookupDeviations(sets)
+	if err != nil {
+		log.Printf("[GetHint] Error loading deviations: %v", err)
+		http.Error(w, fmt.Sprintf("Invalid deviation sets: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	state := engine.GetState()
+	count := state.Counts[game.CountHiLo]
+	advisor := strategy.NewAdvisor(engine.Rules())
+	advice, deviation, err := strategy.NewIndexPlayer(advisor, deviations).AdviseState(state, count.TrueCount)
+	if err != nil {
+		log.Printf("[GetHint] No hint for hand %d: %v", state.HandID, err)
+		status := http.StatusConflict
+		if errors.Is(err, strategy.ErrUnsupportedVariant) {
+			status = http.StatusNotImplemented
+		}
+		http.Error(w, fmt.Sprintf("No decision to advise: %v", err), status)
+		return
+	}
+	basic, _ := advisor.AdviseState(state) // Cannot fail once the index play was found
+
+	resp := map[string]interface{}{
+		"handId":       state.HandID,
+		"phase":        state.Phase,
+		"activeSeat":   state.ActiveSeat,
+		"activeHand":   state.ActiveHand,
+		"runningCount": count.RunningCount,
+		"trueCount":    count.TrueCount,
+		"action":       advice.Action,
+		"reason":       advice.Reason,
+		"basic":        basic,
+		"deviation":    nil,
+	}
+	if deviation != nil {
+		resp["deviation"] = map[string]interface{}{
+			"play":  deviation.String(),
+			"index": deviation.Index,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -7,17 +7,11 @@ import (
 	"math/big"
 	"math/rand"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/DanDo385/blackjack/backend/internal/game"
 )
 
-// Card represents a playing card
-type Card struct {
-	Suit  string `json:"suit"`  // C, D, H, S
-	Value string `json:"value"` // A, 2-10, J, Q, K
-}
-
 // GameState represents the current game state
 type GameState struct {
 	HandID     int      `json:"handId"`
@@ -33,51 +27,30 @@ type ActionRequest struct {
 	Action       string  `json:"action"`
 	BuyInsurance bool    `json:"buyInsurance,omitempty"`
 	Amount       float64 `json:"amount,omitempty"`
+	Seat         *int    `json:"seat,omitempty"` // Acting seat, defaults to the seat whose turn it is
 }
 
-var suits = []string{"C", "D", "H", "S"}
-var values = []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"}
-
-// cardToImagePath converts a card to its image path
-func cardToImagePath(value, suit string) string {
-	if value == "J" {
-		// Handle jokers/special cards
-		return fmt.Sprintf("/cards/J-%s.png", suit)
+// seat returns the seat the action is for; the engine refuses it unless the caller holds that seat
+func (req ActionRequest) seat(engine *game.GlobalEngine) int {
+	if req.Seat != nil {
+		return *req.Seat
 	}
-	return fmt.Sprintf("/cards/%s-%s.png", value, suit)
+	return engine.GetState().ActiveSeat
 }
 
-// dealCard deals a random card
-func dealCard() Card {
-	return Card{
-		Suit:  suits[rand.Intn(len(suits))],
-		Value: values[rand.Intn(len(values))],
-	}
+// SideBetRequest represents a side bet placed with the main bet
+type SideBetRequest struct {
+	Kind   string  `json:"kind"` // perfect_pairs, 21+3, lucky_ladies
+	Amount float64 `json:"amount"`
 }
 
-// dealInitialHands deals initial cards for dealer and player
-func dealInitialHands() ([]string, []string) {
-	dealerCards := []Card{dealCard(), dealCard()}
-	playerCards := []Card{dealCard(), dealCard()}
-
-	dealerHand := make([]string, len(dealerCards))
-	playerHand := make([]string, len(playerCards))
-
-	for i, card := range dealerCards {
-		if i == 0 {
-			// First dealer card is face-up
-			dealerHand[i] = cardToImagePath(card.Value, card.Suit)
-		} else {
-			// Second dealer card is face-down
-			dealerHand[i] = "/cards/back.png"
-		}
-	}
-
-	for i, card := range playerCards {
-		playerHand[i] = cardToImagePath(card.Value, card.Suit)
+// sideBetWagers converts requested side bets to wei wagers
+func sideBetWagers(reqs []SideBetRequest) []game.SideBetWager {
+	wagers := make([]game.SideBetWager, len(reqs))
+	for i, req := range reqs {
+		wagers[i] = game.SideBetWager{Kind: game.SideBetKind(req.Kind), Amount: toWei(req.Amount).String()}
 	}
-
-	return dealerHand, playerHand
+	return wagers
 }
 
 // ErrorResponse represents a structured error response
@@ -107,6 +80,44 @@ func writeError(w http.ResponseWriter, status int, code, message string, details
 	}
 }
 
+// toWei converts a token amount to wei (assuming 18 decimals for most tokens)
+func toWei(amount float64) *big.Int {
+	amountFloat := big.NewFloat(amount)
+	amountFloat.Mul(amountFloat, big.NewFloat(1e18))
+	amountWei, _ := amountFloat.Int(nil)
+	return amountWei
+}
+
+// tableID returns the table named by the request's X-Table-ID header or ?table= query
+func tableID(r *http.Request) string {
+	if id := r.Header.Get("X-Table-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("table")
+}
+
+// engineFor returns the engine for the request's table
+// A named table must exist; without one a non-zero hand ID must find the table
+// playing that hand, and only a request with neither gets the default table
+func engineFor(r *http.Request, handID int64) (*game.GlobalEngine, error) {
+	tables := game.GetTableManager()
+	if id := tableID(r); id != "" {
+		engine, ok := tables.Get(id)
+		if !ok {
+			return nil, fmt.Errorf("table %s not found", id)
+		}
+		return engine, nil
+	}
+
+	if handID == 0 {
+		return tables.Default(), nil
+	}
+	if _, engine, ok := tables.FindHand(handID); ok {
+		return engine, nil
+	}
+	return nil, fmt.Errorf("no table is playing hand %d", handID)
+}
+
 // logError logs a structured error with context
 func logError(route, operation string, err error, details map[string]interface{}) {
 	log.Printf("[%s] ERROR %s: %v", route, operation, err)
@@ -116,26 +127,16 @@ func logError(route, operation string, err error, details map[string]interface{}
 }
 
 func GetEngineState(w http.ResponseWriter, r *http.Request) {
-	// Recover from any panics
-	defer func() {
-		if rec := recover(); rec != nil {
-			logError("GetEngineState", "panic recovered", fmt.Errorf("%v", rec), map[string]interface{}{
-				"status": http.StatusInternalServerError,
-				"route":  "/api/engine/state",
-			})
-			writeError(w, http.StatusInternalServerError, "PANIC_ERROR", "Internal server error occurred", map[string]interface{}{
-				"panic": fmt.Sprintf("%v", rec),
-			})
-		}
-	}()
-
 	log.Printf("[GetEngineState] Incoming request from %s %s", r.Method, r.RemoteAddr)
 
-	// Get the global engine instance (always returns valid state)
-	engine := game.GetEngine()
-	if engine == nil {
-		logError("GetEngineState", "engine nil", fmt.Errorf("engine instance is nil"), nil)
-		writeError(w, http.StatusInternalServerError, "ENGINE_ERROR", "Game engine not available", nil)
+	// Get the table's engine (a known table always returns valid state)
+	handID, _ := strconv.ParseInt(r.URL.Query().Get("handId"), 10, 64)
+	engine, err := engineFor(r, handID)
+	if err != nil {
+		logError("GetEngineState", "find table", err, nil)
+		writeError(w, http.StatusNotFound, "TABLE_NOT_FOUND", "Table not found", map[string]interface{}{
+			"error": err.Error(),
+		})
 		return
 	}
 
@@ -159,23 +160,46 @@ func GetEngineState(w http.ResponseWriter, r *http.Request) {
 		// Game state
 		"handId":         state.HandID,
 		"deckInitialized": state.DeckInitialized,
+		"shoeId":         state.ShoeID,
 		"cardsDealt":     state.CardsDealt,
+		"cardsRemaining": state.CardsRemaining,
 		"totalCards":     state.TotalCards,
+		"reshuffleAt":    state.ReshuffleAt,
+		"shuffleVersion": state.ShuffleVersion,
+
+		// Seats
+		"seats":          state.Seats,
+		"activeSeat":     state.ActiveSeat,
 
 		// Hands (only if cards exist)
 		"dealerHand":     state.DealerHand,
 		"playerHand":     state.PlayerHand,
+		"playerHands":    state.Hands,
+		"activeHand":     state.ActiveHand,
+
+		// Side bets
+		"sideBets":       state.SideBets,
+
+		// Insurance
+		"insuranceAmount":  state.InsuranceAmount,
+		"insuranceOutcome": state.InsuranceOutcome,
+		"insurancePayout":  state.InsurancePayout,
+		"evenMoney":        state.EvenMoney,
 
 		// Outcome (only if complete)
 		"outcome":        state.Outcome,
 		"payout":         state.Payout,
 
 		// Counting metrics
+		"countingSystem": state.CountingSystem,
+		"counts":         state.Counts,
 		"trueCount":      state.TrueCount,
 		"shoePct":        state.ShoePct,
 		"runningCount":   state.RunningCount,
+		"decksRemaining": state.DecksRemaining,
 
 		// Table parameters
+		"rules":          engine.Rules(),
 		"anchor":         state.Anchor,
 		"spreadNum":      state.SpreadNum,
 		"lastBet":        state.LastBet,
@@ -208,26 +232,14 @@ func GetEngineState(w http.ResponseWriter, r *http.Request) {
 }
 
 func PostBet(w http.ResponseWriter, r *http.Request) {
-	// Recover from any panics
-	defer func() {
-		if rec := recover(); rec != nil {
-			logError("PostBet", "panic recovered", fmt.Errorf("%v", rec), map[string]interface{}{
-				"status": http.StatusInternalServerError,
-				"route":  "/api/engine/bet",
-			})
-			writeError(w, http.StatusInternalServerError, "PANIC_ERROR", "Internal server error occurred during bet placement", map[string]interface{}{
-				"panic": fmt.Sprintf("%v", rec),
-			})
-		}
-	}()
-
 	log.Printf("[PostBet] Incoming bet request from %s", r.RemoteAddr)
 
 	var req struct {
-		Amount  float64 `json:"amount"`
-		Token   string  `json:"token"`
-		USDCRef *string `json:"usdcRef,omitempty"`
-		QuoteID *string `json:"quoteId,omitempty"`
+		Amount   float64          `json:"amount"`
+		Token    string           `json:"token"`
+		SideBets []SideBetRequest `json:"sideBets,omitempty"`
+		USDCRef  *string          `json:"usdcRef,omitempty"`
+		QuoteID  *string          `json:"quoteId,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -238,7 +250,17 @@ func PostBet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("[PostBet] Bet amount: %.2f, token: %s", req.Amount, req.Token)
+	log.Printf("[PostBet] Bet amount: %.2f, token: %s, side bets: %d", req.Amount, req.Token, len(req.SideBets))
+
+	// Side bets are checked up front to reject a bad one as a bad request; StartHand places them with the bet
+	sideBets := sideBetWagers(req.SideBets)
+	if err := game.ValidateSideBets(sideBets); err != nil {
+		logError("PostBet", "validate side bets", err, nil)
+		writeError(w, http.StatusBadRequest, "SIDE_BET_ERROR", "Invalid side bet", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// Get player address from request (in production, authenticate/authorize)
 	playerAddr := r.Header.Get("X-Player-Address")
@@ -247,31 +269,31 @@ func PostBet(w http.ResponseWriter, r *http.Request) {
 		log.Printf("[PostBet] No player address provided, using demo address")
 	}
 
-	// Convert amount to big.Int (assuming 18 decimals for most tokens)
-	amountWei := new(big.Int)
-	amountFloat := big.NewFloat(req.Amount)
-	amountFloat.Mul(amountFloat, big.NewFloat(1e18))
-	amountWei, _ = amountFloat.Int(nil)
+	// Convert amount to big.Int
+	amountWei := toWei(req.Amount)
 
 	tokenAddr := req.Token
 	if tokenAddr == "" {
 		tokenAddr = "0x0000000000000000000000000000000000000000" // Demo fallback
 	}
 
-	// Generate hand ID
-	handID := time.Now().Unix()
+	// Generate hand ID (unique across tables)
+	handID := game.GetTableManager().NextHandID()
 
-	// Get engine and start hand
-	engine := game.GetEngine()
-	if engine == nil {
-		logError("PostBet", "get engine", fmt.Errorf("engine is nil"), nil)
-		writeError(w, http.StatusInternalServerError, "ENGINE_ERROR", "Game engine not available", nil)
+	// Get the table's engine and start hand
+	table, engine, err := namedEngine(r)
+	if err != nil {
+		logError("PostBet", "find table", err, nil)
+		writeError(w, http.StatusNotFound, "TABLE_NOT_FOUND", "Table not found", map[string]interface{}{
+			"error": err.Error(),
+		})
 		return
 	}
 
-	if err := engine.StartHand(handID, playerAddr, tokenAddr, amountWei.String(), req.Amount); err != nil {
+	if err := engine.StartHand(handID, playerAddr, tokenAddr, amountWei.String(), req.Amount, sideBets...); err != nil {
 		logError("PostBet", "start hand", err, map[string]interface{}{
 			"handId": handID,
+			"table":  table,
 			"player": playerAddr,
 		})
 		writeError(w, http.StatusInternalServerError, "START_HAND_ERROR", "Failed to start hand", map[string]interface{}{
@@ -280,14 +302,11 @@ func PostBet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("[PostBet] Hand started: handID=%d, phase=SHUFFLING", handID)
-
-	// Generate random seed for shuffle (in production, this would come from VRF)
-	seed := make([]byte, 32)
-	rand.Read(seed)
+	log.Printf("[PostBet] Hand started: handID=%d, table=%s, phase=SHUFFLING", handID, table)
 
-	// Shuffle and deal
-	if err := engine.ShuffleAndDeal(seed); err != nil {
+	// Shuffle under the published commitment, so the shoe can be verified once it is retired
+	// A failed deal voids the round, returning the table to WAITING_FOR_DEAL
+	if err := engine.ShuffleAndDealFair(); err != nil {
 		logError("PostBet", "shuffle and deal", err, map[string]interface{}{
 			"handId": handID,
 		})
@@ -297,6 +316,17 @@ func PostBet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A blackjack on the deal resolves immediately
+	if err := engine.FinishRound(); err != nil {
+		logError("PostBet", "finish round", err, map[string]interface{}{
+			"handId": handID,
+		})
+		writeError(w, http.StatusInternalServerError, "RESOLVE_ERROR", "Failed to resolve blackjack", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Get current state
 	state := engine.GetState()
 	if state == nil {
@@ -310,15 +340,30 @@ func PostBet(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[PostBet] Cards dealt: phase=%s, dealer=%v, player=%v",
 		state.Phase, state.DealerHand, state.PlayerHand)
 
+	message := "Cards dealt - player's turn"
+	switch state.Phase {
+	case game.PhaseInsuranceOffer:
+		message = "Cards dealt - " + state.PhaseDetail
+	case game.PhaseComplete:
+		message = fmt.Sprintf("Hand complete - %s", state.Outcome)
+	}
+
 	// Return state with dealt cards
 	resp := map[string]interface{}{
 		"handId":      handID,
+		"tableId":     table,
 		"status":      "dealt",
 		"phase":       state.Phase,
 		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
 		"dealerHand":  state.DealerHand,
 		"playerHand":  state.PlayerHand,
-		"message":     "Cards dealt - player's turn",
+		"wagered":     state.Wagered, // The bet on each hand dealt (twice the bet at a switch table)
+		"sideBets":    state.SideBets,
+		"outcome":     state.Outcome,
+		"payout":      state.Payout,
+		"message":     message,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -338,7 +383,8 @@ func PostBet(w http.ResponseWriter, r *http.Request) {
 // PostResolve resolves a hand using stored VRF seed
 func PostResolve(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		HandID int64 `json:"handId"`
+		HandID   int64            `json:"handId"`
+		SideBets []SideBetRequest `json:"sideBets,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -355,8 +401,14 @@ func PostResolve(w http.ResponseWriter, r *http.Request) {
 	tokenAddr := "0x0000000000000000000000000000000000000000"
 	amountStr := "1000000000000000000" // 1 token
 
-	// Resolve hand using game engine
-	result, err := game.ResolveHand(req.HandID, playerAddr, tokenAddr, amountStr, seed)
+	engine, err := engineFor(r, req.HandID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+
+	// Resolve hand using game engine under the table's rules
+	result, err := game.ResolveHandWithRules(engine.Rules(), req.HandID, playerAddr, tokenAddr, amountStr, seed, sideBetWagers(req.SideBets)...)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to resolve hand: %v", err), http.StatusInternalServerError)
 		return
@@ -366,7 +418,9 @@ func PostResolve(w http.ResponseWriter, r *http.Request) {
 	resp := map[string]interface{}{
 		"handId":       result.HandID,
 		"outcome":      result.Outcome,
+		"reason":       result.Reason,
 		"payout":       result.Payout.String(),
+		"sideBets":     result.SideBets,
 		"dealerHand":   result.DealerCards,
 		"playerHand":   result.PlayerCards,
 		"feeLink":      result.FeeLink.String(),
@@ -390,8 +444,13 @@ func PostHit(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[PostHit] HandID: %d", req.HandID)
 
 	// Get engine and execute hit
-	engine := game.GetEngine()
-	if err := engine.PlayerHit(); err != nil {
+	engine, err := engineFor(r, int64(req.HandID))
+	if err != nil {
+		log.Printf("[PostHit] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.SeatHit(req.seat(engine), playerAddress(r)); err != nil {
 		log.Printf("[PostHit] Error executing hit: %v", err)
 		http.Error(w, fmt.Sprintf("Failed to hit: %v", err), http.StatusBadRequest)
 		return
@@ -402,20 +461,23 @@ func PostHit(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("[PostHit] Card dealt: phase=%s, playerHand=%v", state.Phase, state.PlayerHand)
 
-	// Check if player busted
-	if state.Phase == game.PhaseResolution {
-		// Auto-resolve
-		if err := engine.ResolveHand(); err != nil {
-			log.Printf("[PostHit] Error resolving hand: %v", err)
-		}
-		state = engine.GetState()
+	// Player's turn may be over after a bust (dealer still plays if a split hand stands)
+	if err := engine.FinishRound(); err != nil {
+		log.Printf("[PostHit] Error finishing round: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to finish round: %v", err), http.StatusInternalServerError)
+		return
 	}
+	state = engine.GetState()
 
 	resp := map[string]interface{}{
 		"handId":      req.HandID,
 		"phase":       state.Phase,
 		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
 		"playerHand":  state.PlayerHand,
+		"playerHands": state.Hands,
+		"activeHand":  state.ActiveHand,
 		"dealerHand":  state.DealerHand,
 		"outcome":     state.Outcome,
 		"payout":      state.Payout,
@@ -439,42 +501,49 @@ func PostStand(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[PostStand] HandID: %d", req.HandID)
 
 	// Get engine and execute stand
-	engine := game.GetEngine()
-	if err := engine.PlayerStand(); err != nil {
-		log.Printf("[PostStand] Error executing stand: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to stand: %v", err), http.StatusBadRequest)
+	engine, err := engineFor(r, int64(req.HandID))
+	if err != nil {
+		log.Printf("[PostStand] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
 		return
 	}
-
-	// Execute dealer play
-	if err := engine.DealerPlay(); err != nil {
-		log.Printf("[PostStand] Error executing dealer play: %v", err)
-		http.Error(w, fmt.Sprintf("Failed dealer play: %v", err), http.StatusInternalServerError)
+	if err := engine.SeatStand(req.seat(engine), playerAddress(r)); err != nil {
+		log.Printf("[PostStand] Error executing stand: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to stand: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	// Resolve hand
-	if err := engine.ResolveHand(); err != nil {
-		log.Printf("[PostStand] Error resolving hand: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to resolve: %v", err), http.StatusInternalServerError)
+	// Dealer plays and the hand resolves once the last split hand stands
+	if err := engine.FinishRound(); err != nil {
+		log.Printf("[PostStand] Error finishing round: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to finish round: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Get final state
 	state := engine.GetState()
 
-	log.Printf("[PostStand] Hand complete: phase=%s, outcome=%s, payout=%s",
+	log.Printf("[PostStand] Stand complete: phase=%s, outcome=%s, payout=%s",
 		state.Phase, state.Outcome, state.Payout)
 
+	message := fmt.Sprintf("Hand complete - %s", state.Outcome)
+	if state.Phase == game.PhasePlayerTurn {
+		message = fmt.Sprintf("Playing hand %d of %d", state.ActiveHand+1, len(state.Hands))
+	}
+
 	resp := map[string]interface{}{
 		"handId":      req.HandID,
 		"phase":       state.Phase,
 		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
 		"dealerHand":  state.DealerHand,
 		"playerHand":  state.PlayerHand,
+		"playerHands": state.Hands,
+		"activeHand":  state.ActiveHand,
 		"outcome":     state.Outcome,
 		"payout":      state.Payout,
-		"message":     fmt.Sprintf("Hand complete - %s", state.Outcome),
+		"message":     message,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -482,16 +551,98 @@ func PostStand(w http.ResponseWriter, r *http.Request) {
 }
 
 func PostSplit(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[PostSplit] Incoming split request")
+
 	var req ActionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostSplit] Error decoding request: %v", err)
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	// Split the hand (create two hands)
-	resp := GameState{
-		HandID:  req.HandID,
-		Message: "Hand split",
+	log.Printf("[PostSplit] HandID: %d", req.HandID)
+
+	// Get engine and execute split
+	engine, err := engineFor(r, int64(req.HandID))
+	if err != nil {
+		log.Printf("[PostSplit] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.SeatSplit(req.seat(engine), playerAddress(r)); err != nil {
+		log.Printf("[PostSplit] Error executing split: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to split: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Split aces may finish the player's turn immediately
+	if err := engine.FinishRound(); err != nil {
+		log.Printf("[PostSplit] Error finishing round: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to finish round: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	state := engine.GetState()
+
+	log.Printf("[PostSplit] Hand split: phase=%s, hands=%d, active=%d", state.Phase, len(state.Hands), state.ActiveHand)
+
+	resp := map[string]interface{}{
+		"handId":      req.HandID,
+		"phase":       state.Phase,
+		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
+		"dealerHand":  state.DealerHand,
+		"playerHand":  state.PlayerHand,
+		"playerHands": state.Hands,
+		"activeHand":  state.ActiveHand,
+		"outcome":     state.Outcome,
+		"payout":      state.Payout,
+		"message":     "Hand split",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PostSwitch swaps the second cards of the seat's two hands (Blackjack Switch tables)
+func PostSwitch(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[PostSwitch] Incoming switch request")
+
+	var req ActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostSwitch] Error decoding request: %v", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	engine, err := engineFor(r, int64(req.HandID))
+	if err != nil {
+		log.Printf("[PostSwitch] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.SeatSwitch(req.seat(engine), playerAddress(r)); err != nil {
+		log.Printf("[PostSwitch] Error executing switch: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to switch: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	state := engine.GetState()
+
+	log.Printf("[PostSwitch] Hands switched: phase=%s, hands=%d", state.Phase, len(state.Hands))
+
+	resp := map[string]interface{}{
+		"handId":      req.HandID,
+		"phase":       state.Phase,
+		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
+		"dealerHand":  state.DealerHand,
+		"playerHand":  state.PlayerHand,
+		"playerHands": state.Hands,
+		"activeHand":  state.ActiveHand,
+		"message":     "Hands switched",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -499,20 +650,53 @@ func PostSplit(w http.ResponseWriter, r *http.Request) {
 }
 
 func PostDouble(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[PostDouble] Incoming double request")
+
 	var req ActionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostDouble] Error decoding request: %v", err)
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	// Double down - deal one card and stand
-	newCard := dealCard()
-	cardPath := cardToImagePath(newCard.Value, newCard.Suit)
+	log.Printf("[PostDouble] HandID: %d", req.HandID)
+
+	// Get engine and execute double (one card, then stand)
+	engine, err := engineFor(r, int64(req.HandID))
+	if err != nil {
+		log.Printf("[PostDouble] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.SeatDouble(req.seat(engine), playerAddress(r)); err != nil {
+		log.Printf("[PostDouble] Error executing double: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to double: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := engine.FinishRound(); err != nil {
+		log.Printf("[PostDouble] Error finishing round: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to finish round: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	state := engine.GetState()
+
+	log.Printf("[PostDouble] Doubled down: phase=%s, outcome=%s, payout=%s", state.Phase, state.Outcome, state.Payout)
 
-	resp := GameState{
-		HandID:     req.HandID,
-		PlayerHand: []string{cardPath},
-		Message:    "Doubled down",
+	resp := map[string]interface{}{
+		"handId":      req.HandID,
+		"phase":       state.Phase,
+		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
+		"dealerHand":  state.DealerHand,
+		"playerHand":  state.PlayerHand,
+		"playerHands": state.Hands,
+		"activeHand":  state.ActiveHand,
+		"outcome":     state.Outcome,
+		"payout":      state.Payout,
+		"message":     "Doubled down",
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -520,27 +704,165 @@ func PostDouble(w http.ResponseWriter, r *http.Request) {
 }
 
 func PostInsurance(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[PostInsurance] Incoming insurance request")
+
 	var req ActionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostInsurance] Error decoding request: %v", err)
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	if req.BuyInsurance {
-		resp := GameState{
-			HandID:  req.HandID,
-			Message: "Insurance purchased",
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
-	} else {
-		resp := GameState{
-			HandID:  req.HandID,
-			Message: "Insurance declined",
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(resp)
+	log.Printf("[PostInsurance] HandID: %d, buy: %v, amount: %.2f", req.HandID, req.BuyInsurance, req.Amount)
+
+	// Zero amount insures for the maximum (half the bet)
+	amount := ""
+	if req.Amount > 0 {
+		amount = toWei(req.Amount).String()
+	}
+
+	engine, err := engineFor(r, int64(req.HandID))
+	if err != nil {
+		log.Printf("[PostInsurance] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.SeatInsurance(req.seat(engine), playerAddress(r), req.BuyInsurance, amount); err != nil {
+		log.Printf("[PostInsurance] Error settling insurance: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to settle insurance: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Dealer blackjack or even money ends the hand
+	if err := engine.FinishRound(); err != nil {
+		log.Printf("[PostInsurance] Error finishing round: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to finish round: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	state := engine.GetState()
+
+	log.Printf("[PostInsurance] Insurance %s: phase=%s, insurancePayout=%s",
+		state.InsuranceOutcome, state.Phase, state.InsurancePayout)
+
+	message := "Insurance declined"
+	switch state.InsuranceOutcome {
+	case "even_money":
+		message = "Even money paid"
+	case "win":
+		message = "Insurance pays - dealer has blackjack"
+	case "lose":
+		message = "Insurance lost - dealer has no blackjack"
+	}
+
+	resp := map[string]interface{}{
+		"handId":           req.HandID,
+		"phase":            state.Phase,
+		"phaseDetail":      state.PhaseDetail,
+		"activeSeat":       state.ActiveSeat,
+		"seats":            state.Seats,
+		"dealerHand":       state.DealerHand,
+		"playerHand":       state.PlayerHand,
+		"insuranceAmount":  state.InsuranceAmount,
+		"insuranceOutcome": state.InsuranceOutcome,
+		"insurancePayout":  state.InsurancePayout,
+		"outcome":          state.Outcome,
+		"payout":           state.Payout,
+		"message":          message,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func PostSurrender(w http.ResponseWriter, r *http.Request) {
+	log.Printf("[PostSurrender] Incoming surrender request")
+
+	var req ActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostSurrender] Error decoding request: %v", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("[PostSurrender] HandID: %d", req.HandID)
+
+	engine, err := engineFor(r, int64(req.HandID))
+	if err != nil {
+		log.Printf("[PostSurrender] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.SeatSurrender(req.seat(engine), playerAddress(r)); err != nil {
+		log.Printf("[PostSurrender] Error executing surrender: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to surrender: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := engine.FinishRound(); err != nil {
+		log.Printf("[PostSurrender] Error finishing round: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to finish round: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	state := engine.GetState()
+
+	log.Printf("[PostSurrender] Hand surrendered: phase=%s, payout=%s", state.Phase, state.Payout)
+
+	resp := map[string]interface{}{
+		"handId":      req.HandID,
+		"phase":       state.Phase,
+		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
+		"dealerHand":  state.DealerHand,
+		"playerHand":  state.PlayerHand,
+		"outcome":     state.Outcome,
+		"payout":      state.Payout,
+		"message":     "Hand surrendered - half the bet returned",
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PostCountingSystem selects the counting system shown as runningCount/trueCount
+func PostCountingSystem(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		System string `json:"system"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostCountingSystem] Error decoding request: %v", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	engine, err := engineFor(r, 0)
+	if err != nil {
+		log.Printf("[PostCountingSystem] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.SetCountingSystem(game.CountingSystem(req.System)); err != nil {
+		log.Printf("[PostCountingSystem] Error selecting system: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to select counting system: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	state := engine.GetState()
+
+	log.Printf("[PostCountingSystem] Counting with %s: running=%.1f, true=%.2f", state.CountingSystem, state.RunningCount, state.TrueCount)
+
+	resp := map[string]interface{}{
+		"countingSystem": state.CountingSystem,
+		"counts":         state.Counts,
+		"runningCount":   state.RunningCount,
+		"trueCount":      state.TrueCount,
+		"decksRemaining": state.DecksRemaining,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
 }
 
 func PostCashOut(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// streamKeepAlive is how often an idle event stream sends a comment so proxies keep it open
+const streamKeepAlive = 15 * time.Second
+
+// handEvents resolves the table and hand named by ?handId= (the table's current hand by default)
+func handEvents(r *http.Request) (*game.GlobalEngine, int64, error) {
+	handID, _ := strconv.ParseInt(r.URL.Query().Get("handId"), 10, 64)
+	engine, err := engineFor(r, handID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if handID == 0 {
+		handID = engine.HandID()
+	}
+	return engine, handID, nil
+}
+
+// GetHandEvents returns a hand's journaled events and the state folded from them
+// ?after= skips events up to that sequence number
+func GetHandEvents(w http.ResponseWriter, r *http.Request) {
+	engine, handID, err := handEvents(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Table not found: %v", err), http.StatusNotFound)
+		return
+	}
+	after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+
+	events, _ := engine.Events(handID, after)
+	if len(events) == 0 && after == 0 {
+		http.Error(w, fmt.Sprintf("No events for hand %d", handID), http.StatusNotFound)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"handId": handID,
+		"events": events,
+	}
+	if after == 0 {
+		state, err := game.FoldEvents(events)
+		if err != nil {
+			log.Printf("[GetHandEvents] Hand %d journal does not fold: %v", handID, err)
+			http.Error(w, fmt.Sprintf("Failed to fold events: %v", err), http.StatusInternalServerError)
+			return
+		}
+		resp["state"] = state
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetHandEventStream streams a hand's events as server-sent events until the hand completes
+// Events already journaled are sent first; ?after= (or Last-Event-ID on reconnect) skips those already seen
+func GetHandEventStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	engine, handID, err := handEvents(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Table not found: %v", err), http.StatusNotFound)
+		return
+	}
+	after, _ := strconv.ParseInt(r.URL.Query().Get("after"), 10, 64)
+	if id, err := strconv.ParseInt(r.Header.Get("Last-Event-ID"), 10, 64); err == nil {
+		after = id
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		events, next := engine.Events(handID, after)
+		for _, ev := range events {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				log.Printf("[GetHandEventStream] Error encoding event %d: %v", ev.Seq, err)
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, ev.Type, data)
+			after = ev.Seq
+
+			if ev.Phase == game.PhaseComplete {
+				fmt.Fprint(w, "event: end\ndata: {}\n\n")
+				flusher.Flush()
+				return
+			}
+		}
+		if len(events) == 0 && engine.HandID() > handID {
+			// The table has moved on, so nothing more will be journaled for this hand
+			fmt.Fprint(w, "event: end\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-next:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
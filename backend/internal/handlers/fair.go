@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+	"github.com/DanDo385/blackjack/backend/internal/random"
+)
+
+// GetFairSeeds returns the table's provably fair seeds: the commitment the next shoe will be
+// shuffled under, the commitment of the shoe in play, and the revealed seeds of retired shoes
+func GetFairSeeds(w http.ResponseWriter, r *http.Request) {
+	engine, err := engineFor(r, 0)
+	if err != nil {
+		// A closed table commits to nothing more, but the seeds of its shoes stay published
+		if reveals, ok := game.GetTableManager().ClosedReveals(tableID(r)); ok {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"closed": true, "reveals": reveals})
+			return
+		}
+		http.Error(w, fmt.Sprintf("Table not found: %v", err), http.StatusNotFound)
+		return
+	}
+
+	next, err := engine.NextCommitment()
+	if err != nil {
+		log.Printf("[GetFairSeeds] Error committing to a server seed: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to commit to a server seed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"next":    next,
+		"current": engine.GetState().Commitment,
+		"reveals": engine.Reveals(),
+	})
+}
+
+// PostFairRotate sets the seated player's share of the next shoe's client seed and optionally commits to a new server seed
+// Only the player holding the seat may rotate; the shoe in play is unaffected and its server seed is revealed when it is retired
+func PostFairRotate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Seat             int    `json:"seat"`
+		ClientSeed       string `json:"clientSeed"`
+		RotateServerSeed bool   `json:"rotateServerSeed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.ClientSeed == "" && !req.RotateServerSeed {
+		http.Error(w, "clientSeed or rotateServerSeed required", http.StatusBadRequest)
+		return
+	}
+
+	engine, err := engineFor(r, 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Table not found: %v", err), http.StatusNotFound)
+		return
+	}
+	next, err := engine.RotateSeeds(req.Seat, playerAddress(r), req.ClientSeed, req.RotateServerSeed)
+	if err != nil {
+		log.Printf("[PostFairRotate] Error rotating seeds: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to rotate seeds: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"next": next})
+}
+
+// PostFairVerify checks revealed seeds against their commitment and returns the shoe's shuffle seed
+// With a handId, the hand is also replayed from the shoe those seeds produce
+func PostFairVerify(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		random.Reveal
+		HandID int64 `json:"handId,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]interface{}{"reveal": req.Reveal}
+	seed, err := req.Reveal.Verify()
+	if err != nil {
+		resp["verified"] = false
+		resp["mismatch"] = err.Error()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	resp["shuffleSeed"] = hex.EncodeToString(seed)
+	resp["verified"] = true
+
+	if req.HandID != 0 {
+		handLog, err := handLogFor(r, req.HandID)
+		switch {
+		case errors.Is(err, game.ErrShoeInPlay):
+			http.Error(w, fmt.Sprintf("Hand %d can be verified once its shoe is reshuffled", req.HandID), http.StatusConflict)
+			return
+		case err != nil:
+			http.Error(w, fmt.Sprintf("Hand not found: %v", err), http.StatusNotFound)
+			return
+		}
+
+		// Check the hand against the seeds the caller holds, not the ones the table logged
+		reveal := req.Reveal
+		handLog.Seed.Fair = &reveal
+		replay, err := handLog.Verify()
+		resp["handId"] = req.HandID
+		resp["log"] = handLog
+		resp["replay"] = replay
+		if err != nil {
+			log.Printf("[PostFairVerify] Hand %d failed verification: %v", req.HandID, err)
+			resp["verified"] = false
+			resp["mismatch"] = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
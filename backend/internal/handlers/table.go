@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// SeatRequest represents a request for one seat at the table
+type SeatRequest struct {
+	Seat     int              `json:"seat"`
+	Amount   float64          `json:"amount,omitempty"`
+	Token    string           `json:"token,omitempty"`
+	SideBets []SideBetRequest `json:"sideBets,omitempty"`
+}
+
+// playerAddress returns the caller's address (in production, authenticate/authorize)
+func playerAddress(r *http.Request) string {
+	if addr := r.Header.Get("X-Player-Address"); addr != "" {
+		return addr
+	}
+	return "0x0000000000000000000000000000000000000000" // Demo fallback
+}
+
+// namedEngine returns the engine of the table named by the request, or the default table
+func namedEngine(r *http.Request) (string, *game.GlobalEngine, error) {
+	id := tableID(r)
+	if id == "" {
+		return game.DefaultTableID, game.GetTableManager().Default(), nil
+	}
+
+	engine, ok := game.GetTableManager().Get(id)
+	if !ok {
+		return "", nil, fmt.Errorf("table %s not found", id)
+	}
+	return id, engine, nil
+}
+
+// writeTable writes the table's seats and round state
+func writeTable(w http.ResponseWriter, table string, state *game.EngineState, message string) {
+	resp := map[string]interface{}{
+		"tableId":     table,
+		"handId":      state.HandID,
+		"phase":       state.Phase,
+		"phaseDetail": state.PhaseDetail,
+		"activeSeat":  state.ActiveSeat,
+		"seats":       state.Seats,
+		"dealerHand":  state.DealerHand,
+		"message":     message,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PostJoinSeat sits the caller down at a seat
+func PostJoinSeat(w http.ResponseWriter, r *http.Request) {
+	var req SeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostJoinSeat] Error decoding request: %v", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	playerAddr := playerAddress(r)
+	table, engine, err := namedEngine(r)
+	if err != nil {
+		log.Printf("[PostJoinSeat] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.JoinSeat(req.Seat, playerAddr); err != nil {
+		log.Printf("[PostJoinSeat] Error joining seat %d: %v", req.Seat, err)
+		http.Error(w, fmt.Sprintf("Failed to join seat: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeTable(w, table, engine.GetState(), fmt.Sprintf("Seated at seat %d", req.Seat))
+}
+
+// PostLeaveSeat frees the caller's seat between rounds
+func PostLeaveSeat(w http.ResponseWriter, r *http.Request) {
+	var req SeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostLeaveSeat] Error decoding request: %v", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	table, engine, err := namedEngine(r)
+	if err != nil {
+		log.Printf("[PostLeaveSeat] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.LeaveSeat(req.Seat, playerAddress(r)); err != nil {
+		log.Printf("[PostLeaveSeat] Error leaving seat %d: %v", req.Seat, err)
+		http.Error(w, fmt.Sprintf("Failed to leave seat: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeTable(w, table, engine.GetState(), fmt.Sprintf("Left seat %d", req.Seat))
+}
+
+// PostOpenBetting opens the betting window for the seated players
+func PostOpenBetting(w http.ResponseWriter, r *http.Request) {
+	table, engine, err := namedEngine(r)
+	if err != nil {
+		log.Printf("[PostOpenBetting] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.OpenBetting(game.GetTableManager().NextHandID()); err != nil {
+		log.Printf("[PostOpenBetting] Error opening betting: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to open betting: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeTable(w, table, engine.GetState(), "Place your bets")
+}
+
+// PostSeatBet places the caller's bet on their seat during the betting window
+func PostSeatBet(w http.ResponseWriter, r *http.Request) {
+	var req SeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("[PostSeatBet] Error decoding request: %v", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	tokenAddr := req.Token
+	if tokenAddr == "" {
+		tokenAddr = "0x0000000000000000000000000000000000000000" // Demo fallback
+	}
+
+	table, engine, err := namedEngine(r)
+	if err != nil {
+		log.Printf("[PostSeatBet] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.PlaceBet(req.Seat, playerAddress(r), tokenAddr, toWei(req.Amount).String()); err != nil {
+		log.Printf("[PostSeatBet] Error placing bet on seat %d: %v", req.Seat, err)
+		http.Error(w, fmt.Sprintf("Failed to place bet: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := engine.SeatSideBets(req.Seat, playerAddress(r), sideBetWagers(req.SideBets)); err != nil {
+		log.Printf("[PostSeatBet] Error placing side bets on seat %d: %v", req.Seat, err)
+		http.Error(w, fmt.Sprintf("Failed to place side bets: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeTable(w, table, engine.GetState(), fmt.Sprintf("Bet placed on seat %d", req.Seat))
+}
+
+// PostTableDeal closes the betting window and deals every seat with a bet
+func PostTableDeal(w http.ResponseWriter, r *http.Request) {
+	table, engine, err := namedEngine(r)
+	if err != nil {
+		log.Printf("[PostTableDeal] Error finding table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to find table: %v", err), http.StatusNotFound)
+		return
+	}
+	if err := engine.CloseBetting(); err != nil {
+		log.Printf("[PostTableDeal] Error closing betting: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to close betting: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Shuffle under the table's published commitment
+	if err := engine.ShuffleAndDealFair(); err != nil {
+		log.Printf("[PostTableDeal] Error dealing: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to shuffle and deal cards: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Dealer blackjack (or naturals on every seat) resolves immediately
+	if err := engine.FinishRound(); err != nil {
+		log.Printf("[PostTableDeal] Error finishing round: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to finish round: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	state := engine.GetState()
+
+	log.Printf("[PostTableDeal] Cards dealt: phase=%s, seats=%d, active=%d", state.Phase, len(state.Seats), state.ActiveSeat)
+
+	writeTable(w, table, state, "Cards dealt - "+state.PhaseDetail)
+}
+
+// GetTables lists the open tables
+func GetTables(w http.ResponseWriter, r *http.Request) {
+	tables := game.GetTableManager().Tables()
+
+	resp := map[string]interface{}{
+		"tables": tables,
+		"count":  len(tables),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// PostCreateTable opens a new table, with the JSON rules in the body on top of the defaults
+func PostCreateTable(w http.ResponseWriter, r *http.Request) {
+	rules := game.DefaultRules()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Printf("[PostCreateTable] Error reading request: %v", err)
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if len(body) > 0 {
+		if rules, err = game.ParseRules(body); err != nil {
+			log.Printf("[PostCreateTable] Error parsing rules: %v", err)
+			http.Error(w, fmt.Sprintf("Invalid rules: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	table, engine, err := game.GetTableManager().Create(rules)
+	if err != nil {
+		log.Printf("[PostCreateTable] Error creating table: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to create table: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"tableId": table,
+		"rules":   engine.Rules(),
+		"phase":   engine.GetState().Phase,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// GetVariants lists the games a table can be opened with and the rules each one fixes
+func GetVariants(w http.ResponseWriter, r *http.Request) {
+	variants := make([]map[string]interface{}, 0, len(game.Variants()))
+	for _, v := range game.Variants() {
+		rules := v.Rules(game.DefaultRules())
+		rules.Variant = v.Name()
+		variants = append(variants, map[string]interface{}{
+			"name":        v.Name(),
+			"rules":       rules,
+			"handsPerBet": v.HandsPerBet(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"variants": variants})
+}
+
+// PostCloseTable closes the table named by the request
+func PostCloseTable(w http.ResponseWriter, r *http.Request) {
+	table := tableID(r)
+	if err := game.GetTableManager().Close(table); err != nil {
+		log.Printf("[PostCloseTable] Error closing table %q: %v", table, err)
+		http.Error(w, fmt.Sprintf("Failed to close table: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"tableId": table,
+		"message": "Table closed",
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// PostVerify replays a hand from its seed and action log and checks the recorded result
+// The body names a hand by handId (its log is looked up on the tables) or carries the log itself.
+// Logs are only released once the hand's shoe is retired, since the seed reveals every card left in it.
+func PostVerify(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		HandID int64         `json:"handId"`
+		Log    *game.HandLog `json:"log,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	var handLog game.HandLog
+	switch {
+	case req.Log != nil:
+		handLog = *req.Log
+	case req.HandID == 0:
+		http.Error(w, "handId or log required", http.StatusBadRequest)
+		return
+	default:
+		var err error
+		handLog, err = handLogFor(r, req.HandID)
+		switch {
+		case errors.Is(err, game.ErrShoeInPlay):
+			http.Error(w, fmt.Sprintf("Hand %d can be verified once its shoe is reshuffled", req.HandID), http.StatusConflict)
+			return
+		case err != nil:
+			log.Printf("[PostVerify] No log for hand %d: %v", req.HandID, err)
+			http.Error(w, fmt.Sprintf("Hand not found: %v", err), http.StatusNotFound)
+			return
+		}
+	}
+
+	replay, err := handLog.Verify()
+	resp := map[string]interface{}{
+		"handId":   handLog.HandID,
+		"verified": err == nil,
+		"log":      handLog,
+		"replay":   replay,
+	}
+	if err != nil {
+		log.Printf("[PostVerify] Hand %d failed verification: %v", handLog.HandID, err)
+		resp["mismatch"] = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handLogFor returns a hand's replay log from the request's table, or from whichever table dealt it
+// A closed table's hands are still found: its shoe was retired when it closed
+func handLogFor(r *http.Request, handID int64) (game.HandLog, error) {
+	tables := game.GetTableManager()
+	if id := tableID(r); id != "" {
+		if engine, ok := tables.Get(id); ok {
+			return engine.HandLog(handID)
+		}
+	}
+	return tables.HandLog(handID)
+}
@@ -0,0 +1,97 @@
+package random
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Commitment is a provably fair seed pair for one shoe
+// The server publishes the hash of its seed before any bet, the player chooses the client seed,
+// and the shuffle seed is derived from both and the nonce. The server seed stays secret until revealed.
+type Commitment struct {
+	serverSeed []byte
+
+	ServerSeedHash string `json:"serverSeedHash"` // Hex sha256 of the server seed
+	ClientSeed     string `json:"clientSeed"`
+	Nonce          uint64 `json:"nonce"`
+}
+
+// NewCommitment commits to a fresh random server seed
+// An empty client seed is replaced by a random one the player can change before the shoe is shuffled
+func NewCommitment(clientSeed string, nonce uint64) (*Commitment, error) {
+	serverSeed, err := GenerateSeed()
+	if err != nil {
+		return nil, err
+	}
+	if clientSeed == "" {
+		if clientSeed, err = GenerateSeedHex(); err != nil {
+			return nil, err
+		}
+		clientSeed = clientSeed[:16]
+	}
+
+	return &Commitment{
+		serverSeed:     serverSeed,
+		ServerSeedHash: HashServerSeed(serverSeed),
+		ClientSeed:     clientSeed,
+		Nonce:          nonce,
+	}, nil
+}
+
+// Public returns the commitment without its server seed, safe to publish
+func (c *Commitment) Public() Commitment {
+	return Commitment{ServerSeedHash: c.ServerSeedHash, ClientSeed: c.ClientSeed, Nonce: c.Nonce}
+}
+
+// ShuffleSeed returns the seed the shoe is shuffled with
+func (c *Commitment) ShuffleSeed() []byte {
+	return DeriveShuffleSeed(c.serverSeed, c.ClientSeed, c.Nonce)
+}
+
+// Reveal discloses the server seed so the shuffle can be checked against the commitment
+func (c *Commitment) Reveal() Reveal {
+	return Reveal{
+		ServerSeed:     hex.EncodeToString(c.serverSeed),
+		ServerSeedHash: c.ServerSeedHash,
+		ClientSeed:     c.ClientSeed,
+		Nonce:          c.Nonce,
+	}
+}
+
+// Reveal is a commitment with its server seed disclosed
+type Reveal struct {
+	ServerSeed     string `json:"serverSeed"` // Hex
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	Nonce          uint64 `json:"nonce"`
+}
+
+// Verify checks the server seed against the published hash and returns the shuffle seed
+func (r Reveal) Verify() ([]byte, error) {
+	serverSeed, err := hex.DecodeString(strings.TrimPrefix(r.ServerSeed, "0x"))
+	if err != nil || len(serverSeed) == 0 {
+		return nil, fmt.Errorf("invalid server seed %q: want hex bytes", r.ServerSeed)
+	}
+
+	if hash := HashServerSeed(serverSeed); hash != strings.ToLower(strings.TrimPrefix(r.ServerSeedHash, "0x")) {
+		return nil, fmt.Errorf("server seed hashes to %s, not the committed %s", hash, r.ServerSeedHash)
+	}
+	return DeriveShuffleSeed(serverSeed, r.ClientSeed, r.Nonce), nil
+}
+
+// HashServerSeed returns the hex sha256 commitment of a server seed
+func HashServerSeed(serverSeed []byte) string {
+	hash := sha256.Sum256(serverSeed)
+	return hex.EncodeToString(hash[:])
+}
+
+// DeriveShuffleSeed derives a shuffle seed as HMAC-SHA256(serverSeed, "clientSeed:nonce")
+func DeriveShuffleSeed(serverSeed []byte, clientSeed string, nonce uint64) []byte {
+	mac := hmac.New(sha256.New, serverSeed)
+	mac.Write([]byte(clientSeed + ":" + strconv.FormatUint(nonce, 10)))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RampStep sets the bet from a true count up
+type RampStep struct {
+	TrueCount float64 `json:"trueCount"`
+	Units     float64 `json:"units"`
+}
+
+// Ramp is a bet ramp: one unit below the first step, then each step's units from its true count up
+// Steps are kept in ascending true count order
+type Ramp []RampStep
+
+// ParseRamp parses comma-separated "trueCount:units" steps such as "1:2,2:4,3:8"
+// An empty string is a flat one-unit bet
+func ParseRamp(s string) (Ramp, error) {
+	var ramp Ramp
+	if strings.TrimSpace(s) == "" {
+		return ramp, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		tc, units, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("ramp step %q: want trueCount:units", part)
+		}
+		step := RampStep{}
+		var err error
+		if step.TrueCount, err = strconv.ParseFloat(tc, 64); err != nil {
+			return nil, fmt.Errorf("ramp step %q: invalid true count: %w", part, err)
+		}
+		if step.Units, err = strconv.ParseFloat(units, 64); err != nil {
+			return nil, fmt.Errorf("ramp step %q: invalid units: %w", part, err)
+		}
+		ramp = append(ramp, step)
+	}
+
+	if err := ramp.Validate(); err != nil {
+		return nil, err
+	}
+	sort.Slice(ramp, func(i, j int) bool { return ramp[i].TrueCount < ramp[j].TrueCount })
+	return ramp, nil
+}
+
+// Validate checks that every step bets a positive amount
+func (r Ramp) Validate() error {
+	for _, step := range r {
+		if step.Units <= 0 {
+			return fmt.Errorf("ramp step at true count %g must bet more than 0 units", step.TrueCount)
+		}
+	}
+	return nil
+}
+
+// Bet returns the bet in units at a true count
+func (r Ramp) Bet(trueCount float64) float64 {
+	bet := 1.0
+	for _, step := range r {
+		if trueCount < step.TrueCount {
+			break
+		}
+		bet = step.Units
+	}
+	return bet
+}
+
+// String formats the ramp the way ParseRamp reads it
+func (r Ramp) String() string {
+	if len(r) == 0 {
+		return "flat"
+	}
+	parts := make([]string, len(r))
+	for i, step := range r {
+		parts[i] = fmt.Sprintf("%g:%g", step.TrueCount, step.Units)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,114 @@
+package sim
+
+import (
+	"github.com/DanDo385/blackjack/backend/internal/game"
+	"github.com/DanDo385/blackjack/backend/internal/strategy"
+	"github.com/shopspring/decimal"
+)
+
+// roundResult is the outcome of one round for one bet
+type roundResult struct {
+	net       float64 // Net win in units, insurance included
+	insurance float64 // Net of the insurance bet alone
+	blackjack bool
+	bustHands int
+}
+
+// playRound deals and settles one round of a bet in units, in casino order
+// The hole card is drawn up front either way; without a peek a dealer blackjack is only settled after the player acts
+func playRound(rules game.Rules, player strategy.Player, s *shoe, bet float64) (roundResult, error) {
+	var res roundResult
+
+	p1, err := s.draw()
+	if err != nil {
+		return res, err
+	}
+	up, err := s.draw()
+	if err != nil {
+		return res, err
+	}
+	p2, err := s.draw()
+	if err != nil {
+		return res, err
+	}
+	hole, err := s.drawHole()
+	if err != nil {
+		return res, err
+	}
+	defer s.revealHole()
+
+	cards := []game.Card{p1, p2}
+	dealer := []game.Card{up, hole}
+	playerBJ := game.IsBlackjack(cards)
+	dealerBJ := game.IsBlackjack(dealer)
+	peek := up.Rank == game.Ace || up.Rank.IsTen()
+
+	// Insurance is half the bet and pays 2:1
+	if up.Rank == game.Ace && player.TakeInsurance(s.trueCount()) {
+		if dealerBJ {
+			res.insurance = bet
+		} else {
+			res.insurance = -bet / 2
+		}
+		res.net += res.insurance
+	}
+
+	// Early surrender is offered before the dealer checks for blackjack
+	if peek && !playerBJ && rules.AllowSurrender && rules.SurrenderMode == game.SurrenderEarly {
+		hand := game.PlayerHand{Cards: cards, Bet: "1"}
+		if player.Decide(hand, 1, up, s.trueCount()) == strategy.Surrender {
+			hand.Surrendered = true
+			res.net += rules.EvaluateHand(hand, dealer, decimal.NewFromFloat(bet), true).Payout.InexactFloat64()
+			return res, nil
+		}
+	}
+
+	switch {
+	case dealerBJ && playerBJ:
+		res.blackjack = true
+		return res, nil
+	case dealerBJ && rules.Peeks():
+		res.net -= bet
+		return res, nil
+	case playerBJ:
+		res.blackjack = true
+		won, _ := rules.BlackjackPayout(cards, decimal.NewFromFloat(bet))
+		res.net += won.InexactFloat64()
+		return res, nil
+	}
+
+	hands, err := strategy.PlayHands(rules, cards, s.draw, func(hand game.PlayerHand, handsInRound int) strategy.Action {
+		return player.Decide(hand, handsInRound, up, s.trueCount())
+	})
+	if err != nil {
+		return res, err
+	}
+
+	// The dealer only draws while a hand is left to beat
+	live := false
+	for _, hand := range hands {
+		if game.IsBust(hand.Cards) {
+			res.bustHands++
+		} else if !hand.Surrendered {
+			live = true
+		}
+	}
+	s.revealHole()
+	for live && rules.DealerShouldHit(dealer) {
+		card, err := s.draw()
+		if err != nil {
+			return res, err
+		}
+		dealer = append(dealer, card)
+	}
+
+	for i, hand := range hands {
+		stake := bet
+		if hand.Doubled {
+			stake *= 2
+		}
+		settled := rules.EvaluateHand(hand, dealer, decimal.NewFromFloat(stake), i == 0)
+		res.net += settled.Payout.InexactFloat64()
+	}
+	return res, nil
+}
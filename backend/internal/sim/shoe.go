@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// shoe is a worker's shoe and the player's running count of it
+// Shoes are shuffled from seeds derived from the batch seed, so a batch always deals the same cards
+type shoe struct {
+	rules   game.Rules
+	system  game.CountingSystem
+	seed    []byte // Batch seed
+	shoes   int    // Shoes shuffled so far in the batch
+	deck    *game.Deck
+	running float64
+
+	round []game.Card // Cards the player has seen this round
+	hole  *game.Card  // Dealer hole card while it is face down
+}
+
+// newShoe returns a freshly shuffled shoe for a batch
+func newShoe(rules game.Rules, system game.CountingSystem, seed []byte) *shoe {
+	s := &shoe{rules: rules, system: system, seed: seed}
+	s.shuffle()
+	return s
+}
+
+// shuffle replaces the shoe with the batch's next one
+func (s *shoe) shuffle() {
+	s.shoes++
+	s.deck = s.rules.NewDeck()
+	s.deck.Shuffle(game.DeriveSeed(s.seed, "shoe", s.shoes))
+	s.deck.SetCutCard(s.rules.ReshuffleAt())
+	s.running = s.system.InitialRunningCount(s.rules.Decks)
+}
+
+// newRound clears the table, shuffling first if the cut card came out last round
+func (s *shoe) newRound() {
+	if s.deck.CutCardReached() {
+		s.shuffle()
+	}
+	s.round = s.round[:0]
+	s.hole = nil
+}
+
+// draw deals a card face up and counts it
+func (s *shoe) draw() (game.Card, error) {
+	card, err := s.next()
+	if err != nil {
+		return game.Card{}, err
+	}
+	s.round = append(s.round, card)
+	s.running += s.system.Tag(card)
+	return card, nil
+}
+
+// drawHole deals the dealer's hole card face down; it is counted when turned over
+func (s *shoe) drawHole() (game.Card, error) {
+	card, err := s.next()
+	if err != nil {
+		return game.Card{}, err
+	}
+	s.hole = &card
+	return card, nil
+}
+
+// revealHole turns the hole card over and counts it
+func (s *shoe) revealHole() {
+	if s.hole == nil {
+		return
+	}
+	s.round = append(s.round, *s.hole)
+	s.running += s.system.Tag(*s.hole)
+	s.hole = nil
+}
+
+// next deals the next card, reshuffling the discards like the engine when the shoe runs out mid-round
+func (s *shoe) next() (game.Card, error) {
+	card, err := s.deck.Draw()
+	if !errors.Is(err, game.ErrDeckExhausted) {
+		return card, err
+	}
+
+	inPlay := append([]game.Card(nil), s.round...)
+	if s.hole != nil {
+		inPlay = append(inPlay, *s.hole)
+	}
+	if err := s.deck.ReshuffleDiscards(inPlay); err != nil {
+		return game.Card{}, fmt.Errorf("shoe %d: %w", s.shoes, err)
+	}
+
+	// The reshuffled discards are unseen again; only the cards on the table stay counted
+	s.running = s.system.InitialRunningCount(s.rules.Decks)
+	for _, seen := range s.round {
+		s.running += s.system.Tag(seen)
+	}
+	return s.deck.Draw()
+}
+
+// trueCount returns the player's true count from the cards still to be dealt
+func (s *shoe) trueCount() float64 {
+	return s.system.TrueCount(s.running, float64(s.deck.Remaining())/float64(s.rules.DeckSize()))
+}
@@ -0,0 +1,238 @@
+package sim
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+	"github.com/DanDo385/blackjack/backend/internal/random"
+	"github.com/DanDo385/blackjack/backend/internal/strategy"
+)
+
+// batchRounds is the number of rounds a worker plays per batch
+// Batches are seeded by index and merged in index order, so results do not depend on the worker count
+const batchRounds = 10000
+
+// Config describes a simulation run
+type Config struct {
+	Rules    game.Rules
+	Player   strategy.Player
+	Counting game.CountingSystem // Count the player keeps for the ramp and count-based plays
+	Ramp     Ramp
+	Rounds   int
+	Workers  int     // Defaults to the number of CPUs
+	Seed     []byte  // Master seed every shoe is derived from; random when empty
+	Bankroll float64 // In units, for the risk of ruin
+}
+
+// Result summarises a simulation run; money is in betting units
+type Result struct {
+	Rounds     int        `json:"rounds"`
+	Wagered    float64    `json:"wagered"` // Initial bets, before doubles and splits
+	Net        float64    `json:"net"`
+	EV         float64    `json:"ev"`        // Mean net per round
+	EVPercent  float64    `json:"evPercent"` // Net over initial bets, in percent
+	CI95       [2]float64 `json:"ci95"`      // 95% confidence interval of EVPercent
+	StdDev     float64    `json:"stdDev"`    // Per round
+	N0         float64    `json:"n0"`        // Rounds for the expectation to equal one standard deviation (0 without an edge)
+	RiskOfRuin float64    `json:"riskOfRuin"`
+
+	Wins       int `json:"wins"` // Rounds by the net of the hands, insurance aside
+	Losses     int `json:"losses"`
+	Pushes     int `json:"pushes"`
+	Blackjacks int `json:"blackjacks"`
+	BustHands  int `json:"bustHands"` // Hands, not rounds: each split hand that busts counts
+
+	Seed    string        `json:"seed"`
+	Workers int           `json:"workers"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Run simulates cfg.Rounds rounds across a pool of workers
+// Each batch of rounds plays its own shoes, shuffled from seeds derived from the master seed,
+// so a run is reproducible from its seed alone.
+// Only classic blackjack is simulated; other variants return strategy.ErrUnsupportedVariant.
+func Run(cfg Config) (Result, error) {
+	if err := cfg.Rules.Validate(); err != nil {
+		return Result{}, fmt.Errorf("invalid rules: %w", err)
+	}
+	if err := strategy.CheckVariant(cfg.Rules); err != nil {
+		return Result{}, err
+	}
+	if cfg.Counting == "" {
+		cfg.Counting = game.CountHiLo
+	}
+	if err := cfg.Counting.Validate(); err != nil {
+		return Result{}, err
+	}
+	if err := cfg.Ramp.Validate(); err != nil {
+		return Result{}, err
+	}
+	if cfg.Player == nil {
+		return Result{}, fmt.Errorf("no player strategy")
+	}
+	if cfg.Rounds < 1 {
+		return Result{}, fmt.Errorf("rounds must be at least 1, got %d", cfg.Rounds)
+	}
+	if cfg.Workers < 1 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if len(cfg.Seed) == 0 {
+		seed, err := random.GenerateSeed()
+		if err != nil {
+			return Result{}, err
+		}
+		cfg.Seed = seed
+	}
+	cfg.Ramp = append(Ramp(nil), cfg.Ramp...)
+	sort.Slice(cfg.Ramp, func(i, j int) bool { return cfg.Ramp[i].TrueCount < cfg.Ramp[j].TrueCount })
+
+	start := time.Now()
+	batches := (cfg.Rounds + batchRounds - 1) / batchRounds
+	tallies := make([]tally, batches)
+	errs := make([]error, batches)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				rounds := min(batchRounds, cfg.Rounds-b*batchRounds)
+				tallies[b], errs[b] = runBatch(cfg, b, rounds)
+			}
+		}()
+	}
+	for b := 0; b < batches; b++ {
+		jobs <- b
+	}
+	close(jobs)
+	wg.Wait()
+
+	var total tally
+	for b := range tallies {
+		if errs[b] != nil {
+			return Result{}, fmt.Errorf("batch %d: %w", b, errs[b])
+		}
+		total.merge(tallies[b])
+	}
+
+	res := total.result(cfg.Bankroll)
+	res.Seed = hex.EncodeToString(cfg.Seed)
+	res.Workers = cfg.Workers
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
+
+// runBatch plays one batch of rounds on the batch's own shoes
+func runBatch(cfg Config, batch, rounds int) (tally, error) {
+	var t tally
+	s := newShoe(cfg.Rules, cfg.Counting, game.DeriveSeed(cfg.Seed, "batch", batch))
+	for i := 0; i < rounds; i++ {
+		s.newRound()
+		bet := cfg.Ramp.Bet(s.trueCount())
+		res, err := playRound(cfg.Rules, cfg.Player, s, bet)
+		if err != nil {
+			return tally{}, err
+		}
+		t.add(res, bet)
+	}
+	return t, nil
+}
+
+// tally accumulates round results, keeping the running mean and squared deviations (Welford)
+type tally struct {
+	rounds  int
+	wagered float64
+	net     float64
+	mean    float64
+	m2      float64
+
+	wins, losses, pushes, blackjacks, bustHands int
+}
+
+// add records one round
+func (t *tally) add(res roundResult, bet float64) {
+	t.rounds++
+	t.wagered += bet
+	t.net += res.net
+
+	delta := res.net - t.mean
+	t.mean += delta / float64(t.rounds)
+	t.m2 += delta * (res.net - t.mean)
+
+	switch hands := res.net - res.insurance; {
+	case hands > 0:
+		t.wins++
+	case hands < 0:
+		t.losses++
+	default:
+		t.pushes++
+	}
+	if res.blackjack {
+		t.blackjacks++
+	}
+	t.bustHands += res.bustHands
+}
+
+// merge adds another tally's rounds
+func (t *tally) merge(o tally) {
+	if o.rounds == 0 {
+		return
+	}
+	n := t.rounds + o.rounds
+	delta := o.mean - t.mean
+	t.mean += delta * float64(o.rounds) / float64(n)
+	t.m2 += o.m2 + delta*delta*float64(t.rounds)*float64(o.rounds)/float64(n)
+	t.rounds = n
+
+	t.wagered += o.wagered
+	t.net += o.net
+	t.wins += o.wins
+	t.losses += o.losses
+	t.pushes += o.pushes
+	t.blackjacks += o.blackjacks
+	t.bustHands += o.bustHands
+}
+
+// result derives the run statistics
+func (t *tally) result(bankroll float64) Result {
+	res := Result{
+		Rounds:     t.rounds,
+		Wagered:    t.wagered,
+		Net:        t.net,
+		EV:         t.mean,
+		Wins:       t.wins,
+		Losses:     t.losses,
+		Pushes:     t.pushes,
+		Blackjacks: t.blackjacks,
+		BustHands:  t.bustHands,
+	}
+	if t.rounds < 2 || t.wagered == 0 {
+		return res
+	}
+
+	variance := t.m2 / float64(t.rounds-1)
+	res.StdDev = math.Sqrt(variance)
+
+	avgBet := t.wagered / float64(t.rounds)
+	stdErr := res.StdDev / math.Sqrt(float64(t.rounds))
+	res.EVPercent = t.mean / avgBet * 100
+	res.CI95 = [2]float64{(t.mean - 1.96*stdErr) / avgBet * 100, (t.mean + 1.96*stdErr) / avgBet * 100}
+
+	// N0 and risk of ruin only make sense for a player with an edge
+	res.RiskOfRuin = 1
+	if t.mean > 0 && variance > 0 {
+		res.N0 = variance / (t.mean * t.mean)
+		if bankroll > 0 {
+			res.RiskOfRuin = math.Exp(-2 * t.mean * bankroll / variance)
+		}
+	}
+	return res
+}
@@ -0,0 +1,197 @@
+package sim
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+	"github.com/DanDo385/blackjack/backend/internal/strategy"
+)
+
+func testConfig(rounds, workers int) Config {
+	rules := game.DefaultRules()
+	rules.Decks = 6
+	rules.HitSoft17 = false
+	rules.BJPayoutBps = 15000
+	return Config{
+		Rules:   rules,
+		Player:  strategy.NewAdvisor(rules),
+		Rounds:  rounds,
+		Workers: workers,
+		Seed:    []byte("sim-test-seed"),
+	}
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	one, err := Run(testConfig(25000, 1))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	many, err := Run(testConfig(25000, 4))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if one.Rounds != 25000 {
+		t.Fatalf("rounds = %d, want 25000", one.Rounds)
+	}
+	one.Workers, one.Elapsed = many.Workers, many.Elapsed
+	if one != many {
+		t.Errorf("results differ across worker counts:\n%+v\n%+v", one, many)
+	}
+
+	cfg := testConfig(25000, 4)
+	cfg.Seed = []byte("another seed")
+	other, err := Run(cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if other.Net == many.Net {
+		t.Error("different seeds gave the same result")
+	}
+}
+
+func TestRunMatchesExactEdge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("long simulation")
+	}
+
+	cfg := testConfig(300000, 0)
+	res, err := Run(cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	edge, err := strategy.HouseEdge(cfg.Rules)
+	if err != nil {
+		t.Fatalf("HouseEdge: %v", err)
+	}
+
+	// Widen the 95% interval so the fixed seed is not a coin flip around its edge
+	want := -edge.HouseEdge * 100
+	half := (res.CI95[1] - res.CI95[0]) / 2
+	if math.Abs(res.EVPercent-want) > 2*half {
+		t.Errorf("simulated EV %.3f%% (±%.3f%%), exact %.3f%%", res.EVPercent, half, want)
+	}
+	if res.StdDev < 1.05 || res.StdDev > 1.25 {
+		t.Errorf("standard deviation = %.3f, want about 1.15 units per round", res.StdDev)
+	}
+	if res.N0 != 0 || res.RiskOfRuin != 1 {
+		t.Errorf("N0 %.0f, risk of ruin %.2f: want 0 and 1 without an edge", res.N0, res.RiskOfRuin)
+	}
+}
+
+func TestRunRejectsBadConfig(t *testing.T) {
+	cfg := testConfig(0, 1)
+	if _, err := Run(cfg); err == nil {
+		t.Error("expected error for zero rounds")
+	}
+
+	cfg = testConfig(10, 1)
+	cfg.Counting = "tens"
+	if _, err := Run(cfg); err == nil {
+		t.Error("expected error for an unknown counting system")
+	}
+
+	cfg = testConfig(10, 1)
+	cfg.Player = nil
+	if _, err := Run(cfg); err == nil {
+		t.Error("expected error without a player")
+	}
+
+	cfg = testConfig(10, 1)
+	switchGame, _ := game.LookupVariant(game.VariantSwitch)
+	cfg.Rules = switchGame.Rules(cfg.Rules)
+	cfg.Rules.Variant = game.VariantSwitch
+	if _, err := Run(cfg); !errors.Is(err, strategy.ErrUnsupportedVariant) {
+		t.Errorf("Run under Blackjack Switch: %v, want ErrUnsupportedVariant", err)
+	}
+}
+
+func TestParseRamp(t *testing.T) {
+	ramp, err := ParseRamp("3:8, 1:2,2:4")
+	if err != nil {
+		t.Fatalf("ParseRamp: %v", err)
+	}
+	for tc, want := range map[float64]float64{-2: 1, 0.99: 1, 1: 2, 2.5: 4, 7: 8} {
+		if got := ramp.Bet(tc); got != want {
+			t.Errorf("Bet(%g) = %g, want %g", tc, got, want)
+		}
+	}
+	if got := ramp.String(); got != "1:2,2:4,3:8" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, bad := range []string{"1", "x:2", "1:y", "1:0"} {
+		if _, err := ParseRamp(bad); err == nil {
+			t.Errorf("ParseRamp(%q): expected error", bad)
+		}
+	}
+}
+
+func TestTallyMerge(t *testing.T) {
+	nets := []float64{1, -1, 0, 1.5, -2, 1, -1, -1, 2, 0.5}
+
+	var whole tally
+	for _, net := range nets {
+		whole.add(roundResult{net: net}, 1)
+	}
+
+	var left, right tally
+	for i, net := range nets {
+		if i < 4 {
+			left.add(roundResult{net: net}, 1)
+		} else {
+			right.add(roundResult{net: net}, 1)
+		}
+	}
+	left.merge(right)
+
+	if left.rounds != whole.rounds || math.Abs(left.mean-whole.mean) > 1e-12 || math.Abs(left.m2-whole.m2) > 1e-12 {
+		t.Errorf("merged tally %+v, want %+v", left, whole)
+	}
+}
+
+func TestTallyCountsHandsApartFromInsurance(t *testing.T) {
+	var tl tally
+	// Insured against a blackjack: the hand loses, the insurance pays, the round breaks even
+	tl.add(roundResult{net: 0, insurance: 1}, 1)
+	// Insurance lost on a hand that wins
+	tl.add(roundResult{net: 0.5, insurance: -0.5}, 1)
+	// Insurance lost on a push
+	tl.add(roundResult{net: -0.5, insurance: -0.5}, 1)
+
+	if tl.wins != 1 || tl.losses != 1 || tl.pushes != 1 {
+		t.Errorf("wins/losses/pushes = %d/%d/%d, want 1/1/1", tl.wins, tl.losses, tl.pushes)
+	}
+	if tl.net != 0 {
+		t.Errorf("net = %v, want 0", tl.net)
+	}
+}
+
+func TestRunWithIndexPlays(t *testing.T) {
+	cfg := testConfig(50000, 2)
+	cfg.Ramp = Ramp{{TrueCount: 1, Units: 2}, {TrueCount: 3, Units: 8}}
+	basic, err := Run(cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	deviations, err := strategy.LookupDeviations("illustrious18,fab4")
+	if err != nil {
+		t.Fatalf("LookupDeviations: %v", err)
+	}
+	cfg.Player = strategy.NewIndexPlayer(strategy.NewAdvisor(cfg.Rules), deviations)
+	index, err := Run(cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Same seed and ramp: the bets match until the index plays change which cards are drawn
+	if index.Net == basic.Net {
+		t.Error("index plays changed nothing")
+	}
+	if index.Wagered <= float64(index.Rounds) {
+		t.Errorf("wagered %.0f over %d rounds, want the ramp to raise bets", index.Wagered, index.Rounds)
+	}
+}
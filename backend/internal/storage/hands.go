@@ -124,10 +124,12 @@ func UpdateHandState(ctx context.Context, handID int64, updates map[string]inter
 }
 
 // UpdateHandSeed updates the seed field in Redis hand state
+// The shuffle version is stored alongside so the hand can be replayed from its seed
 func UpdateHandSeed(ctx context.Context, handID int64, seed string) error {
 	return UpdateHandState(ctx, handID, map[string]interface{}{
-		"seed":   seed,
-		"status": "randomness_fulfilled",
+		"seed":           seed,
+		"shuffleVersion": int(game.CurrentShuffleVersion),
+		"status":         "randomness_fulfilled",
 	}, 30*time.Minute)
 }
 
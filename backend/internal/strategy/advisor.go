@@ -0,0 +1,256 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// Action is a player decision, named like the game endpoints
+type Action string
+
+const (
+	Hit              Action = "hit"
+	Stand            Action = "stand"
+	Double           Action = "double"
+	Split            Action = "split"
+	Surrender        Action = "surrender"
+	Insure           Action = "insurance"
+	DeclineInsurance Action = "decline_insurance"
+)
+
+// ErrUnsupportedVariant is returned for a game whose strategy and odds this package does not model
+var ErrUnsupportedVariant = errors.New("variant not supported")
+
+// CheckVariant returns ErrUnsupportedVariant unless rules deal classic blackjack
+// The charts, the edge calculation and the simulator all assume the classic deck and payouts
+func CheckVariant(rules game.Rules) error {
+	if rules.Variant != "" && rules.Variant != game.VariantClassic {
+		return fmt.Errorf("%w: %s", ErrUnsupportedVariant, rules.Variant)
+	}
+	return nil
+}
+
+// Advice is the recommended action for a hand and why
+type Advice struct {
+	Action Action `json:"action"`
+	Code   Code   `json:"code,omitempty"` // Chart cell the advice came from
+	Reason string `json:"reason"`
+	Total  int    `json:"total"`
+	Soft   bool   `json:"soft"`
+}
+
+// Advisor recommends basic-strategy plays for one rule set
+type Advisor struct {
+	rules game.Rules
+	chart Chart
+}
+
+// NewAdvisor returns an advisor playing the chart generated for rules
+func NewAdvisor(rules game.Rules) *Advisor {
+	return &Advisor{rules: rules, chart: Generate(rules)}
+}
+
+// NewChartAdvisor returns an advisor playing a custom chart under rules
+func NewChartAdvisor(rules game.Rules, chart Chart) *Advisor {
+	return &Advisor{rules: rules, chart: chart}
+}
+
+// Chart returns the advisor's strategy chart
+func (a *Advisor) Chart() Chart {
+	return a.chart
+}
+
+// Advise recommends the play for a hand against the dealer upcard
+// handsInRound is how many hands the seat holds (splitting stops at the table's limit)
+func (a *Advisor) Advise(hand game.PlayerHand, handsInRound int, dealerUp game.Card) Advice {
+	d := a.decide(hand, handsInRound, column(dealerUp.Rank))
+	label := handLabel(hand.Cards, d.total, d.soft)
+	vs := fmt.Sprintf("%s vs %s", label, dealerUp.Rank)
+
+	advice := Advice{Action: d.action, Code: d.code, Total: d.total, Soft: d.soft}
+	switch {
+	case d.total > 21:
+		advice.Reason = label + " is bust"
+	case d.action == Surrender:
+		advice.Reason = fmt.Sprintf("%s: surrender, losing half the bet is cheaper than playing on", vs)
+	case d.action == Split && d.code == Ph:
+		advice.Reason = fmt.Sprintf("%s: split, worth it because you may double after splitting", vs)
+	case d.action == Split:
+		advice.Reason = fmt.Sprintf("%s: always split here", vs)
+	case d.action == Double:
+		advice.Reason = fmt.Sprintf("%s: double, the dealer is likely to bust or you are likely to draw well", vs)
+	case d.code == Dh || d.code == Ds:
+		advice.Reason = fmt.Sprintf("%s: double if allowed, otherwise %s; doubling is not allowed on this hand", vs, d.action)
+	case d.action == Stand && d.total <= 16:
+		advice.Reason = fmt.Sprintf("%s: stand and let the dealer risk busting", vs)
+	case d.action == Stand:
+		advice.Reason = fmt.Sprintf("%s: stand", vs)
+	case d.total >= 12 && !d.soft:
+		advice.Reason = fmt.Sprintf("%s: hit, the dealer's upcard makes %d too weak to stand on", vs, d.total)
+	default:
+		advice.Reason = fmt.Sprintf("%s: hit", vs)
+	}
+	return advice
+}
+
+// decision is a chart lookup before it is explained
+type decision struct {
+	action Action
+	code   Code
+	total  int
+	soft   bool
+}
+
+// decide looks the hand up in the chart against the upcard in chart column col,
+// falling back to what the table allows
+func (a *Advisor) decide(hand game.PlayerHand, handsInRound int, col int) decision {
+	return a.lookup(hand, handsInRound, col, a.canSurrender(hand, handsInRound))
+}
+
+// lookup is decide with surrender offered or not
+func (a *Advisor) lookup(hand game.PlayerHand, handsInRound int, col int, surrender bool) decision {
+	total, soft := game.CalculateHandValue(hand.Cards)
+	d := decision{total: total, soft: soft}
+	if total > 21 {
+		d.action = Stand
+		return d
+	}
+
+	pair := len(hand.Cards) == 2 && hand.Cards[0].Rank == hand.Cards[1].Rank
+	pairPoints := 0
+	if pair {
+		pairPoints = hand.Cards[0].Rank.Points()
+	}
+
+	// Surrender comes first: it is only offered as the first decision
+	if surrender {
+		if (pair && a.chart.PairSurrender[pairPoints][col]) || (!pair && !soft && a.chart.Surrender[total][col]) {
+			d.action = Surrender
+			return d
+		}
+	}
+
+	if pair && a.canSplit(hand, handsInRound) {
+		code := a.chart.Pairs[pairPoints][col]
+		if code == P || (code == Ph && a.rules.AllowDAS) {
+			d.action, d.code = Split, code
+			return d
+		}
+	}
+
+	if soft {
+		d.code = a.chart.Soft[total][col]
+	} else {
+		d.code = a.chart.Hard[total][col]
+	}
+
+	switch d.code {
+	case Dh, Ds:
+		switch {
+		case a.rules.CanDouble(hand.Cards, hand.FromSplit):
+			d.action = Double
+		case d.code == Ds:
+			d.action = Stand
+		default:
+			d.action = Hit
+		}
+	case S:
+		d.action = Stand
+	default:
+		d.action = Hit
+	}
+	return d
+}
+
+// AdviseState recommends the play for the hand the engine is waiting on
+// The classic charts don't apply to other variants, which return ErrUnsupportedVariant
+func (a *Advisor) AdviseState(state *game.EngineState) (Advice, error) {
+	if err := CheckVariant(a.rules); err != nil {
+		return Advice{}, err
+	}
+	if state.ActiveSeat < 0 || state.ActiveSeat >= len(state.Seats) {
+		return Advice{}, fmt.Errorf("invalid active seat %d", state.ActiveSeat)
+	}
+	seat := state.Seats[state.ActiveSeat]
+	if len(state.DealerCards) == 0 || seat.ActiveHand >= len(seat.Hands) {
+		return Advice{}, fmt.Errorf("no hand dealt")
+	}
+	hand := seat.Hands[seat.ActiveHand]
+	dealerUp := state.DealerCards[0]
+
+	switch state.Phase {
+	case game.PhasePlayerTurn:
+		if state.DealerPeeked && a.rules.AllowSurrender && a.rules.SurrenderMode == game.SurrenderEarly {
+			// The dealer has checked for blackjack, so surrendering now only saves half of a bet that is still live
+			return a.afterPeek().Advise(hand, len(seat.Hands), dealerUp), nil
+		}
+		return a.Advise(hand, len(seat.Hands), dealerUp), nil
+	case game.PhaseInsuranceOffer:
+		// Early surrender against an Ace is decided in place of insurance
+		if a.rules.AllowSurrender && a.rules.SurrenderMode == game.SurrenderEarly {
+			if advice := a.Advise(hand, len(seat.Hands), dealerUp); advice.Action == Surrender {
+				return advice, nil
+			}
+		}
+		total, soft := game.CalculateHandValue(hand.Cards)
+		return Advice{
+			Action: DeclineInsurance,
+			Reason: "Decline insurance (and even money): it pays 2:1 but the hole card is a ten less than a third of the time",
+			Total:  total,
+			Soft:   soft,
+		}, nil
+	default:
+		return Advice{}, fmt.Errorf("no decision to make in phase %s", state.Phase)
+	}
+}
+
+// afterPeek returns the advisor without the hands only worth surrendering before the dealer peeks
+func (a *Advisor) afterPeek() *Advisor {
+	early, late := a.rules, a.rules
+	early.SurrenderMode, late.SurrenderMode = game.SurrenderEarly, game.SurrenderLate
+	earlyChart, lateChart := Generate(early), Generate(late)
+
+	chart := a.chart
+	for total := range chart.Surrender {
+		for col := range chart.Surrender[total] {
+			if earlyChart.Surrender[total][col] && !lateChart.Surrender[total][col] {
+				chart.Surrender[total][col] = false
+			}
+		}
+	}
+	for points := range chart.PairSurrender {
+		for col := range chart.PairSurrender[points] {
+			if earlyChart.PairSurrender[points][col] && !lateChart.PairSurrender[points][col] {
+				chart.PairSurrender[points][col] = false
+			}
+		}
+	}
+	return &Advisor{rules: a.rules, chart: chart}
+}
+
+// canSplit reports whether the table allows splitting the hand
+func (a *Advisor) canSplit(hand game.PlayerHand, handsInRound int) bool {
+	if handsInRound >= a.rules.MaxSplitHands {
+		return false
+	}
+	return !(hand.SplitAces && a.rules.SplitAcesOnce)
+}
+
+// canSurrender reports whether the table allows surrendering the hand
+func (a *Advisor) canSurrender(hand game.PlayerHand, handsInRound int) bool {
+	return a.rules.AllowSurrender && handsInRound == 1 && len(hand.Cards) == 2 && !hand.FromSplit
+}
+
+// handLabel describes a hand the way strategy charts do: "Pair of 8", "Soft 18", "Hard 16"
+func handLabel(cards []game.Card, total int, soft bool) string {
+	switch {
+	case len(cards) == 2 && cards[0].Rank == cards[1].Rank:
+		return fmt.Sprintf("Pair of %s", cards[0].Rank)
+	case soft:
+		return fmt.Sprintf("Soft %d", total)
+	default:
+		return fmt.Sprintf("Hard %d", total)
+	}
+}
@@ -0,0 +1,144 @@
+package strategy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+func cards(specs ...string) []game.Card {
+	out := make([]game.Card, len(specs))
+	for i, spec := range specs {
+		c, err := game.ParseCard(spec)
+		if err != nil {
+			panic(err)
+		}
+		out[i] = c
+	}
+	return out
+}
+
+func TestAdvise(t *testing.T) {
+	rules := game.DefaultRules()
+	rules.AllowSurrender = true
+	noDouble := rules
+	noDouble.DoubleOn = game.DoubleTenToEleven
+	noDAS := rules
+	noDAS.AllowDAS = false
+
+	tests := []struct {
+		name  string
+		rules game.Rules
+		hand  game.PlayerHand
+		hands int
+		up    string
+		want  Action
+	}{
+		{"hard 16 vs 10 surrenders", rules, game.PlayerHand{Cards: cards("10-S", "6-H")}, 1, "K-C", Surrender},
+		{"hard 16 vs 10 after a split hits", rules, game.PlayerHand{Cards: cards("10-S", "6-H"), FromSplit: true}, 2, "K-C", Hit},
+		{"three-card 16 vs 10 hits", rules, game.PlayerHand{Cards: cards("10-S", "3-H", "3-D")}, 1, "K-C", Hit},
+		{"eights split", rules, game.PlayerHand{Cards: cards("8-S", "8-H")}, 1, "6-C", Split},
+		{"eights at the split limit", rules, game.PlayerHand{Cards: cards("8-S", "8-H"), FromSplit: true}, 4, "6-C", Stand},
+		{"twos vs 2 split with DAS", rules, game.PlayerHand{Cards: cards("2-S", "2-H")}, 1, "2-C", Split},
+		{"twos vs 2 hit without DAS", noDAS, game.PlayerHand{Cards: cards("2-S", "2-H")}, 1, "2-C", Hit},
+		{"11 doubles", rules, game.PlayerHand{Cards: cards("6-S", "5-H")}, 1, "6-C", Double},
+		{"9 vs 4 hits when only 10-11 double", noDouble, game.PlayerHand{Cards: cards("6-S", "3-H")}, 1, "4-C", Hit},
+		{"soft 18 vs 4 stands when it cannot double", noDouble, game.PlayerHand{Cards: cards("A-S", "7-H")}, 1, "4-C", Stand},
+		{"soft 18 vs 9 hits", rules, game.PlayerHand{Cards: cards("A-S", "7-H")}, 1, "9-C", Hit},
+		{"multi-card soft 18 vs 9 hits", rules, game.PlayerHand{Cards: cards("A-S", "A-H", "6-D")}, 1, "9-C", Hit},
+		{"hard 13 vs 2 stands", rules, game.PlayerHand{Cards: cards("10-S", "3-H")}, 1, "2-C", Stand},
+	}
+	for _, tt := range tests {
+		up := cards(tt.up)[0]
+		if got := NewAdvisor(tt.rules).Advise(tt.hand, tt.hands, up); got.Action != tt.want {
+			t.Errorf("%s: got %s (%s), want %s", tt.name, got.Action, got.Reason, tt.want)
+		}
+	}
+}
+
+func TestAdviseState(t *testing.T) {
+	advisor := NewAdvisor(game.DefaultRules())
+	state := &game.EngineState{
+		Phase:       game.PhaseInsuranceOffer,
+		DealerCards: cards("A-S", "9-D"),
+		Seats:       []game.Seat{{Hands: []game.PlayerHand{{Cards: cards("10-S", "6-H")}}}},
+	}
+
+	advice, err := advisor.AdviseState(state)
+	if err != nil {
+		t.Fatalf("AdviseState: %v", err)
+	}
+	if advice.Action != DeclineInsurance {
+		t.Errorf("insurance offer = %s, want %s", advice.Action, DeclineInsurance)
+	}
+
+	state.Phase = game.PhasePlayerTurn
+	if advice, err = advisor.AdviseState(state); err != nil {
+		t.Fatalf("AdviseState: %v", err)
+	}
+	if advice.Action != Hit || !strings.HasPrefix(advice.Reason, "Hard 16 vs A") {
+		t.Errorf("player turn = %s (%s), want hit on hard 16 vs A", advice.Action, advice.Reason)
+	}
+
+	// Early surrender on 14 vs 10 is only worth it before the dealer peeks
+	early := game.DefaultRules()
+	early.AllowSurrender = true
+	early.SurrenderMode = game.SurrenderEarly
+	state.DealerCards = cards("10-S", "9-D")
+	state.Seats[0].Hands[0].Cards = cards("10-C", "4-H")
+	if advice, _ := NewAdvisor(early).AdviseState(state); advice.Action != Surrender {
+		t.Errorf("14 vs 10 before the peek = %s, want %s", advice.Action, Surrender)
+	}
+	state.DealerPeeked = true
+	if advice, _ := NewAdvisor(early).AdviseState(state); advice.Action != Hit {
+		t.Errorf("14 vs 10 after the peek = %s, want %s", advice.Action, Hit)
+	}
+	state.Seats[0].Hands[0].Cards = cards("10-C", "6-H")
+	if advice, _ := NewAdvisor(early).AdviseState(state); advice.Action != Surrender {
+		t.Errorf("16 vs 10 after the peek = %s, want %s", advice.Action, Surrender)
+	}
+
+	state.Phase = game.PhaseComplete
+	if _, err := advisor.AdviseState(state); err == nil {
+		t.Error("expected error advising a finished hand")
+	}
+
+	state.Phase = game.PhasePlayerTurn
+	spanish := game.DefaultRules()
+	spanish.Variant = game.VariantSpanish21
+	if _, err := NewAdvisor(spanish).AdviseState(state); !errors.Is(err, ErrUnsupportedVariant) {
+		t.Errorf("AdviseState under Spanish 21: %v, want ErrUnsupportedVariant", err)
+	}
+}
+
+func TestPlaySplitsAndDoubles(t *testing.T) {
+	advisor := NewAdvisor(game.DefaultRules())
+
+	// 8-8 vs 6 splits; the first 8 draws a 3 and doubles on 11, the second draws a 10 and stands
+	deck := &game.Deck{Cards: cards("3-H", "K-D", "10-C", "2-S")}
+	hands, err := advisor.Play(deck, cards("8-S", "8-H"), cards("6-C")[0])
+	if err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if len(hands) != 2 {
+		t.Fatalf("hands = %v, want 2", hands)
+	}
+	if !hands[0].Doubled || hands[0].Bet != "2" || len(hands[0].Cards) != 3 {
+		t.Errorf("first hand = %+v, want 8-3 doubled to three cards", hands[0])
+	}
+	if hands[1].Doubled || hands[1].Bet != "1" || len(hands[1].Cards) != 2 {
+		t.Errorf("second hand = %+v, want 8-10 standing", hands[1])
+	}
+
+	// Split aces get one card each
+	deck = &game.Deck{Cards: cards("5-H", "6-D", "9-C")}
+	hands, err = advisor.Play(deck, cards("A-S", "A-H"), cards("10-C")[0])
+	if err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if len(hands) != 2 || len(hands[0].Cards) != 2 || len(hands[1].Cards) != 2 {
+		t.Errorf("split aces = %v, want two hands of two cards", hands)
+	}
+}
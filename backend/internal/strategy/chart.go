@@ -0,0 +1,239 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// Code is one cell of a basic-strategy chart
+// Double and split codes carry the play to fall back on when the table does not allow them
+type Code string
+
+const (
+	H  Code = "H"  // Hit
+	S  Code = "S"  // Stand
+	Dh Code = "Dh" // Double if allowed, otherwise hit
+	Ds Code = "Ds" // Double if allowed, otherwise stand
+	P  Code = "P"  // Split
+	Ph Code = "Ph" // Split if double after split is allowed, otherwise hit
+)
+
+// Upcards lists the dealer upcards in chart column order: 2-10, then Ace
+var Upcards = []game.Rank{game.Two, game.Three, game.Four, game.Five, game.Six, game.Seven, game.Eight, game.Nine, game.Ten, game.Ace}
+
+// Row is a chart row, one code per dealer upcard in Upcards order
+type Row [10]Code
+
+// Chart is a basic-strategy chart for one rule set
+// Hard and Soft are indexed by hand total, Pairs by the paired rank's points (1 = aces, 10 = tens);
+// Surrender and PairSurrender mark the hands to give up when surrender is offered
+type Chart struct {
+	Hard          [22]Row      `json:"hard"`  // Totals 4-21
+	Soft          [22]Row      `json:"soft"`  // Totals 12-21
+	Pairs         [11]Row      `json:"pairs"` // A-A through 10-10
+	Surrender     [22][10]bool `json:"surrender"`
+	PairSurrender [11][10]bool `json:"pairSurrender"`
+}
+
+// ParseChart decodes a custom chart in the JSON form Chart marshals to
+// Every playable cell (hard 4-21, soft 12-21, every pair) must hold a known code
+func ParseChart(data []byte) (Chart, error) {
+	var c Chart
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Chart{}, fmt.Errorf("invalid chart JSON: %w", err)
+	}
+
+	check := func(kind string, rows []Row, from int) error {
+		for i, row := range rows {
+			for col, code := range row {
+				switch code {
+				case H, S, Dh, Ds, P, Ph:
+				default:
+					return fmt.Errorf("%s %d vs %s: unknown code %q", kind, from+i, Upcards[col], code)
+				}
+			}
+		}
+		return nil
+	}
+	if err := check("hard", c.Hard[4:], 4); err != nil {
+		return Chart{}, err
+	}
+	if err := check("soft", c.Soft[12:], 12); err != nil {
+		return Chart{}, err
+	}
+	if err := check("pair", c.Pairs[1:], 1); err != nil {
+		return Chart{}, err
+	}
+	return c, nil
+}
+
+// column returns the chart column of a dealer upcard
+func column(up game.Rank) int {
+	if up == game.Ace {
+		return upColumn(11)
+	}
+	return upColumn(up.Points())
+}
+
+// fill sets a row's cells for upcards from..to (points, 11 = Ace) to code
+func (r *Row) fill(code Code, from, to int) {
+	for up := from; up <= to; up++ {
+		r[upColumn(up)] = code
+	}
+}
+
+// upColumn returns the chart column of an upcard given by points, 11 = Ace
+func upColumn(points int) int {
+	if points == 11 {
+		return 9
+	}
+	return points - 2
+}
+
+// Generate builds the basic-strategy chart for a rule set
+// It starts from the multi-deck S17 chart and applies the standard adjustments for
+// hitting soft 17, double after split, one- and two-deck games, no hole card and surrender
+func Generate(rules game.Rules) Chart {
+	var c Chart
+	h17 := rules.HitSoft17
+	fewDecks := rules.Decks <= 2
+
+	// Hard totals
+	for total := 4; total <= 21; total++ {
+		row := &c.Hard[total]
+		row.fill(H, 2, 11)
+		switch {
+		case total == 8 && rules.Decks == 1:
+			row.fill(Dh, 5, 6)
+		case total == 9 && fewDecks:
+			row.fill(Dh, 2, 6)
+		case total == 9:
+			row.fill(Dh, 3, 6)
+		case total == 10:
+			row.fill(Dh, 2, 9)
+		case total == 11:
+			row.fill(Dh, 2, 10)
+			if h17 || fewDecks {
+				row.fill(Dh, 11, 11)
+			}
+		case total == 12:
+			row.fill(S, 4, 6)
+		case total >= 13 && total <= 16:
+			row.fill(S, 2, 6)
+		case total >= 17:
+			row.fill(S, 2, 11)
+		}
+	}
+
+	// Soft totals (A-2 is soft 13; A-A is soft 12 once it can no longer be split)
+	for total := 12; total <= 21; total++ {
+		row := &c.Soft[total]
+		row.fill(H, 2, 11)
+		switch total {
+		case 13, 14:
+			if rules.Decks == 1 {
+				row.fill(Dh, 4, 6)
+			} else {
+				row.fill(Dh, 5, 6)
+			}
+		case 15, 16:
+			row.fill(Dh, 4, 6)
+		case 17:
+			if rules.Decks == 1 {
+				row.fill(Dh, 2, 6)
+			} else {
+				row.fill(Dh, 3, 6)
+			}
+		case 18:
+			row.fill(S, 2, 8)
+			row.fill(Ds, 3, 6)
+			if h17 {
+				row.fill(Ds, 2, 2)
+			}
+		case 19:
+			row.fill(S, 2, 11)
+			if h17 || rules.Decks == 1 {
+				row.fill(Ds, 6, 6)
+			}
+		default:
+			row.fill(S, 2, 11)
+		}
+	}
+
+	// Pairs; 5-5 plays as hard 10
+	for points := 1; points <= 10; points++ {
+		row := &c.Pairs[points]
+		row.fill(H, 2, 11)
+		switch points {
+		case 1, 8:
+			row.fill(P, 2, 11)
+		case 2, 3:
+			row.fill(Ph, 2, 3)
+			row.fill(P, 4, 7)
+		case 4:
+			row.fill(Ph, 5, 6)
+		case 5:
+			*row = c.Hard[10]
+		case 6:
+			row.fill(Ph, 2, 2)
+			row.fill(P, 3, 6)
+		case 7:
+			row.fill(P, 2, 7)
+		case 9:
+			row.fill(S, 2, 11)
+			row.fill(P, 2, 6)
+			row.fill(P, 8, 9)
+		case 10:
+			row.fill(S, 2, 11)
+		}
+	}
+
+	if rules.DealingMode == game.DealENHC {
+		c.noHoleCard()
+	}
+	if rules.AllowSurrender {
+		c.fillSurrender(rules.SurrenderMode, h17)
+	}
+	return c
+}
+
+// noHoleCard stops doubling and splitting against a ten or Ace when a dealer blackjack takes
+// the whole bet (ENHC); under OBO the extra bets are returned, so the hole-card chart still holds
+func (c *Chart) noHoleCard() {
+	c.Hard[11].fill(H, 10, 11)
+	c.Pairs[1].fill(H, 11, 11)
+	c.Pairs[8].fill(H, 10, 11)
+}
+
+// fillSurrender marks the hands to surrender
+// Early surrender gives up far more hands against an Ace or ten, since the dealer has not yet checked for blackjack,
+// on top of the late surrender hands
+func (c *Chart) fillSurrender(mode game.SurrenderMode, h17 bool) {
+	ace, ten, nine := upColumn(11), upColumn(10), upColumn(9)
+
+	if mode == game.SurrenderEarly {
+		for _, total := range []int{5, 6, 7, 12, 13, 14, 15, 16, 17} {
+			c.Surrender[total][ace] = true
+		}
+		for _, total := range []int{14, 15, 16} {
+			c.Surrender[total][ten] = true
+		}
+		for _, points := range []int{3, 6, 7, 8} {
+			c.PairSurrender[points][ace] = true
+		}
+		c.PairSurrender[7][ten] = true
+		c.PairSurrender[8][ten] = true
+	}
+
+	c.Surrender[16][nine] = true
+	c.Surrender[16][ten] = true
+	c.Surrender[16][ace] = true
+	c.Surrender[15][ten] = true
+	if h17 {
+		c.Surrender[15][ace] = true
+		c.Surrender[17][ace] = true
+		c.PairSurrender[8][ace] = true
+	}
+}
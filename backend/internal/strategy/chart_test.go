@@ -0,0 +1,111 @@
+package strategy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+func TestGenerateRuleAdjustments(t *testing.T) {
+	s17 := game.DefaultRules()
+	s17.HitSoft17 = false
+	s17.Decks = 6
+	h17 := s17
+	h17.HitSoft17 = true
+	noDAS := s17
+	noDAS.AllowDAS = false
+	single := s17
+	single.Decks = 1
+	enhc := h17
+	enhc.DealingMode = game.DealENHC
+	obo := h17
+	obo.DealingMode = game.DealOBO
+
+	tests := []struct {
+		name string
+		code Code
+		want Code
+	}{
+		{"11 vs A, S17 shoe", Generate(s17).Hard[11][upColumn(11)], H},
+		{"11 vs A, H17 shoe", Generate(h17).Hard[11][upColumn(11)], Dh},
+		{"11 vs A, single deck", Generate(single).Hard[11][upColumn(11)], Dh},
+		{"A-7 vs 2, S17", Generate(s17).Soft[18][upColumn(2)], S},
+		{"A-7 vs 2, H17", Generate(h17).Soft[18][upColumn(2)], Ds},
+		{"A-8 vs 6, S17", Generate(s17).Soft[19][upColumn(6)], S},
+		{"A-8 vs 6, H17", Generate(h17).Soft[19][upColumn(6)], Ds},
+		{"9 vs 2, shoe", Generate(s17).Hard[9][upColumn(2)], H},
+		{"9 vs 2, single deck", Generate(single).Hard[9][upColumn(2)], Dh},
+		{"2-2 vs 2, DAS", Generate(s17).Pairs[2][upColumn(2)], Ph},
+		{"2-2 vs 2, no DAS", Generate(noDAS).Pairs[2][upColumn(2)], Ph},
+		{"9-9 vs 7", Generate(s17).Pairs[9][upColumn(7)], S},
+		{"5-5 vs 9", Generate(s17).Pairs[5][upColumn(9)], Dh},
+		{"16 vs 10", Generate(s17).Hard[16][upColumn(10)], H},
+		{"12 vs 3", Generate(s17).Hard[12][upColumn(3)], H},
+		{"12 vs 4", Generate(s17).Hard[12][upColumn(4)], S},
+		{"11 vs 10, ENHC", Generate(enhc).Hard[11][upColumn(10)], H},
+		{"11 vs 9, ENHC", Generate(enhc).Hard[11][upColumn(9)], Dh},
+		{"A-A vs A, ENHC", Generate(enhc).Pairs[1][upColumn(11)], H},
+		{"8-8 vs 10, ENHC", Generate(enhc).Pairs[8][upColumn(10)], H},
+		{"8-8 vs 10, OBO", Generate(obo).Pairs[8][upColumn(10)], P},
+		{"11 vs A, OBO", Generate(obo).Hard[11][upColumn(11)], Dh},
+	}
+	for _, tt := range tests {
+		if tt.code != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.code, tt.want)
+		}
+	}
+}
+
+func TestGenerateSurrender(t *testing.T) {
+	rules := game.DefaultRules()
+	rules.HitSoft17 = false
+	if c := Generate(rules); c.Surrender[16][upColumn(10)] {
+		t.Fatal("surrender marked on a table that does not offer it")
+	}
+
+	rules.AllowSurrender = true
+	late := Generate(rules)
+	if !late.Surrender[16][upColumn(10)] || !late.Surrender[15][upColumn(10)] {
+		t.Error("late surrender should give up 15 and 16 vs 10")
+	}
+	if late.Surrender[15][upColumn(11)] || late.Surrender[17][upColumn(11)] {
+		t.Error("15 and 17 vs A are only surrendered when the dealer hits soft 17")
+	}
+
+	rules.HitSoft17 = true
+	lateH17 := Generate(rules)
+	if !lateH17.Surrender[15][upColumn(11)] || !lateH17.Surrender[17][upColumn(11)] || !lateH17.PairSurrender[8][upColumn(11)] {
+		t.Error("H17 late surrender should add 15, 17 and 8-8 vs A")
+	}
+
+	rules.SurrenderMode = game.SurrenderEarly
+	early := Generate(rules)
+	if !early.Surrender[5][upColumn(11)] || !early.Surrender[14][upColumn(10)] || !early.PairSurrender[7][upColumn(10)] {
+		t.Error("early surrender should give up 5 vs A, 14 vs 10 and 7-7 vs 10")
+	}
+	if !early.Surrender[16][upColumn(9)] {
+		t.Error("early surrender should keep the late surrender hands")
+	}
+}
+
+func TestParseChart(t *testing.T) {
+	want := Generate(game.DefaultRules())
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got, err := ParseChart(data)
+	if err != nil {
+		t.Fatalf("ParseChart: %v", err)
+	}
+	if got != want {
+		t.Error("chart changed through JSON")
+	}
+
+	want.Hard[16][upColumn(10)] = "R"
+	data, _ = json.Marshal(want)
+	if _, err := ParseChart(data); err == nil {
+		t.Error("expected error for an unknown code")
+	}
+}
@@ -0,0 +1,305 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// HandKind is the kind of decision a deviation applies to
+type HandKind string
+
+const (
+	HardHand  HandKind = "hard"
+	SoftHand  HandKind = "soft"
+	PairHand  HandKind = "pair"
+	Insurance HandKind = "insurance"
+)
+
+// Deviation is an index play: from a true count on, play Action instead of the chart
+// Surrender deviations decide both ways: surrender at the index, never below it
+type Deviation struct {
+	Kind   HandKind  `json:"kind"`
+	Total  int       `json:"total,omitempty"`  // Hand total, or the paired card's points for pairs (1 = aces)
+	Upcard game.Rank `json:"upcard,omitempty"` // Any ten-value rank stands for every ten; unused for insurance
+	Index  float64   `json:"index"`
+	Below  bool      `json:"below,omitempty"`  // Play Action below the index instead of at or above it
+	Action Action    `json:"action,omitempty"` // Unused for insurance, which is taken at the index
+}
+
+// String describes the deviation the way index tables list it: "16 vs 10: stand at 0+"
+func (d Deviation) String() string {
+	if d.Kind == Insurance {
+		return fmt.Sprintf("insurance at %+g+", d.Index)
+	}
+
+	var hand string
+	switch d.Kind {
+	case SoftHand:
+		hand = fmt.Sprintf("soft %d", d.Total)
+	case PairHand:
+		rank := cardOf(d.Total).Rank
+		hand = fmt.Sprintf("%s,%s", rank, rank)
+	default:
+		hand = fmt.Sprintf("%d", d.Total)
+	}
+	when := fmt.Sprintf("%+g+", d.Index)
+	if d.Below {
+		when = fmt.Sprintf("below %+g", d.Index)
+	}
+	return fmt.Sprintf("%s vs %s: %s at %s", hand, d.Upcard, d.Action, when)
+}
+
+// applies reports whether the true count calls for the deviation
+func (d Deviation) applies(trueCount float64) bool {
+	if d.Below {
+		return trueCount < d.Index
+	}
+	return trueCount >= d.Index
+}
+
+// Deviations is an index table, checked in order
+type Deviations []Deviation
+
+// Illustrious18 are the eighteen most valuable Hi-Lo index plays for multi-deck S17 games
+var Illustrious18 = Deviations{
+	{Kind: Insurance, Index: 3},
+	{Kind: HardHand, Total: 16, Upcard: game.Ten, Index: 0, Action: Stand},
+	{Kind: HardHand, Total: 15, Upcard: game.Ten, Index: 4, Action: Stand},
+	{Kind: PairHand, Total: 10, Upcard: game.Five, Index: 5, Action: Split},
+	{Kind: PairHand, Total: 10, Upcard: game.Six, Index: 4, Action: Split},
+	{Kind: HardHand, Total: 10, Upcard: game.Ten, Index: 4, Action: Double},
+	{Kind: HardHand, Total: 12, Upcard: game.Three, Index: 2, Action: Stand},
+	{Kind: HardHand, Total: 12, Upcard: game.Two, Index: 3, Action: Stand},
+	{Kind: HardHand, Total: 11, Upcard: game.Ace, Index: 1, Action: Double},
+	{Kind: HardHand, Total: 9, Upcard: game.Two, Index: 1, Action: Double},
+	{Kind: HardHand, Total: 10, Upcard: game.Ace, Index: 4, Action: Double},
+	{Kind: HardHand, Total: 9, Upcard: game.Seven, Index: 3, Action: Double},
+	{Kind: HardHand, Total: 16, Upcard: game.Nine, Index: 5, Action: Stand},
+	{Kind: HardHand, Total: 13, Upcard: game.Two, Index: -1, Below: true, Action: Hit},
+	{Kind: HardHand, Total: 12, Upcard: game.Four, Index: 0, Below: true, Action: Hit},
+	{Kind: HardHand, Total: 12, Upcard: game.Five, Index: -2, Below: true, Action: Hit},
+	{Kind: HardHand, Total: 12, Upcard: game.Six, Index: -1, Below: true, Action: Hit},
+	{Kind: HardHand, Total: 13, Upcard: game.Three, Index: -2, Below: true, Action: Hit},
+}
+
+// Fab4 are the four most valuable Hi-Lo late surrender index plays for multi-deck S17 games
+var Fab4 = Deviations{
+	{Kind: HardHand, Total: 14, Upcard: game.Ten, Index: 3, Action: Surrender},
+	{Kind: HardHand, Total: 15, Upcard: game.Ten, Index: 0, Action: Surrender},
+	{Kind: HardHand, Total: 15, Upcard: game.Nine, Index: 2, Action: Surrender},
+	{Kind: HardHand, Total: 15, Upcard: game.Ace, Index: 1, Action: Surrender},
+}
+
+// DeviationSets are the built-in index tables by name
+var DeviationSets = map[string]Deviations{
+	"illustrious18": Illustrious18,
+	"fab4":          Fab4,
+}
+
+// LookupDeviations joins built-in index tables named in a comma-separated list, e.g. "illustrious18,fab4"
+func LookupDeviations(names string) (Deviations, error) {
+	var out Deviations
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		set, ok := DeviationSets[name]
+		if !ok {
+			known := make([]string, 0, len(DeviationSets))
+			for n := range DeviationSets {
+				known = append(known, n)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("unknown deviation set %q (known: %s)", name, strings.Join(known, ", "))
+		}
+		out = append(out, set...)
+	}
+	return out, nil
+}
+
+// ParseDeviations decodes a custom index table: a JSON array of deviations
+func ParseDeviations(data []byte) (Deviations, error) {
+	var out Deviations
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("invalid deviations JSON: %w", err)
+	}
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Validate checks that every deviation names a hand and a play the player can make
+func (ds Deviations) Validate() error {
+	for i, d := range ds {
+		switch d.Kind {
+		case Insurance:
+			continue
+		case HardHand:
+			if d.Total < 4 || d.Total > 21 {
+				return fmt.Errorf("deviation %d: hard total must be 4-21, got %d", i, d.Total)
+			}
+		case SoftHand:
+			if d.Total < 12 || d.Total > 21 {
+				return fmt.Errorf("deviation %d: soft total must be 12-21, got %d", i, d.Total)
+			}
+		case PairHand:
+			if d.Total < 1 || d.Total > 10 {
+				return fmt.Errorf("deviation %d: pair card must be 1-10, got %d", i, d.Total)
+			}
+		default:
+			return fmt.Errorf("deviation %d: unknown kind %q", i, d.Kind)
+		}
+
+		if d.Upcard.Points() < 1 {
+			return fmt.Errorf("deviation %d: missing upcard", i)
+		}
+		switch d.Action {
+		case Hit, Stand, Double, Split, Surrender:
+		default:
+			return fmt.Errorf("deviation %d: unknown action %q", i, d.Action)
+		}
+		if d.Action == Split && d.Kind != PairHand {
+			return fmt.Errorf("deviation %d: only pairs can split", i)
+		}
+	}
+	return nil
+}
+
+// IndexPlayer plays an advisor's chart with count-based deviations on top
+type IndexPlayer struct {
+	advisor    *Advisor
+	deviations Deviations
+}
+
+// NewIndexPlayer returns a player deviating from the advisor's chart by the index table
+func NewIndexPlayer(advisor *Advisor, deviations Deviations) *IndexPlayer {
+	return &IndexPlayer{advisor: advisor, deviations: deviations}
+}
+
+// Decide plays the index play for the true count, or the chart play
+func (p *IndexPlayer) Decide(hand game.PlayerHand, handsInRound int, dealerUp game.Card, trueCount float64) Action {
+	action, _ := p.Deviate(hand, handsInRound, dealerUp, trueCount)
+	return action
+}
+
+// TakeInsurance takes insurance at or above the table's insurance index
+func (p *IndexPlayer) TakeInsurance(trueCount float64) bool {
+	return p.insurance(trueCount) != nil
+}
+
+// insurance returns the insurance deviation the true count calls for, if any
+func (p *IndexPlayer) insurance(trueCount float64) *Deviation {
+	for i := range p.deviations {
+		if d := &p.deviations[i]; d.Kind == Insurance && d.applies(trueCount) {
+			return d
+		}
+	}
+	return nil
+}
+
+// Deviate returns the play for the true count and the deviation behind it, nil when the chart play stands
+// Surrender indexes are checked first since surrender is only offered as the first decision;
+// a hand the chart splits is only deviated by pair indexes.
+func (p *IndexPlayer) Deviate(hand game.PlayerHand, handsInRound int, dealerUp game.Card, trueCount float64) (Action, *Deviation) {
+	a := p.advisor
+	col := column(dealerUp.Rank)
+	canSurrender := a.canSurrender(hand, handsInRound)
+	chart := a.lookup(hand, handsInRound, col, canSurrender)
+	if chart.total > 21 {
+		return chart.action, nil
+	}
+
+	pair := len(hand.Cards) == 2 && hand.Cards[0].Rank == hand.Cards[1].Rank
+	matches := func(d Deviation) bool {
+		if d.Kind == Insurance || column(d.Upcard) != col {
+			return false
+		}
+		switch d.Kind {
+		case PairHand:
+			return pair && hand.Cards[0].Rank.Points() == d.Total
+		case SoftHand:
+			return chart.soft && chart.total == d.Total
+		default:
+			return !chart.soft && chart.total == d.Total && chart.action != Split
+		}
+	}
+
+	if canSurrender {
+		for i := range p.deviations {
+			d := &p.deviations[i]
+			if d.Action != Surrender || !matches(*d) {
+				continue
+			}
+			if d.applies(trueCount) {
+				return Surrender, d
+			}
+			if chart.action == Surrender {
+				// Below the index the hand is played on
+				return a.lookup(hand, handsInRound, col, false).action, d
+			}
+			break
+		}
+	}
+	if chart.action == Surrender {
+		return Surrender, nil
+	}
+
+	for i := range p.deviations {
+		d := &p.deviations[i]
+		if d.Action == Surrender || !matches(*d) || !d.applies(trueCount) {
+			continue
+		}
+		switch d.Action {
+		case Double:
+			if !a.rules.CanDouble(hand.Cards, hand.FromSplit) {
+				continue
+			}
+		case Split:
+			if !a.canSplit(hand, handsInRound) {
+				continue
+			}
+		}
+		return d.Action, d
+	}
+	return chart.action, nil
+}
+
+// AdviseState recommends the index play for the hand the engine is waiting on, given the player's true count
+// It returns the play and the deviation behind it, nil when basic strategy stands
+func (p *IndexPlayer) AdviseState(state *game.EngineState, trueCount float64) (Advice, *Deviation, error) {
+	basic, err := p.advisor.AdviseState(state)
+	if err != nil {
+		return Advice{}, nil, err
+	}
+	count := fmt.Sprintf("true count %+.1f", trueCount)
+
+	if state.Phase == game.PhaseInsuranceOffer && basic.Action == DeclineInsurance {
+		if d := p.insurance(trueCount); d != nil {
+			basic.Action = Insure
+			basic.Reason = fmt.Sprintf("Take insurance at %s: the index is %+g, enough tens are left for it to pay", count, d.Index)
+			return basic, d, nil
+		}
+		return basic, nil, nil
+	}
+	if state.Phase != game.PhasePlayerTurn {
+		return basic, nil, nil
+	}
+
+	seat := state.Seats[state.ActiveSeat]
+	hand := seat.Hands[seat.ActiveHand]
+	action, d := p.Deviate(hand, len(seat.Hands), state.DealerCards[0], trueCount)
+	if d == nil || action == basic.Action {
+		return basic, nil, nil
+	}
+
+	advice := Advice{Action: action, Code: basic.Code, Total: basic.Total, Soft: basic.Soft}
+	label := handLabel(hand.Cards, basic.Total, basic.Soft)
+	advice.Reason = fmt.Sprintf("%s vs %s at %s: %s instead of %s (index play %s)", label, state.DealerCards[0].Rank, count, action, basic.Action, d)
+	return advice, d, nil
+}
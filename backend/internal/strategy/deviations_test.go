@@ -0,0 +1,119 @@
+package strategy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+func TestDeviate(t *testing.T) {
+	rules := game.DefaultRules()
+	rules.HitSoft17 = false
+	rules.AllowSurrender = true
+	deviations, err := LookupDeviations("illustrious18, fab4")
+	if err != nil {
+		t.Fatalf("LookupDeviations: %v", err)
+	}
+	player := NewIndexPlayer(NewAdvisor(rules), deviations)
+
+	tests := []struct {
+		name    string
+		hand    game.PlayerHand
+		hands   int
+		up      string
+		tc      float64
+		want    Action
+		deviate bool
+	}{
+		{"16 vs 10 stands at 0", game.PlayerHand{Cards: cards("10-S", "3-H", "3-D")}, 1, "K-C", 0, Stand, true},
+		{"16 vs 10 hits below 0", game.PlayerHand{Cards: cards("10-S", "3-H", "3-D")}, 1, "K-C", -0.5, Hit, false},
+		{"12 vs 4 hits below 0", game.PlayerHand{Cards: cards("10-S", "2-H")}, 1, "4-C", -1, Hit, true},
+		{"12 vs 4 stands at 0", game.PlayerHand{Cards: cards("10-S", "2-H")}, 1, "4-C", 0, Stand, false},
+		{"tens split vs 6 at +4", game.PlayerHand{Cards: cards("10-S", "10-H")}, 1, "6-C", 4, Split, true},
+		{"tens stand vs 6 at +3", game.PlayerHand{Cards: cards("10-S", "10-H")}, 1, "6-C", 3, Stand, false},
+		{"tens at the split limit stand", game.PlayerHand{Cards: cards("10-S", "10-H"), FromSplit: true}, 4, "6-C", 6, Stand, false},
+		{"11 vs A doubles at +1", game.PlayerHand{Cards: cards("6-S", "5-H")}, 1, "A-C", 1, Double, true},
+		{"three-card 11 vs A hits", game.PlayerHand{Cards: cards("3-S", "3-H", "5-D")}, 1, "A-C", 5, Hit, false},
+		{"15 vs 10 surrenders at 0", game.PlayerHand{Cards: cards("10-S", "5-H")}, 1, "Q-C", 0, Surrender, true},
+		{"15 vs 10 plays on below 0", game.PlayerHand{Cards: cards("10-S", "5-H")}, 1, "Q-C", -1, Hit, true},
+		{"three-card 15 vs 10 stands at +4", game.PlayerHand{Cards: cards("10-S", "2-H", "3-D")}, 1, "Q-C", 4, Stand, true},
+		{"14 vs 10 surrenders at +3", game.PlayerHand{Cards: cards("10-S", "4-H")}, 1, "J-C", 3, Surrender, true},
+		{"eights still split vs 10", game.PlayerHand{Cards: cards("8-S", "8-H")}, 1, "K-C", 2, Split, false},
+	}
+	for _, tt := range tests {
+		up := cards(tt.up)[0]
+		got, d := player.Deviate(tt.hand, tt.hands, up, tt.tc)
+		if got != tt.want || (d != nil) != tt.deviate {
+			t.Errorf("%s: got %s (deviation %v), want %s (deviation %v)", tt.name, got, d, tt.want, tt.deviate)
+		}
+	}
+
+	if player.TakeInsurance(2.9) || !player.TakeInsurance(3) {
+		t.Error("insurance should be taken from true count +3")
+	}
+}
+
+func TestIndexPlayerAdviseState(t *testing.T) {
+	rules := game.DefaultRules()
+	player := NewIndexPlayer(NewAdvisor(rules), Illustrious18)
+	state := &game.EngineState{
+		Phase:       game.PhasePlayerTurn,
+		DealerCards: cards("10-S", "7-D"),
+		Seats:       []game.Seat{{Hands: []game.PlayerHand{{Cards: cards("10-H", "6-H")}}}},
+	}
+
+	advice, d, err := player.AdviseState(state, 1.5)
+	if err != nil {
+		t.Fatalf("AdviseState: %v", err)
+	}
+	if advice.Action != Stand || d == nil || !strings.Contains(advice.Reason, "instead of hit") {
+		t.Errorf("16 vs 10 at +1.5 = %s (%s), want an index stand", advice.Action, advice.Reason)
+	}
+
+	if advice, d, _ = player.AdviseState(state, -1); advice.Action != Hit || d != nil {
+		t.Errorf("16 vs 10 at -1 = %s (deviation %v), want basic hit", advice.Action, d)
+	}
+
+	state.Phase = game.PhaseInsuranceOffer
+	state.DealerCards = cards("A-S", "7-D")
+	if advice, d, _ = player.AdviseState(state, 3.2); advice.Action != Insure || d == nil {
+		t.Errorf("insurance at +3.2 = %s, want %s", advice.Action, Insure)
+	}
+}
+
+func TestParseDeviations(t *testing.T) {
+	data, err := json.Marshal(Illustrious18)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	got, err := ParseDeviations(data)
+	if err != nil {
+		t.Fatalf("ParseDeviations: %v", err)
+	}
+	if len(got) != len(Illustrious18) || got[1] != Illustrious18[1] {
+		t.Errorf("round trip = %v", got)
+	}
+
+	custom := `[{"kind":"soft","total":19,"upcard":"6","index":1,"action":"double"}]`
+	if _, err := ParseDeviations([]byte(custom)); err != nil {
+		t.Errorf("custom table: %v", err)
+	}
+
+	for _, bad := range []string{
+		`[{"kind":"hard","total":16,"index":0,"action":"stand"}]`,
+		`[{"kind":"hard","total":16,"upcard":"10","index":0,"action":"split"}]`,
+		`[{"kind":"soft","total":9,"upcard":"10","index":0,"action":"stand"}]`,
+		`[{"kind":"hard","total":16,"upcard":"10","index":0,"action":"fold"}]`,
+		`[{"kind":"triple","total":16,"upcard":"10","index":0,"action":"stand"}]`,
+	} {
+		if _, err := ParseDeviations([]byte(bad)); err == nil {
+			t.Errorf("ParseDeviations(%s): expected error", bad)
+		}
+	}
+
+	if _, err := LookupDeviations("illustrious18,wonging"); err == nil {
+		t.Error("expected error for an unknown set")
+	}
+}
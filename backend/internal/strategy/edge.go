@@ -0,0 +1,369 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// Edge is the exact expectation of a rule set played by basic strategy, per unit of initial bet
+type Edge struct {
+	PlayerEV  float64     `json:"playerEv"`  // Expected net win of the player
+	HouseEdge float64     `json:"houseEdge"` // -PlayerEV
+	ByUpcard  [10]float64 `json:"byUpcard"`  // Player expectation given each dealer upcard, in Upcards order
+}
+
+// HouseEdge computes the exact expected value of a full shoe dealt under rules and played by basic strategy
+// Every starting hand and upcard is weighted by its probability, the player's draws are followed
+// card by card through the shoe, and the dealer's final total is computed by recursion over the
+// composition left after the player's cards. With an Ace or ten up the dealer's hole card is
+// enumerated, so hands are only played on when the dealer has no blackjack. Without a hole card
+// (ENHC and OBO) the dealer's second card is enumerated the same way, being as likely to be any card
+// left after the player's, and against a blackjack the hand is played out to settle what it loses.
+// Charlies and 7-7-7 are paid; the suited bonuses depend on suits, which the shoe doesn't track.
+// Split hands are valued as independent hands drawn from the shoe less the pair, without resplits;
+// this is the usual approximation and moves the edge by a few hundredths of a percent at most.
+// Only classic blackjack is modeled; other variants return ErrUnsupportedVariant.
+func HouseEdge(rules game.Rules) (Edge, error) {
+	if err := rules.Validate(); err != nil {
+		return Edge{}, fmt.Errorf("invalid rules: %w", err)
+	}
+	if err := CheckVariant(rules); err != nil {
+		return Edge{}, err
+	}
+	if rules.Suited678Bps != 0 || rules.SuitedBJPayoutBps != 0 {
+		return Edge{}, fmt.Errorf("suited bonus hands are not modeled by the edge calculation")
+	}
+
+	an := &analyzer{
+		advisor: NewAdvisor(rules),
+		rules:   rules,
+		bjPays:  float64(rules.BJPayoutBps) / 10000,
+		dealer:  make(map[dealerKey]dealerOdds),
+	}
+
+	var edge Edge
+	var weight [10]float64
+	full := game.NewRankCounts(rules.Decks)
+	for p1 := 1; p1 <= 10; p1++ {
+		for p2 := p1; p2 <= 10; p2++ {
+			for up := 1; up <= 10; up++ {
+				shoe := full
+				p := take(&shoe, p1) * take(&shoe, p2) * take(&shoe, up)
+				if p == 0 {
+					continue
+				}
+				if p1 != p2 {
+					p *= 2 // Either card may come first
+				}
+
+				ev := an.round(shoe, p1, p2, up)
+				col := column(cardOf(up).Rank)
+				edge.PlayerEV += p * ev
+				edge.ByUpcard[col] += p * ev
+				weight[col] += p
+			}
+		}
+	}
+
+	for col := range edge.ByUpcard {
+		if weight[col] > 0 {
+			edge.ByUpcard[col] /= weight[col]
+		}
+	}
+	edge.HouseEdge = -edge.PlayerEV
+	return edge, nil
+}
+
+// analyzer computes expectations for one rule set
+type analyzer struct {
+	advisor *Advisor
+	rules   game.Rules
+	bjPays  float64
+	dealer  map[dealerKey]dealerOdds
+}
+
+// dealerKey identifies a dealer distribution: the shoe left and the dealer's known cards (hole 0 when undrawn)
+type dealerKey struct {
+	shoe     game.RankCounts
+	up, hole int
+}
+
+// dealerOdds holds the probabilities of the dealer finishing on 17-21 (indexes 0-4) or busting (index 5)
+type dealerOdds [6]float64
+
+// round returns the expectation of a starting hand p1,p2 against the upcard, shoe holding the remaining cards
+func (an *analyzer) round(shoe game.RankCounts, p1, p2, up int) float64 {
+	hand := game.PlayerHand{Cards: []game.Card{cardOf(p1), cardOf(p2)}}
+	col := column(cardOf(up).Rank)
+
+	// The card that would give the dealer blackjack; the dealer peeks when it is possible
+	bjCard := 0
+	switch up {
+	case 1:
+		bjCard = 10
+	case 10:
+		bjCard = 1
+	}
+
+	left := float64(shoe.Total())
+	if game.IsBlackjack(hand.Cards) {
+		if bjCard == 0 {
+			return an.bjPays
+		}
+		return (1 - float64(shoe[bjCard])/left) * an.bjPays
+	}
+	if bjCard == 0 {
+		return an.play(shoe, hand, 1, col, up, 0)
+	}
+
+	if !an.rules.Peeks() {
+		ev := 0.0
+		for hole := 1; hole <= 10; hole++ {
+			count := shoe[hole]
+			if count == 0 {
+				continue
+			}
+			rest := shoe
+			rest[hole]--
+			if hole == bjCard {
+				ev += float64(count) / left * an.againstBlackjack(rest, hand, 1, col, true)
+			} else {
+				ev += float64(count) / left * an.play(rest, hand, 1, col, up, hole)
+			}
+		}
+		return ev
+	}
+
+	// Early surrender is decided before the dealer checks for blackjack
+	if an.rules.AllowSurrender && an.rules.SurrenderMode == game.SurrenderEarly {
+		if an.advisor.decide(hand, 1, col).action == Surrender {
+			return -0.5
+		}
+	}
+
+	ev := -float64(shoe[bjCard]) / left
+	for hole := 1; hole <= 10; hole++ {
+		count := shoe[hole]
+		if hole == bjCard || count == 0 {
+			continue
+		}
+		rest := shoe
+		rest[hole]--
+		ev += float64(count) / left * an.play(rest, hand, 1, col, up, hole)
+	}
+	return ev
+}
+
+// play returns the expectation of a hand played by the chart
+func (an *analyzer) play(shoe game.RankCounts, hand game.PlayerHand, handsInRound, col, up, hole int) float64 {
+	if win, ok := an.bonus(hand); ok {
+		return win
+	}
+
+	d := an.advisor.decide(hand, handsInRound, col)
+	switch d.action {
+	case Surrender:
+		return -0.5
+	case Stand:
+		return an.stand(shoe, d.total, up, hole)
+	case Double:
+		return 2 * each(shoe, func(rest game.RankCounts, card game.Card) float64 {
+			total, _ := game.CalculateHandValue(append(hand.Cards[:len(hand.Cards):len(hand.Cards)], card))
+			return an.stand(rest, total, up, hole)
+		})
+	case Split:
+		return 2 * an.split(shoe, hand.Cards[0], col, up, hole)
+	default:
+		return each(shoe, func(rest game.RankCounts, card game.Card) float64 {
+			next := hand
+			next.Cards = append(hand.Cards[:len(hand.Cards):len(hand.Cards)], card)
+			return an.play(rest, next, handsInRound, col, up, hole)
+		})
+	}
+}
+
+// split returns the expectation of one of the two hands made by splitting a pair of card
+func (an *analyzer) split(shoe game.RankCounts, card game.Card, col, up, hole int) float64 {
+	aces := card.Rank == game.Ace
+	return each(shoe, func(rest game.RankCounts, next game.Card) float64 {
+		hand := game.PlayerHand{Cards: []game.Card{card, next}, FromSplit: true, SplitAces: aces}
+		if aces && an.rules.SplitAcesOnce {
+			total, _ := game.CalculateHandValue(hand.Cards)
+			return an.stand(rest, total, up, hole)
+		}
+		// Playing at the split limit keeps the hand from being split again
+		return an.play(rest, hand, an.rules.MaxSplitHands, col, up, hole)
+	})
+}
+
+// againstBlackjack returns the result of a hand played by the chart when the dealer, without a hole card, makes blackjack
+// original marks the hand carrying the seat's original bet, the only one at stake under original bets only
+func (an *analyzer) againstBlackjack(shoe game.RankCounts, hand game.PlayerHand, handsInRound, col int, original bool) float64 {
+	if an.rules.CharlieCards > 0 && len(hand.Cards) >= an.rules.CharlieCards {
+		total, _ := game.CalculateHandValue(hand.Cards)
+		return an.lostToBlackjack(total, 1, original)
+	}
+
+	d := an.advisor.decide(hand, handsInRound, col)
+	switch d.action {
+	case Surrender:
+		return -0.5
+	case Stand:
+		return an.lostToBlackjack(d.total, 1, original)
+	case Double:
+		return each(shoe, func(rest game.RankCounts, card game.Card) float64 {
+			total, _ := game.CalculateHandValue(append(hand.Cards[:len(hand.Cards):len(hand.Cards)], card))
+			return an.lostToBlackjack(total, 2, original)
+		})
+	case Split:
+		card := hand.Cards[0]
+		aces := card.Rank == game.Ace
+		splitHand := func(original bool) float64 {
+			return each(shoe, func(rest game.RankCounts, next game.Card) float64 {
+				hand := game.PlayerHand{Cards: []game.Card{card, next}, FromSplit: true, SplitAces: aces}
+				if aces && an.rules.SplitAcesOnce {
+					total, _ := game.CalculateHandValue(hand.Cards)
+					return an.lostToBlackjack(total, 1, original)
+				}
+				return an.againstBlackjack(rest, hand, an.rules.MaxSplitHands, col, original)
+			})
+		}
+		return splitHand(original) + splitHand(false)
+	default:
+		return each(shoe, func(rest game.RankCounts, card game.Card) float64 {
+			next := hand
+			next.Cards = append(hand.Cards[:len(hand.Cards):len(hand.Cards)], card)
+			return an.againstBlackjack(rest, next, handsInRound, col, original)
+		})
+	}
+}
+
+// lostToBlackjack returns what a hand finishing on total with stake units bet loses to a dealer blackjack
+// A busted hand always loses its stake; otherwise original bets only takes just the original bet
+func (an *analyzer) lostToBlackjack(total int, stake float64, original bool) float64 {
+	switch {
+	case total > 21 || an.rules.DealingMode != game.DealOBO:
+		return -stake
+	case original:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// bonus returns the win of a hand that finishes on a bonus whatever the dealer makes, short of a blackjack
+// A Charlie wins even money on any hand; 7-7-7 pays only on the hand as dealt, not on split hands
+func (an *analyzer) bonus(hand game.PlayerHand) (float64, bool) {
+	if an.rules.CharlieCards > 0 && len(hand.Cards) >= an.rules.CharlieCards && !game.IsBust(hand.Cards) {
+		return 1, true
+	}
+	if an.rules.Triple7Bps == 0 || hand.FromSplit || len(hand.Cards) != 3 {
+		return 0, false
+	}
+	for _, c := range hand.Cards {
+		if c.Rank != game.Seven {
+			return 0, false
+		}
+	}
+	return float64(an.rules.Triple7Bps) / 10000, true
+}
+
+// stand returns the expectation of standing on total against the dealer
+func (an *analyzer) stand(shoe game.RankCounts, total, up, hole int) float64 {
+	if total > 21 {
+		return -1
+	}
+
+	odds := an.dealerOdds(shoe, up, hole)
+	ev := odds[5]
+	for i, p := range odds[:5] {
+		switch dealer := 17 + i; {
+		case total > dealer:
+			ev += p
+		case total < dealer:
+			ev -= p
+		}
+	}
+	return ev
+}
+
+// dealerOdds returns the dealer's final-total distribution, memoized by shoe and known cards
+func (an *analyzer) dealerOdds(shoe game.RankCounts, up, hole int) dealerOdds {
+	key := dealerKey{shoe: shoe, up: up, hole: hole}
+	if odds, ok := an.dealer[key]; ok {
+		return odds
+	}
+
+	var odds dealerOdds
+	total, ace := up, up == 1
+	if hole != 0 {
+		total, ace = total+hole, ace || hole == 1
+	}
+	an.drawDealer(&shoe, shoe.Total(), total, ace, 1, &odds)
+
+	an.dealer[key] = odds
+	return odds
+}
+
+// drawDealer plays out a dealer hand of hard total (aces as 1), adding each final total's probability p to odds
+// It stands and hits like game.DealerShouldHit, tracking the total instead of the cards to stay fast
+func (an *analyzer) drawDealer(shoe *game.RankCounts, left, hard int, ace bool, p float64, odds *dealerOdds) {
+	total, soft := hard, false
+	if ace && hard+10 <= 21 {
+		total, soft = hard+10, true
+	}
+	if total > 17 || (total == 17 && !(soft && an.rules.HitSoft17)) {
+		if total > 21 {
+			odds[5] += p
+		} else {
+			odds[total-17] += p
+		}
+		return
+	}
+
+	for points := 1; points <= 10; points++ {
+		count := shoe[points]
+		if count == 0 {
+			continue
+		}
+		shoe[points]--
+		an.drawDealer(shoe, left-1, hard+points, ace || points == 1, p*float64(count)/float64(left), odds)
+		shoe[points]++
+	}
+}
+
+// each averages fn over the next card drawn from shoe, passing the shoe without it
+func each(shoe game.RankCounts, fn func(rest game.RankCounts, card game.Card) float64) float64 {
+	left := float64(shoe.Total())
+	ev := 0.0
+	for points := 1; points <= 10; points++ {
+		count := shoe[points]
+		if count == 0 {
+			continue
+		}
+		rest := shoe
+		rest[points]--
+		ev += float64(count) / left * fn(rest, cardOf(points))
+	}
+	return ev
+}
+
+// take removes a card of the given points from shoe and returns the probability of drawing it
+func take(shoe *game.RankCounts, points int) float64 {
+	left := shoe.Total()
+	if left == 0 || shoe[points] == 0 {
+		return 0
+	}
+	p := float64(shoe[points]) / float64(left)
+	shoe[points]--
+	return p
+}
+
+// cardOf returns a card worth points (1 = Ace); the suit does not matter to the analyzer
+func cardOf(points int) game.Card {
+	if points == 1 {
+		return game.Card{Rank: game.Ace}
+	}
+	return game.Card{Rank: Upcards[points-2]}
+}
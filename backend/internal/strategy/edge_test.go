@@ -0,0 +1,99 @@
+package strategy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+func TestHouseEdge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("exact edge computation takes a few seconds")
+	}
+
+	// Six decks, S17, DAS, 3:2, no surrender: published figures put basic strategy at about 0.46% without resplits
+	rules := game.DefaultRules()
+	rules.Decks = 6
+	rules.HitSoft17 = false
+	rules.BJPayoutBps = 15000
+	s17, err := HouseEdge(rules)
+	if err != nil {
+		t.Fatalf("HouseEdge: %v", err)
+	}
+	if s17.HouseEdge < 0.0043 || s17.HouseEdge > 0.0049 {
+		t.Errorf("6-deck S17 edge = %.4f%%, want about 0.46%%", s17.HouseEdge*100)
+	}
+	if s17.ByUpcard[column(game.Six)] <= 0 || s17.ByUpcard[column(game.Ace)] >= 0 {
+		t.Errorf("by upcard = %v, want the player ahead against a 6 and behind against an Ace", s17.ByUpcard)
+	}
+
+	// Hitting soft 17 costs the player about 0.2%
+	rules.HitSoft17 = true
+	h17, err := HouseEdge(rules)
+	if err != nil {
+		t.Fatalf("HouseEdge: %v", err)
+	}
+	if diff := h17.HouseEdge - s17.HouseEdge; diff < 0.0018 || diff > 0.0025 {
+		t.Errorf("H17 adds %.4f%%, want about 0.21%%", diff*100)
+	}
+
+	rules.BJPayoutBps = 5000
+	if _, err := HouseEdge(rules); err == nil {
+		t.Error("expected error for invalid rules")
+	}
+
+	for _, variant := range game.Variants()[1:] {
+		rules := variant.Rules(game.DefaultRules())
+		rules.Variant = variant.Name()
+		if _, err := HouseEdge(rules); !errors.Is(err, ErrUnsupportedVariant) {
+			t.Errorf("HouseEdge(%s): %v, want ErrUnsupportedVariant", variant.Name(), err)
+		}
+	}
+}
+
+func TestHouseEdgeDealingModesAndBonuses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("exact edge computation takes a few seconds")
+	}
+
+	base := game.DefaultRules()
+	base.Decks = 6
+	base.HitSoft17 = false
+	base.BJPayoutBps = 15000
+	peek, err := HouseEdge(base)
+	if err != nil {
+		t.Fatalf("HouseEdge: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(r *game.Rules)
+		min, max float64 // Change in house edge
+	}{
+		// Doubles and splits against a ten or Ace are lost to a blackjack the dealer never peeked for;
+		// the no-hole-card chart stops making most of them, leaving about 0.11%
+		{"enhc", func(r *game.Rules) { r.DealingMode = game.DealENHC }, 0.0005, 0.0025},
+		// Only busted hands lose more than the original bet, so the game is close to the peek game
+		{"obo", func(r *game.Rules) { r.DealingMode = game.DealOBO }, 0, 0.0003},
+		{"charlie", func(r *game.Rules) { r.CharlieCards = 5 }, -0.0150, -0.0080},
+		{"777", func(r *game.Rules) { r.Triple7Bps = 30000 }, -0.0010, -0.0001},
+	}
+	for _, tt := range tests {
+		rules := base
+		tt.modify(&rules)
+		edge, err := HouseEdge(rules)
+		if err != nil {
+			t.Fatalf("%s: HouseEdge: %v", tt.name, err)
+		}
+		if diff := edge.HouseEdge - peek.HouseEdge; diff < tt.min || diff > tt.max {
+			t.Errorf("%s changes the edge by %.4f%%, want between %.2f%% and %.2f%%", tt.name, diff*100, tt.min*100, tt.max*100)
+		}
+	}
+
+	rules := base
+	rules.Suited678Bps = 20000
+	if _, err := HouseEdge(rules); err == nil {
+		t.Error("expected error for a suited bonus the analyzer can't model")
+	}
+}
@@ -0,0 +1,102 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/DanDo385/blackjack/backend/internal/game"
+)
+
+// Player makes the decisions for simulated hands
+// trueCount is the player's true count when the decision is made; players that do not count ignore it
+type Player interface {
+	Decide(hand game.PlayerHand, handsInRound int, dealerUp game.Card, trueCount float64) Action
+	TakeInsurance(trueCount float64) bool
+}
+
+// Decide plays the advisor's chart, whatever the count
+func (a *Advisor) Decide(hand game.PlayerHand, handsInRound int, dealerUp game.Card, trueCount float64) Action {
+	return a.decide(hand, handsInRound, column(dealerUp.Rank)).action
+}
+
+// TakeInsurance always declines: insurance is a losing bet off the top of the shoe
+func (a *Advisor) TakeInsurance(trueCount float64) bool {
+	return false
+}
+
+// Play plays a dealt hand from deck by basic strategy, splitting, doubling and surrendering as advised
+// It returns the finished hands in order; Bet holds each hand's stake in units of the original bet
+// ("1", or "2" after doubling). The dealer's blackjack must already have been checked.
+func (a *Advisor) Play(deck *game.Deck, cards []game.Card, dealerUp game.Card) ([]game.PlayerHand, error) {
+	return PlayHands(a.rules, cards, deck.Draw, func(hand game.PlayerHand, handsInRound int) Action {
+		return a.decide(hand, handsInRound, column(dealerUp.Rank)).action
+	})
+}
+
+// PlayHands plays out a dealt hand, drawing cards with draw and taking each play from decide
+// Split hands are played in order, each dealt its second card when play reaches it; split aces
+// get one card when the table splits aces once. Bet holds each hand's stake in units of the
+// original bet ("1", or "2" after doubling).
+func PlayHands(rules game.Rules, cards []game.Card, draw func() (game.Card, error), decide func(hand game.PlayerHand, handsInRound int) Action) ([]game.PlayerHand, error) {
+	hands := []game.PlayerHand{{Cards: append([]game.Card(nil), cards...), Bet: "1"}}
+
+	for i := 0; i < len(hands); i++ {
+		hand := &hands[i]
+
+		// Split hands are dealt their second card when play reaches them
+		if len(hand.Cards) == 1 {
+			card, err := draw()
+			if err != nil {
+				return nil, fmt.Errorf("failed to deal split hand: %w", err)
+			}
+			hand.Cards = append(hand.Cards, card)
+			if hand.SplitAces && rules.SplitAcesOnce {
+				continue
+			}
+		}
+
+		if !hand.FromSplit && game.IsBlackjack(hand.Cards) {
+			continue
+		}
+
+		for {
+			action := decide(*hand, len(hands))
+			if action == Stand || game.IsBust(hand.Cards) {
+				break
+			}
+
+			if action == Surrender {
+				hand.Surrendered = true
+				break
+			}
+
+			if action == Split {
+				aces := hand.Cards[0].Rank == game.Ace
+				split := game.PlayerHand{Cards: hand.Cards[1:2:2], Bet: hand.Bet, FromSplit: true, SplitAces: aces}
+				hand.Cards = hand.Cards[:1:1]
+				hand.FromSplit = true
+				hand.SplitAces = aces
+
+				hands = append(hands, game.PlayerHand{})
+				copy(hands[i+2:], hands[i+1:])
+				hands[i+1] = split
+				hand = &hands[i]
+			}
+
+			card, err := draw()
+			if err != nil {
+				return nil, fmt.Errorf("failed to %s: %w", action, err)
+			}
+			hand.Cards = append(hand.Cards, card)
+
+			if action == Double {
+				hand.Bet = "2"
+				hand.Doubled = true
+				break
+			}
+			if action == Split && hand.SplitAces && rules.SplitAcesOnce {
+				break
+			}
+		}
+	}
+	return hands, nil
+}
@@ -41,6 +41,9 @@ contract Table is ITable, VRFConsumerBaseV2 {
   struct Hand { address player; address token; uint256 amount; uint256 usdcRef; bool settled; bytes32 seed; }
   mapping(uint256 => Hand) public hands; uint256 public nextHandId;
 
+  // Merkle roots of off-chain shoes (salted card positions) => block timestamp they were committed at
+  mapping(bytes32 => uint256) public shoeRootCommittedAt;
+
   constructor(
     Rules memory r,
     address _treasury,
@@ -174,6 +177,19 @@ contract Table is ITable, VRFConsumerBaseV2 {
 
     emit HandSettled(handId, h.player, playerWin?int256(h.amount): -int256(h.amount), h.token, payout, feeLink, feeNickelRef);
   }
-}
-
 
+  /**
+   * @notice Commits the Merkle root of a shuffled shoe before any card of it is dealt
+   * @dev A dealt card is later proven against the root without revealing the rest of the shoe.
+   *      A shoe refilled from its discards mid-round gets a new root under the same shoeId.
+   *      Only the shoe in play can be committed to.
+   */
+  function commitShoe(uint256 id, bytes32 root) external {
+    require(msg.sender == owner, "NotOwner");
+    require(id == shoeId, "NotCurrentShoe");
+    require(root != bytes32(0), "EmptyRoot");
+    require(shoeRootCommittedAt[root] == 0, "RootCommitted");
+    shoeRootCommittedAt[root] = block.timestamp;
+    emit ShoeCommitted(id, root);
+  }
+}
@@ -17,9 +17,11 @@ interface ITable {
   event RandomFulfilled(uint256 indexed handId, bytes32 seed);
   event HandSettled(uint256 indexed handId, address indexed player, int256 pnl, address payoutToken, uint256 payoutAmount, uint256 feeLink, uint256 feeNickelRef);
   event Reshuffle(uint256 indexed shoeId);
+  event ShoeCommitted(uint256 indexed shoeId, bytes32 root);
 
   function placeBet(address token, uint256 amount, uint256 usdcRef, bytes32 quoteId) external returns (uint256 handId);
   function settle(uint256 handId, uint256 cardsDealtInHand) external;
+  function commitShoe(uint256 shoeId, bytes32 root) external;
 }
 
 
@@ -24,6 +24,7 @@ contract TableTest is Test {
     event RandomFulfilled(uint256 indexed handId, bytes32 seed);
     event HandSettled(uint256 indexed handId, address indexed player, int256 pnl, address payoutToken, uint256 payoutAmount, uint256 feeLink, uint256 feeNickelRef);
     event Reshuffle(uint256 indexed shoeId);
+    event ShoeCommitted(uint256 indexed shoeId, bytes32 root);
 
     function setUp() public {
         owner = address(this);
@@ -399,6 +400,40 @@ contract TableTest is Test {
         table.settle(handId, 4); // 2 player + 2 dealer cards
     }
 
+    // ============ Shoe Commitment Tests ============
+
+    function test_CommitShoe_RecordsRoot() public {
+        bytes32 root = keccak256("shoe root");
+
+        vm.expectEmit(true, false, false, true);
+        emit ShoeCommitted(1, root);
+        table.commitShoe(1, root);
+
+        assertEq(table.shoeRootCommittedAt(root), block.timestamp);
+    }
+
+    function test_RevertWhen_CommitShoeTwice() public {
+        bytes32 root = keccak256("shoe root");
+        table.commitShoe(1, root);
+
+        vm.expectRevert("RootCommitted");
+        table.commitShoe(1, root);
+    }
+
+    function test_RevertWhen_CommitShoeNotCurrent() public {
+        vm.expectRevert("NotCurrentShoe");
+        table.commitShoe(2, keccak256("shoe root"));
+
+        vm.expectRevert("NotCurrentShoe");
+        table.commitShoe(0, keccak256("shoe root"));
+    }
+
+    function test_RevertWhen_CommitShoeNotOwner() public {
+        vm.prank(player);
+        vm.expectRevert("NotOwner");
+        table.commitShoe(1, keccak256("shoe root"));
+    }
+
     // ============ Multiple Players Tests ============
 
     function test_MultiplePlayers_IndependentState() public {